@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+
+	"github.com/seniorcat/scraper/config"
+	"github.com/seniorcat/scraper/database"
+	"go.uber.org/zap"
+)
+
+// RecipesWithTags выводит рецепты со всеми метками и объяснениями: diet <метка> [<метка>...]
+func RecipesWithTags(args []string) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать логгер: %v", err)
+	}
+	defer logger.Sync()
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		logger.Fatal("Ошибка загрузки конфигурации", zap.Error(err))
+	}
+
+	known := make(map[string]bool, len(cfg.Diet.Rules))
+	for _, rule := range cfg.Diet.Rules {
+		known[rule.Tag] = true
+	}
+
+	if len(args) == 0 {
+		tags := make([]string, 0, len(known))
+		for tag := range known {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		fmt.Println("Использование: diet <метка> [<метка>...]")
+		fmt.Printf("Метки: %v\n", tags)
+		return
+	}
+
+	// Повтор метки не должен менять условие «все метки»
+	var tags []string
+	for _, tag := range args {
+		if !known[tag] {
+			fmt.Printf("Метка %q не задана в конфигурации\n", tag)
+			return
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	dbService, err := database.NewDBService(cfg.Database.URL)
+	if err != nil {
+		logger.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
+	}
+
+	recipes, err := dbService.RecipesWithTags(context.Background(), tags)
+	if err != nil {
+		logger.Fatal("Не удалось загрузить рецепты", zap.Error(err))
+	}
+
+	fmt.Printf("%v: %d рецептов\n", tags, len(recipes))
+	for _, recipe := range recipes {
+		fmt.Printf("  %s  %s\n", recipe.Name, recipe.CanonicalURL)
+		for _, tag := range recipe.Tags {
+			fmt.Printf("    %s: %s\n", tag.Tag, tag.Reason)
+		}
+	}
+}
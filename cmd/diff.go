@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/seniorcat/scraper/config"
+	"github.com/seniorcat/scraper/database"
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/crawldiff"
+	"go.uber.org/zap"
+)
+
+// currentSnapshot — аргумент diff, означающий текущее состояние базы
+const currentSnapshot = "current"
+
+// Diff сравнивает два запуска или запуск с текущим состоянием базы:
+// diff [-format text|json|markdown] [-o файл] <запуск> [<запуск>|current]
+func Diff(args []string) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать логгер: %v", err)
+	}
+	defer logger.Sync()
+
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := flags.String("format", crawldiff.FormatText, "Формат отчета: text, json или markdown")
+	output := flags.String("o", "", "Файл для отчета; по умолчанию стандартный вывод")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		fmt.Println("Использование: diff [-format text|json|markdown] [-o файл] <запуск> [<запуск>|current]")
+		return
+	}
+	to := currentSnapshot
+	if flags.NArg() == 2 {
+		to = flags.Arg(1)
+	}
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		logger.Fatal("Ошибка загрузки конфигурации", zap.Error(err))
+	}
+
+	dbService, err := database.NewDBService(cfg.Database.URL)
+	if err != nil {
+		logger.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
+	}
+
+	ctx := context.Background()
+	var notes []string
+
+	fromSnapshot, err := loadSnapshot(ctx, dbService, flags.Arg(0), &notes)
+	if err != nil {
+		logger.Fatal("Не удалось загрузить данные запуска", zap.String("run", flags.Arg(0)), zap.Error(err))
+	}
+	toSnapshot, err := loadSnapshot(ctx, dbService, to, &notes)
+	if err != nil {
+		logger.Fatal("Не удалось загрузить данные запуска", zap.String("run", to), zap.Error(err))
+	}
+
+	report := crawldiff.Compare(fromSnapshot, toSnapshot)
+	report.Notes = notes
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			logger.Fatal("Не удалось создать файл отчета", zap.Error(err))
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := report.Write(out, *format); err != nil {
+		logger.Fatal("Не удалось вывести отчет", zap.Error(err))
+	}
+}
+
+// loadSnapshot загружает состав запуска или текущее состояние базы; о незавершенных
+// запусках добавляется предупреждение, так как часть записей в них не встречена
+func loadSnapshot(ctx context.Context, dbService *database.DBService, arg string, notes *[]string) (crawldiff.Snapshot, error) {
+	if arg == currentSnapshot {
+		items, err := dbService.CurrentSnapshot(ctx)
+		return crawldiff.Snapshot{Label: "текущее состояние", Items: items}, err
+	}
+
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return crawldiff.Snapshot{}, fmt.Errorf("invalid run id %q", arg)
+	}
+
+	run, err := dbService.CrawlRun(ctx, id)
+	if err != nil {
+		return crawldiff.Snapshot{}, err
+	}
+	if run.ExitReason != entity.RunCompleted {
+		*notes = append(*notes, fmt.Sprintf("запуск %d не был завершен полностью (%s): пропавшие записи могут быть не удалены на сайте", id, exitReasonOrRunning(run)))
+	}
+
+	items, err := dbService.RunSnapshot(ctx, id)
+	return crawldiff.Snapshot{Label: fmt.Sprintf("запуск %d", id), Items: items}, err
+}
+
+// exitReasonOrRunning возвращает причину завершения запуска или пометку, что он еще идет
+func exitReasonOrRunning(run entity.CrawlRun) string {
+	if run.FinishedAt.IsZero() {
+		return "еще выполняется"
+	}
+	return run.ExitReason
+}
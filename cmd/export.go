@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/seniorcat/scraper/config"
+	"github.com/seniorcat/scraper/database"
+	"github.com/seniorcat/scraper/entity"
+	"go.uber.org/zap"
+)
+
+// exportRecipe — строка выгрузки рецепта
+type exportRecipe struct {
+	Name          string                `json:"name"`
+	URL           string                `json:"url"`
+	Ingredients   []string              `json:"ingredients,omitempty"`
+	ImageURL      string                `json:"image_url,omitempty"`
+	Parsed        []entity.Ingredient   `json:"parsed_ingredients,omitempty"`
+	Details       *entity.RecipeDetails `json:"details,omitempty"`
+	Nutrition     *entity.NutritionInfo `json:"nutrition,omitempty"`
+	Tags          []entity.RecipeTag    `json:"tags,omitempty"`
+	LastSeenAt    *time.Time            `json:"last_seen_at,omitempty"`
+	DeletedAt     *time.Time            `json:"deleted_at,omitempty"`
+	DeletedReason string                `json:"deleted_reason,omitempty"`
+}
+
+// exportCategory — строка выгрузки категории
+type exportCategory struct {
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	LastSeenAt    *time.Time `json:"last_seen_at,omitempty"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	DeletedReason string     `json:"deleted_reason,omitempty"`
+}
+
+// Export выгружает рецепты или категории в JSON Lines; удаленные записи
+// выгружаются только с флагом -deleted
+func Export(args []string) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать логгер: %v", err)
+	}
+	defer logger.Sync()
+
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	kind := flags.String("type", "recipes", "Что выгружать: recipes или categories")
+	includeDeleted := flags.Bool("deleted", false, "Включить записи, помеченные удаленными")
+	output := flags.String("o", "", "Файл для выгрузки; по умолчанию стандартный вывод")
+	flags.Parse(args)
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		logger.Fatal("Ошибка загрузки конфигурации", zap.Error(err))
+	}
+
+	dbService, err := database.NewDBService(cfg.Database.URL)
+	if err != nil {
+		logger.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			logger.Fatal("Не удалось создать файл выгрузки", zap.Error(err))
+		}
+		defer file.Close()
+		out = file
+	}
+
+	ctx := context.Background()
+	encoder := json.NewEncoder(out)
+	count := 0
+
+	switch *kind {
+	case "recipes":
+		recipes, err := dbService.ExportRecipes(ctx, *includeDeleted)
+		if err != nil {
+			logger.Fatal("Не удалось выгрузить рецепты", zap.Error(err))
+		}
+		for _, r := range recipes {
+			if err := encoder.Encode(newExportRecipe(r)); err != nil {
+				logger.Fatal("Ошибка записи выгрузки", zap.Error(err))
+			}
+		}
+		count = len(recipes)
+	case "categories":
+		categories, err := dbService.ExportCategories(ctx, *includeDeleted)
+		if err != nil {
+			logger.Fatal("Не удалось выгрузить категории", zap.Error(err))
+		}
+		for _, c := range categories {
+			if err := encoder.Encode(newExportCategory(c)); err != nil {
+				logger.Fatal("Ошибка записи выгрузки", zap.Error(err))
+			}
+		}
+		count = len(categories)
+	default:
+		fmt.Printf("Неизвестный тип выгрузки: %s\n", *kind)
+		return
+	}
+
+	logger.Info("Export completed", zap.String("type", *kind), zap.Int("count", count))
+}
+
+// newExportRecipe преобразует рецепт в строку выгрузки
+func newExportRecipe(r entity.Recipe) exportRecipe {
+	return exportRecipe{
+		Name:          r.Name,
+		URL:           r.CanonicalURL,
+		Ingredients:   r.Ingredients,
+		ImageURL:      r.ImageURL,
+		Parsed:        r.Parsed,
+		Details:       r.Details,
+		Nutrition:     r.Nutrition,
+		Tags:          r.Tags,
+		LastSeenAt:    optionalTime(r.LastSeenAt),
+		DeletedAt:     optionalTime(r.DeletedAt),
+		DeletedReason: r.DeletedReason,
+	}
+}
+
+// newExportCategory преобразует категорию в строку выгрузки
+func newExportCategory(c entity.Category) exportCategory {
+	return exportCategory{
+		Name:          c.Name,
+		URL:           c.CanonicalURL,
+		LastSeenAt:    optionalTime(c.LastSeenAt),
+		DeletedAt:     optionalTime(c.DeletedAt),
+		DeletedReason: c.DeletedReason,
+	}
+}
+
+// optionalTime возвращает nil для нулевого времени, чтобы оно не попадало в выгрузку
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
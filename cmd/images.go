@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/seniorcat/scraper/config"
+	"github.com/seniorcat/scraper/database"
+	"github.com/seniorcat/scraper/pkg/phash"
+	"go.uber.org/zap"
+)
+
+// ImageClusters выводит группы почти одинаковых изображений из разных рецептов:
+// одну фотографию, опубликованную в нескольких категориях или на нескольких сайтах.
+// Все изображения с хешами загружаются из базы и сравниваются в памяти.
+func ImageClusters(args []string) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать логгер: %v", err)
+	}
+	defer logger.Sync()
+
+	flags := flag.NewFlagSet("images", flag.ExitOnError)
+	kind := flags.String("hash", phash.KindPerceptual, "Перцептивный хеш: phash, dhash или ahash")
+	distance := flags.Int("d", -1, "Наибольшее расстояние Хэмминга; по умолчанию images.maxHashDistance")
+	flags.Parse(args)
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		logger.Fatal("Ошибка загрузки конфигурации", zap.Error(err))
+	}
+	if *distance < 0 {
+		*distance = cfg.Images.MaxHashDistance
+	}
+
+	dbService, err := database.NewDBService(cfg.Database.URL)
+	if err != nil {
+		logger.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
+	}
+
+	clusters, err := dbService.ImageClusters(context.Background(), *kind, *distance)
+	if err != nil {
+		logger.Fatal("Не удалось найти похожие изображения", zap.Error(err))
+	}
+
+	fmt.Printf("Групп похожих изображений (%s, расстояние до %d): %d\n", *kind, *distance, len(clusters))
+	for i, cluster := range clusters {
+		fmt.Printf("\nГруппа %d: %d рецептов, %d изображений\n", i+1, len(cluster.Recipes()), len(cluster.Images))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, image := range cluster.Images {
+			hash, _ := phash.Hashes{Average: image.AHash, Difference: image.DHash, Perceptual: image.PHash}.Get(*kind)
+			fmt.Fprintf(w, "  %016x\t%s %d\t%dx%d\t%s\t%s\n",
+				hash, image.Kind, image.Position, image.Width, image.Height, image.RecipeURL, image.SourceURL)
+		}
+		w.Flush()
+	}
+}
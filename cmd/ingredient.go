@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/seniorcat/scraper/config"
+	"github.com/seniorcat/scraper/database"
+	"github.com/seniorcat/scraper/pkg/ingredient"
+	"go.uber.org/zap"
+)
+
+// RecipesWithIngredient выводит рецепты с ингредиентом: ingredient <название>
+func RecipesWithIngredient(args []string) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать логгер: %v", err)
+	}
+	defer logger.Sync()
+
+	if len(args) == 0 {
+		fmt.Println("Использование: ingredient <название ингредиента>")
+		return
+	}
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		logger.Fatal("Ошибка загрузки конфигурации", zap.Error(err))
+	}
+
+	catalog, err := ingredient.LoadCatalog(cfg.Ingredients.CatalogPath, cfg.Ingredients.Synonyms)
+	if err != nil {
+		logger.Fatal("Ошибка загрузки каталога ингредиентов", zap.Error(err))
+	}
+
+	// Название в любой форме сводится к каноническому: «яиц» — «яйцо куриное»
+	name := strings.Join(args, " ")
+	canonical, ok := catalog.Match(name)
+	if !ok {
+		fmt.Printf("Ингредиент %q не найден в каталоге\n", name)
+		return
+	}
+
+	dbService, err := database.NewDBService(cfg.Database.URL)
+	if err != nil {
+		logger.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
+	}
+
+	recipes, err := dbService.RecipesWithIngredient(context.Background(), canonical)
+	if err != nil {
+		logger.Fatal("Не удалось загрузить рецепты", zap.Error(err))
+	}
+
+	fmt.Printf("%s: %d рецептов\n", canonical, len(recipes))
+	for _, recipe := range recipes {
+		fmt.Printf("  %s  %s\n", recipe.Name, recipe.CanonicalURL)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/seniorcat/scraper/config"
+	"github.com/seniorcat/scraper/database"
+	"go.uber.org/zap"
+)
+
+// QuickRecipes выводит рецепты, которые готовятся не дольше заданного времени: quick <минут>
+func QuickRecipes(args []string) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать логгер: %v", err)
+	}
+	defer logger.Sync()
+
+	if len(args) != 1 {
+		fmt.Println("Использование: quick <минут>")
+		return
+	}
+	minutes, err := strconv.Atoi(args[0])
+	if err != nil || minutes <= 0 {
+		fmt.Printf("Некорректное время %q: нужно целое число минут\n", args[0])
+		return
+	}
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		logger.Fatal("Ошибка загрузки конфигурации", zap.Error(err))
+	}
+
+	dbService, err := database.NewDBService(cfg.Database.URL)
+	if err != nil {
+		logger.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
+	}
+
+	recipes, err := dbService.RecipesWithinTime(context.Background(), time.Duration(minutes)*time.Minute)
+	if err != nil {
+		logger.Fatal("Не удалось загрузить рецепты", zap.Error(err))
+	}
+
+	fmt.Printf("До %d минут: %d рецептов\n", minutes, len(recipes))
+	for _, recipe := range recipes {
+		total := time.Duration(recipe.Details.TotalSeconds) * time.Second
+		fmt.Printf("  %-8s %s  %s\n", total, recipe.Name, recipe.CanonicalURL)
+	}
+}
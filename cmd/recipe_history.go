@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/seniorcat/scraper/config"
+	"github.com/seniorcat/scraper/database"
+	"github.com/seniorcat/scraper/entity"
+	"go.uber.org/zap"
+)
+
+// RecipeHistory выводит историю изменений рецепта: history <url> [from to]
+func RecipeHistory(args []string) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать логгер: %v", err)
+	}
+	defer logger.Sync()
+
+	if len(args) != 1 && len(args) != 3 {
+		fmt.Println("Использование: history <канонический URL рецепта> [версия-от версия-до]")
+		return
+	}
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		logger.Fatal("Ошибка загрузки конфигурации", zap.Error(err))
+	}
+
+	dbService, err := database.NewDBService(cfg.Database.URL)
+	if err != nil {
+		logger.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
+	}
+
+	versions, err := dbService.RecipeVersions(context.Background(), args[0])
+	if err != nil {
+		logger.Fatal("Не удалось загрузить версии рецепта", zap.Error(err))
+	}
+	if len(versions) == 0 {
+		fmt.Println("Версии рецепта не найдены")
+		return
+	}
+
+	for _, v := range versions {
+		fmt.Printf("v%d  %s  %s\n", v.Version, v.CreatedAt.Format("2006-01-02 15:04:05"), v.Name)
+	}
+	fmt.Println()
+
+	// Без явных версий выводятся изменения между соседними версиями
+	if len(args) == 1 {
+		for i := 1; i < len(versions); i++ {
+			fmt.Print(entity.DiffRecipeVersions(versions[i-1], versions[i]))
+		}
+		return
+	}
+
+	from, errFrom := findVersion(versions, args[1])
+	to, errTo := findVersion(versions, args[2])
+	if errFrom != nil || errTo != nil {
+		fmt.Println("Версия не найдена")
+		return
+	}
+	fmt.Print(entity.DiffRecipeVersions(from, to))
+}
+
+// findVersion находит версию рецепта по номеру
+func findVersion(versions []entity.RecipeVersion, number string) (entity.RecipeVersion, error) {
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return entity.RecipeVersion{}, err
+	}
+	for _, v := range versions {
+		if v.Version == n {
+			return v, nil
+		}
+	}
+	return entity.RecipeVersion{}, fmt.Errorf("version %d not found", n)
+}
@@ -1,22 +1,45 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/seniorcat/scraper/config"
 	"github.com/seniorcat/scraper/database"
 	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/admin"
+	"github.com/seniorcat/scraper/pkg/anomaly"
+	"github.com/seniorcat/scraper/pkg/blob"
 	"github.com/seniorcat/scraper/pkg/cache"
+	"github.com/seniorcat/scraper/pkg/dedup"
+	"github.com/seniorcat/scraper/pkg/diet"
+	"github.com/seniorcat/scraper/pkg/imaging"
+	"github.com/seniorcat/scraper/pkg/ingredient"
+	"github.com/seniorcat/scraper/pkg/metrics"
+	"github.com/seniorcat/scraper/pkg/nutrition"
 	"github.com/seniorcat/scraper/worker"
 	"go.uber.org/zap"
 )
 
-// RunParser запускает контроллер задач и управляет процессом парсинга
-func RunParser() {
+// runStatus — состояние текущего запуска, отдаваемое на /status
+type runStatus struct {
+	RunID      int64                   `json:"run_id"`
+	StartedAt  time.Time               `json:"started_at"`
+	Uptime     string                  `json:"uptime"`
+	Controller worker.ControllerStatus `json:"controller"`
+}
+
+// RunParser запускает контроллер задач и управляет процессом парсинга. Возвращает
+// ошибку, если обход завершился аномалиями извлечения или потерей данных.
+func RunParser() error {
 	logger, err := zap.NewProduction()
 	if err != nil {
 		log.Fatalf("Не удалось инициализировать логгер: %v", err)
@@ -35,6 +58,24 @@ func RunParser() {
 		logger.Fatal("Ошибка подключения к базе данных", zap.Error(err))
 	}
 
+	startedAt := time.Now()
+
+	// Итог прошлого запуска отдается в метрике до проверки текущего: после
+	// проверки процесс завершается раньше, чем Prometheus успевает его считать
+	if runs, err := dbService.CrawlRuns(context.Background(), 10); err != nil {
+		logger.Warn("Не удалось загрузить итоги прошлого запуска", zap.Error(err))
+	} else if lastRunAnomalous(runs) {
+		metrics.RunAnomalous.Set(1)
+	}
+
+	// Регистрация запуска; сохраняемые строки привязываются к нему
+	runID, err := dbService.StartRun(context.Background(), configSnapshot(cfg), buildVersion())
+	if err != nil {
+		logger.Fatal("Не удалось зарегистрировать запуск", zap.Error(err))
+	}
+	dbService.RunID = runID
+	logger = logger.With(zap.Int64("run_id", runID))
+
 	// Считывание параметров из конфигурации
 	timeout := cfg.Worker.Timeout
 	maxRecipes := cfg.Worker.MaxRecipes
@@ -43,14 +84,81 @@ func RunParser() {
 	concurrency := cfg.Worker.Concurrency
 	rps := cfg.Worker.RPS
 
-	// Создание кеша
-	cache := cache.NewMemoryCache()
+	// Создание кеша посещенных ключей
+	seen := newCache(cfg)
 
 	// Создание воркера для категорий
-	categoryWorker := worker.NewCategoryWorker(logger, rps, time.Duration(timeout)*time.Second, cache)
+	categoryWorker := worker.NewCategoryWorker(logger, rps, time.Duration(timeout)*time.Second, seen)
+
+	// Пакетная запись в базу данных
+	writer := database.NewBatchWriter(dbService, cfg.Database.BatchSize, time.Duration(cfg.Database.FlushInterval)*time.Millisecond, logger)
+
+	// Потерянная пачка прерывает обход: продолжать его бессмысленно, а пометка
+	// пропавших записей по неполным данным удалила бы существующие рецепты
+	writeFailed := make(chan struct{})
+	var writeFailedOnce sync.Once
+	writer.OnError = func(table string, rows int, err error) {
+		writeFailedOnce.Do(func() { close(writeFailed) })
+	}
 
 	// Создание контроллера задач с DI для работы с базой данных
-	taskController := worker.NewTaskController(categoryWorker, concurrency, logger, time.Duration(retryInterval)*time.Second, maxRetries, dbService)
+	taskController := worker.NewTaskController(categoryWorker, concurrency, logger, time.Duration(retryInterval)*time.Second, maxRetries, writer)
+	taskController.Deduplicator = newDeduplicator(cfg, dbService, logger)
+	taskController.Details = dbService
+	taskController.TaskDeadline = time.Duration(cfg.Worker.TaskDeadline) * time.Second
+	taskController.WatchdogInterval = time.Duration(cfg.Worker.WatchdogInterval) * time.Second
+	if cfg.Scheduler.Enabled {
+		taskController.UsePriorityScheduler(newFreshnessPolicy(cfg, dbService, logger).Priority)
+		taskController.Stats = dbService
+	}
+	taskController.Tombstones = dbService
+
+	// Показатели извлечения для сравнения с прошлыми запусками
+	yield := anomaly.NewCollector()
+	categoryWorker.Parser.Yield = yield
+	taskController.Yield = yield
+
+	// Каталог канонических ингредиентов
+	catalog, err := ingredient.LoadCatalog(cfg.Ingredients.CatalogPath, cfg.Ingredients.Synonyms)
+	if err != nil {
+		logger.Fatal("Ошибка загрузки каталога ингредиентов", zap.Error(err))
+	}
+	taskController.Catalog = catalog
+	taskController.Diet, err = diet.NewClassifier(dietRules(cfg), catalog)
+	if err != nil {
+		logger.Fatal("Ошибка в правилах меток диет", zap.Error(err))
+	}
+	if err := dbService.SyncIngredients(context.Background(), catalog.Names()); err != nil {
+		logger.Warn("Не удалось сохранить каталог ингредиентов", zap.Error(err))
+	}
+
+	// Таблица состава продуктов для расчета пищевой ценности
+	nutrients, err := nutrition.LoadTable(cfg.Nutrition.TablePath)
+	if err != nil {
+		logger.Fatal("Ошибка загрузки таблицы состава продуктов", zap.Error(err))
+	}
+	taskController.Nutrition = nutritionCalculator(cfg, nutrients)
+
+	// Этап изображений: загрузка в собственное хранилище, чтобы не ссылаться на сайт
+	if cfg.Images.Enabled {
+		store, err := blobStore(cfg)
+		if err != nil {
+			logger.Fatal("Ошибка настройки хранилища изображений", zap.Error(err))
+		}
+		taskController.Imaging = imaging.NewProcessor(store, cfg.Images.Thumbnails)
+		taskController.Images = dbService
+		imageRPS := cfg.Images.RPS
+		if imageRPS <= 0 {
+			imageRPS = rps
+		}
+		taskController.ImageLimit = worker.NewRateLimiter(imageRPS)
+	}
+
+	// Запуск административного HTTP-сервера
+	adminServer := newAdminServer(cfg, logger, dbService, taskController, runID, startedAt)
+	if err := adminServer.Start(); err != nil {
+		logger.Fatal("Не удалось запустить административный сервер", zap.Error(err))
+	}
 
 	// Запуск контроллера задач
 	go taskController.Start(maxRecipes, rps, time.Duration(timeout)*time.Second)
@@ -61,35 +169,72 @@ func RunParser() {
 	// Создаем канал для категорий
 	categoryQueue := make(chan entity.Category)
 
-	// Запускаем парсинг категорий в отдельной горутине и передаем категории в канал
+	// Запускаем парсинг категорий в отдельной горутине и передаем категории в канал.
+	// Рецепты уже найденных категорий обходятся и при ошибке, но обход считается неполным.
+	var categoriesCutShort atomic.Bool
+	categoriesParsed := make(chan struct{})
 	go func() {
+		defer close(categoriesParsed)
 		err := categoryWorker.Start(categoryQueue) // Передаем канал в Start
 		if err != nil {
-			logger.Fatal("Ошибка парсинга категорий", zap.Error(err))
+			categoriesCutShort.Store(true)
+			logger.Error("Ошибка парсинга категорий", zap.Error(err))
 		}
 	}()
 
 	// Обрабатываем категории: отправляем их на сохранение и добавляем задачи на парсинг рецептов
+	categoriesDone := make(chan struct{})
 	go func() {
+		defer close(categoriesDone)
 		for category := range categoryQueue {
-			// Отправляем категорию на асинхронное сохранение
-			dbService.CategorySaveChan <- []entity.Category{category}
+			// Отправляем категорию на пакетное сохранение
+			if err := writer.SaveCategories(context.Background(), []entity.Category{category}); err != nil {
+				logger.Error("Не удалось поставить категорию на сохранение", zap.String("category", category.Name), zap.Error(err))
+			}
 
 			// Добавляем задачу на парсинг рецептов
-			taskController.TaskQueue <- worker.Task{
+			taskController.AddTask(worker.Task{
 				ID:       category.Name,
-				Type:     "recipe",
+				Type:     worker.TaskListing,
 				Category: &category,
-			}
+			})
 		}
 	}()
 
+	// SIGUSR1 приостанавливает выдачу задач, SIGUSR2 возобновляет ее
+	go handleControlSignals(taskController, logger)
+
 	// Обработка сигналов для корректной остановки воркеров
 	stopChan := make(chan os.Signal, 1)
 	signal.Notify(stopChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Ожидание сигнала остановки
-	<-stopChan
+	// Обход завершен, когда все категории получены и у контроллера не осталось работы
+	completed := make(chan struct{})
+	stopWaiting := make(chan struct{})
+	go func() {
+		<-categoriesParsed
+		<-categoriesDone
+		if taskController.WaitIdle(stopWaiting, time.Second) {
+			close(completed)
+		}
+	}()
+
+	// Ожидание завершения обхода или сигнала остановки
+	runCompleted := false
+	exitReason := entity.RunCompleted
+	select {
+	case <-completed:
+		runCompleted = true
+		logger.Info("Crawl completed")
+	case sig := <-stopChan:
+		close(stopWaiting)
+		exitReason = entity.RunInterrupted + ": " + sig.String()
+		logger.Info("Stop signal received", zap.String("signal", sig.String()))
+	case <-writeFailed:
+		close(stopWaiting)
+		exitReason = entity.RunWriteFailed
+		logger.Error("Обход прерван: не удалось записать данные в базу")
+	}
 
 	// Закрытие каналов для завершения работы воркеров
 	close(dbService.CategorySaveChan)
@@ -97,5 +242,310 @@ func RunParser() {
 
 	// Остановка контроллера задач
 	taskController.Stop()
+
+	// Сброс накопленных пачек в базу данных
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelFlush()
+	if err := writer.Close(flushCtx); err != nil {
+		logger.Error("Не удалось сохранить накопленные данные", zap.Error(err))
+		// Запуск с потерянными данными не считается полным обходом
+		if runCompleted {
+			runCompleted = false
+			exitReason = entity.RunWriteFailed
+		}
+	}
+
+	// Проверка извлечения на аномалии; при поломке разметки запуск проваливается,
+	// а пропавшие записи не помечаются удаленными
+	anomalous := false
+	if runCompleted {
+		anomalies := detectAnomalies(cfg, dbService, runID, yield.Snapshot(), logger)
+		if anomaly.Critical(anomalies) {
+			anomalous, runCompleted = true, false
+			exitReason = entity.RunAnomaly + ": " + anomalyKinds(anomalies)
+		}
+	}
+
+	// Пометка удаленными записей, пропавших с сайта; только после полного обхода
+	if runCompleted {
+		sweepMissing(cfg, dbService, taskController, categoriesCutShort.Load(), startedAt, logger)
+	}
+
+	// Фиксация итогов запуска
+	run, err := dbService.FinishRun(context.Background(), runID, taskController.Status().Tasks[worker.StatusError], exitReason)
+	if err != nil {
+		logger.Error("Не удалось сохранить итоги запуска", zap.Error(err))
+	} else {
+		logger.Info("Run finished",
+			zap.String("exit_reason", run.ExitReason),
+			zap.Duration("duration", run.Duration),
+			zap.Int("categories", run.Categories),
+			zap.Int("recipes", run.Recipes),
+			zap.Int("new_recipes", run.NewRecipes),
+			zap.Int("updated_recipes", run.UpdatedRecipes),
+			zap.Int("failed_tasks", run.FailedTasks))
+	}
+
+	// Остановка административного сервера
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := adminServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Не удалось остановить административный сервер", zap.Error(err))
+	}
+
+	if anomalous {
+		logger.Error("Парсинг завершен с аномалиями извлечения")
+		return fmt.Errorf("run %d: %s", runID, exitReason)
+	}
+	if failed := writer.Failed(); failed > 0 {
+		logger.Error("Парсинг завершен с потерей данных", zap.Int64("rows", failed))
+		return fmt.Errorf("run %d: %d rows not saved", runID, failed)
+	}
+
 	logger.Info("Парсинг завершен.")
+	return nil
+}
+
+// lastRunAnomalous сообщает, завершился ли аномалиями последний завершенный запуск;
+// runs упорядочены от нового к старому, незавершенные пропускаются
+func lastRunAnomalous(runs []entity.CrawlRun) bool {
+	for _, run := range runs {
+		if run.ExitReason != "" {
+			return strings.HasPrefix(run.ExitReason, entity.RunAnomaly)
+		}
+	}
+	return false
+}
+
+// detectAnomalies сравнивает показатели извлечения с прошлыми полными обходами,
+// сохраняет их для следующих запусков и возвращает обнаруженные аномалии
+func detectAnomalies(cfg *config.Config, dbService *database.DBService, runID int64, stats anomaly.Stats, logger *zap.Logger) []anomaly.Anomaly {
+	var anomalies []anomaly.Anomaly
+	if cfg.Anomaly.Enabled {
+		baselineRuns := cfg.Anomaly.BaselineRuns
+		if baselineRuns <= 0 {
+			baselineRuns = 5
+		}
+		history, err := dbService.YieldBaseline(context.Background(), baselineRuns)
+		if err != nil {
+			logger.Error("Не удалось загрузить показатели прошлых запусков", zap.Error(err))
+		}
+
+		anomalies = anomaly.Detect(stats, history, anomalyThresholds(cfg))
+		for _, a := range anomalies {
+			metrics.AnomaliesDetected.WithLabelValues(a.Kind, a.Severity, a.Stage).Inc()
+			if a.Severity == anomaly.SeverityCritical {
+				logger.Error("Обнаружена аномалия извлечения", zap.String("anomaly", a.String()))
+			} else {
+				logger.Warn("Обнаружена аномалия извлечения", zap.String("anomaly", a.String()))
+			}
+		}
+		if anomaly.Critical(anomalies) {
+			metrics.RunAnomalous.Set(1)
+		} else {
+			metrics.RunAnomalous.Set(0)
+		}
+		logger.Info("Extraction checked against baseline", zap.Int("baseline_runs", len(history)), zap.Int("anomalies", len(anomalies)))
+	}
+
+	if err := dbService.SaveYieldStats(context.Background(), runID, stats, anomalies); err != nil {
+		logger.Error("Не удалось сохранить показатели извлечения", zap.Error(err))
+	}
+	return anomalies
+}
+
+// anomalyThresholds возвращает пороги из конфигурации; незаданные берутся по умолчанию
+func anomalyThresholds(cfg *config.Config) anomaly.Thresholds {
+	t := anomaly.DefaultThresholds
+	if cfg.Anomaly.MaxDrop > 0 {
+		t.MaxDrop = cfg.Anomaly.MaxDrop
+	}
+	if cfg.Anomaly.MaxCategoryDrop > 0 {
+		t.MaxCategoryDrop = cfg.Anomaly.MaxCategoryDrop
+	}
+	if cfg.Anomaly.MaxEmptyRateGrowth > 0 {
+		t.MaxEmptyRateGrowth = cfg.Anomaly.MaxEmptyRateGrowth
+	}
+	if cfg.Anomaly.MaxErrorRateGrowth > 0 {
+		t.MaxErrorRateGrowth = cfg.Anomaly.MaxErrorRateGrowth
+	}
+	return t
+}
+
+// blobStore создает хранилище изображений по конфигурации
+func blobStore(cfg *config.Config) (blob.Store, error) {
+	switch cfg.Images.Store {
+	case blob.KindFile, "":
+		return blob.NewFileStore(cfg.Images.Path)
+	case blob.KindS3:
+		s3 := cfg.Images.S3
+		if s3.Bucket == "" {
+			return nil, fmt.Errorf("images.s3.bucket is empty")
+		}
+		accessKey, secretKey := s3.AccessKey, s3.SecretKey
+		if accessKey == "" {
+			accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		}
+		if secretKey == "" {
+			secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		}
+		return blob.NewS3Store(s3.Endpoint, s3.Region, s3.Bucket, accessKey, secretKey), nil
+	default:
+		return nil, fmt.Errorf("unknown image store %q", cfg.Images.Store)
+	}
+}
+
+// dietRules преобразует правила меток диет из конфигурации
+func dietRules(cfg *config.Config) []diet.Rule {
+	rules := make([]diet.Rule, len(cfg.Diet.Rules))
+	for i, r := range cfg.Diet.Rules {
+		rules[i] = diet.Rule{Tag: r.Tag, Contains: r.Contains, Excludes: r.Excludes}
+	}
+	return rules
+}
+
+// nutritionCalculator создает калькулятор пищевой ценности с порогами из конфигурации
+func nutritionCalculator(cfg *config.Config, table *nutrition.Table) *nutrition.Calculator {
+	calculator := nutrition.NewCalculator(table)
+	if cfg.Nutrition.MaxDiscrepancy > 0 {
+		calculator.MaxDiscrepancy = cfg.Nutrition.MaxDiscrepancy
+	}
+	if cfg.Nutrition.MinCoverage > 0 {
+		calculator.MinCoverage = cfg.Nutrition.MinCoverage
+	}
+	return calculator
+}
+
+// anomalyKinds перечисляет критические аномалии для причины завершения запуска
+func anomalyKinds(anomalies []anomaly.Anomaly) string {
+	var kinds []string
+	for _, a := range anomalies {
+		if a.Severity == anomaly.SeverityCritical {
+			kinds = append(kinds, a.Kind+" "+a.Stage)
+		}
+	}
+	return strings.Join(kinds, ", ")
+}
+
+// sweepMissing помечает удаленными рецепты и категории, не встреченные несколько полных обходов подряд
+func sweepMissing(cfg *config.Config, dbService *database.DBService, taskController *worker.TaskController, categoriesCutShort bool, startedAt time.Time, logger *zap.Logger) {
+	if cfg.Tombstone.MaxMissedRuns <= 0 {
+		return
+	}
+
+	// Рецепты необойденных категорий не встречены, но с сайта не пропали
+	if categoriesCutShort {
+		logger.Warn("Пометка пропавших записей пропущена: обход категорий прерван")
+		return
+	}
+
+	// Рецепты упавших задач не встречены, но со страниц не пропали
+	if failed := taskController.Status().Tasks[worker.StatusError]; failed > 0 {
+		logger.Warn("Пометка пропавших записей пропущена: часть задач завершилась ошибкой", zap.Int("failed_tasks", failed))
+		return
+	}
+
+	for _, table := range []string{"recipes", "categories"} {
+		deleted, err := dbService.SweepMissing(context.Background(), table, startedAt, cfg.Tombstone.MaxMissedRuns)
+		if err != nil {
+			logger.Error("Не удалось пометить пропавшие записи", zap.String("table", table), zap.Error(err))
+			continue
+		}
+		logger.Info("Missing entities marked as deleted", zap.String("table", table), zap.Int64("count", deleted))
+	}
+}
+
+// handleControlSignals приостанавливает и возобновляет обход по сигналам
+func handleControlSignals(taskController *worker.TaskController, logger *zap.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	for sig := range signals {
+		logger.Info("Control signal received", zap.String("signal", sig.String()))
+		switch sig {
+		case syscall.SIGUSR1:
+			taskController.Pause()
+		case syscall.SIGUSR2:
+			taskController.Resume()
+		}
+	}
+}
+
+// newAdminServer создает административный сервер с пробами готовности и статусом запуска
+func newAdminServer(cfg *config.Config, logger *zap.Logger, dbService *database.DBService, taskController *worker.TaskController, runID int64, startedAt time.Time) *admin.Server {
+	server := admin.NewServer(cfg.Admin.Address, logger)
+	token := cfg.Admin.Token
+	if token == "" {
+		token = os.Getenv("SCRAPER_ADMIN_TOKEN")
+	}
+	server.SetToken(token)
+
+	server.AddReadinessCheck("database", dbService.Ping)
+	server.AddReadinessCheck("workers", func(ctx context.Context) error {
+		if alive, desired := taskController.AliveWorkers(), taskController.DesiredWorkers(); alive < desired {
+			return fmt.Errorf("%d of %d workers alive", alive, desired)
+		}
+		return nil
+	})
+
+	server.SetController(taskController)
+	server.SetStatusProvider(func() any {
+		return runStatus{
+			RunID:      runID,
+			StartedAt:  startedAt,
+			Uptime:     time.Since(startedAt).Round(time.Second).String(),
+			Controller: taskController.Status(),
+		}
+	})
+
+	return server
+}
+
+// newFreshnessPolicy создает политику приоритетов по истории обходов категорий
+func newFreshnessPolicy(cfg *config.Config, dbService *database.DBService, logger *zap.Logger) *worker.FreshnessPolicy {
+	boosts := make(map[string]float64, len(cfg.Scheduler.Boosts))
+	for key, boost := range cfg.Scheduler.Boosts {
+		boosts[strings.ToLower(strings.TrimSpace(key))] = boost
+	}
+
+	stats, err := dbService.LoadCategoryStats(context.Background())
+	if err != nil {
+		logger.Warn("Не удалось загрузить статистику обходов категорий", zap.Error(err))
+	}
+	logger.Info("Category crawl stats loaded", zap.Int("count", len(stats)))
+
+	return &worker.FreshnessPolicy{
+		Stats:        stats,
+		Boosts:       boosts,
+		ChangeWeight: cfg.Scheduler.ChangeWeight,
+	}
+}
+
+// newDeduplicator создает дедупликатор рецептов и загружает в него отпечатки сохраненных рецептов
+func newDeduplicator(cfg *config.Config, dbService *database.DBService, logger *zap.Logger) *dedup.Deduplicator {
+	// URL текущего обхода не сохраняются между запусками, иначе рецепты не обновлялись бы.
+	// Множество точное: ложное срабатывание фильтра Блума отбросило бы уникальный рецепт.
+	deduplicator := dedup.NewDeduplicator(cache.NewMemoryCache(), cfg.Dedup.MaxDistance)
+
+	fingerprints, err := dbService.LoadRecipeFingerprints(context.Background())
+	if err != nil {
+		logger.Warn("Не удалось загрузить отпечатки рецептов", zap.Error(err))
+		return deduplicator
+	}
+	for url, fingerprint := range fingerprints {
+		deduplicator.Seed(url, fingerprint)
+	}
+	logger.Info("Recipe fingerprints loaded", zap.Int("count", len(fingerprints)))
+
+	return deduplicator
+}
+
+// newCache создает кеш посещенных ключей согласно конфигурации. Кеш пуст в начале
+// каждого запуска: категории, встреченные в прошлом запуске, нужно обойти снова,
+// иначе в них не найдутся новые и изменившиеся рецепты
+func newCache(cfg *config.Config) cache.Cache {
+	if cfg.Cache.Type != "bloom" {
+		return cache.NewMemoryCache()
+	}
+	return cache.NewBloomCache(cfg.Cache.Capacity, cfg.Cache.FalsePositiveRate)
 }
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/seniorcat/scraper/config"
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestLastRunAnomalous проверяет восстановление признака аномального запуска по истории
+func TestLastRunAnomalous(t *testing.T) {
+	anomaly := entity.CrawlRun{ID: 2, ExitReason: entity.RunAnomaly + ": recipe_yield_drop"}
+	completed := entity.CrawlRun{ID: 1, ExitReason: entity.RunCompleted}
+	running := entity.CrawlRun{ID: 3} // Незавершенный или оборвавшийся запуск
+
+	assert.True(t, lastRunAnomalous([]entity.CrawlRun{running, anomaly, completed}))
+	assert.False(t, lastRunAnomalous([]entity.CrawlRun{completed, anomaly}))
+	assert.False(t, lastRunAnomalous(nil))
+}
+
+// siteTransport направляет запросы к сайту-источнику на тестовый сервер
+type siteTransport struct {
+	server *httptest.Server
+}
+
+func (t siteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := url.Parse(t.server.URL)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestRepeatedCrawlListsRecipes проверяет, что повторный запуск с фильтром Блума
+// снова обходит категории и находит в них рецепты
+func TestRepeatedCrawlListsRecipes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><nav><a href="/recepty/supy">Супы</a></nav></body></html>`))
+	})
+	mux.HandleFunc("/recepty/supy", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><article><a href="/recepty/supy/borsch-1"><h3>Борщ</h3></a></article></body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Cache.Type = "bloom"
+	cfg.Cache.Capacity = 1000
+	cfg.Cache.FalsePositiveRate = 0.001
+
+	for run := 1; run <= 2; run++ {
+		categoryWorker := worker.NewCategoryWorker(zap.NewNop(), 100, time.Second, newCache(cfg))
+		categoryWorker.Parser.Collector.WithTransport(siteTransport{server: server})
+		categoryQueue := make(chan entity.Category, 10)
+		require.NoError(t, categoryWorker.Start(categoryQueue))
+
+		var categories []entity.Category
+		for category := range categoryQueue {
+			categories = append(categories, category)
+		}
+		require.Len(t, categories, 1, "run %d", run)
+
+		recipeParser := worker.NewRecipeParser(zap.NewNop(), 10, 100, time.Second)
+		recipeParser.Collector.WithTransport(siteTransport{server: server})
+		recipes, err := recipeParser.ParseRecipes(categories[0])
+		require.NoError(t, err)
+		assert.Len(t, recipes, 1, "run %d", run)
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/seniorcat/scraper/config"
+	"github.com/seniorcat/scraper/database"
+	"go.uber.org/zap"
+)
+
+// ListRuns выводит последние запуски парсера с их итогами
+func ListRuns(args []string) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать логгер: %v", err)
+	}
+	defer logger.Sync()
+
+	flags := flag.NewFlagSet("runs", flag.ExitOnError)
+	limit := flags.Int("n", 20, "Количество последних запусков")
+	flags.Parse(args)
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		logger.Fatal("Ошибка загрузки конфигурации", zap.Error(err))
+	}
+
+	dbService, err := database.NewDBService(cfg.Database.URL)
+	if err != nil {
+		logger.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
+	}
+
+	runs, err := dbService.CrawlRuns(context.Background(), *limit)
+	if err != nil {
+		logger.Fatal("Не удалось загрузить запуски", zap.Error(err))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tНАЧАЛО\tДЛИТЕЛЬНОСТЬ\tКАТЕГОРИИ\tРЕЦЕПТЫ\tНОВЫЕ\tИЗМЕНЕНЫ\tОШИБКИ\tЗАВЕРШЕНИЕ\tВЕРСИЯ")
+	for _, run := range runs {
+		exitReason := run.ExitReason
+		if run.FinishedAt.IsZero() {
+			exitReason = "не завершен"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			run.ID, run.StartedAt.Format("2006-01-02 15:04:05"), run.Duration.Round(time.Second),
+			run.Categories, run.Recipes, run.NewRecipes, run.UpdatedRecipes, run.FailedTasks,
+			exitReason, run.Version)
+	}
+	w.Flush()
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/seniorcat/scraper/config"
+	"github.com/seniorcat/scraper/database"
+	"github.com/seniorcat/scraper/pkg/api"
+	"go.uber.org/zap"
+)
+
+// Serve запускает HTTP-сервер запросов к сохраненным рецептам и работает до сигнала остановки
+func Serve() {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать логгер: %v", err)
+	}
+	defer logger.Sync()
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		logger.Fatal("Ошибка загрузки конфигурации", zap.Error(err))
+	}
+
+	dbService, err := database.NewDBService(cfg.Database.URL)
+	if err != nil {
+		logger.Fatal("Не удалось подключиться к базе данных", zap.Error(err))
+	}
+
+	server := api.NewServer(cfg.Query.Address, logger)
+	server.SetRecipeStore(dbService, database.ErrRecipeNotFound)
+	if err := server.Start(); err != nil {
+		logger.Fatal("Не удалось запустить сервер запросов", zap.Error(err))
+	}
+
+	stopChan := make(chan os.Signal, 1)
+	signal.Notify(stopChan, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-stopChan
+	logger.Info("Stop signal received", zap.String("signal", sig.String()))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Не удалось остановить сервер запросов", zap.Error(err))
+	}
+}
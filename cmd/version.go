@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/url"
+	"runtime/debug"
+
+	"github.com/seniorcat/scraper/config"
+)
+
+// Version задается при сборке: -ldflags "-X github.com/seniorcat/scraper/cmd.Version=v1.2.3"
+var Version = ""
+
+// buildVersion возвращает версию сборки: Version, если задана, иначе ревизию из данных сборки Go
+func buildVersion() string {
+	if Version != "" {
+		return Version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	version := info.Main.Version
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			version += " " + setting.Value
+		case "vcs.modified":
+			if setting.Value == "true" {
+				version += "-dirty"
+			}
+		}
+	}
+	return version
+}
+
+// configSnapshot возвращает конфигурацию запуска в JSON без паролей и ключей доступа
+func configSnapshot(cfg *config.Config) []byte {
+	snapshot := *cfg
+	snapshot.Database.URL = redactURL(cfg.Database.URL)
+	snapshot.Images.S3.Endpoint = redactURL(cfg.Images.S3.Endpoint)
+	snapshot.Images.S3.AccessKey = ""
+	snapshot.Images.S3.SecretKey = ""
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// redactURL скрывает пароль в адресе; адрес, который не разбирается, не сохраняется
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Redacted()
+}
@@ -9,9 +9,20 @@ import (
 // Config описывает структуру конфигурационного файла
 type Config struct {
 	Database struct {
-		URL string `yaml:"url"`
+		URL           string `yaml:"url"`
+		BatchSize     int    `yaml:"batchSize"`     // Количество строк, при котором пачка сбрасывается в базу
+		FlushInterval int    `yaml:"flushInterval"` // Максимальное время накопления пачки, миллисекунд
 	} `yaml:"database"`
 
+	Admin struct {
+		Address string `yaml:"address"` // Адрес административного HTTP-сервера
+		Token   string `yaml:"token"`   // Токен для /control/* и /debug/pprof; пустой — берется из SCRAPER_ADMIN_TOKEN
+	} `yaml:"admin"`
+
+	Query struct {
+		Address string `yaml:"address"` // Адрес HTTP-сервера запросов команды serve
+	} `yaml:"query"`
+
 	Worker struct {
 		Type          int `yaml:"type"`
 		MaxRecipes    int `yaml:"maxRecipes"`
@@ -20,7 +31,69 @@ type Config struct {
 		RetryInterval int `yaml:"retryInterval"`
 		Concurrency   int `yaml:"concurrency"`
 		RPS           int `yaml:"rps"`
+
+		TaskDeadline     int `yaml:"taskDeadline"`     // Максимальное время выполнения задачи, секунд; 0 отключает сторожа
+		WatchdogInterval int `yaml:"watchdogInterval"` // Период проверок сторожа, секунд
 	} `yaml:"worker"`
+
+	Cache struct {
+		Type              string  `yaml:"type"` // memory или bloom
+		Capacity          uint64  `yaml:"capacity"`
+		FalsePositiveRate float64 `yaml:"falsePositiveRate"`
+	} `yaml:"cache"`
+
+	Dedup struct {
+		MaxDistance int `yaml:"maxDistance"` // Максимальное расстояние Хэмминга между отпечатками дубликатов
+	} `yaml:"dedup"`
+
+	Scheduler struct {
+		Enabled      bool               `yaml:"enabled"`      // Выдавать задачи по приоритету свежести вместо FIFO
+		ChangeWeight float64            `yaml:"changeWeight"` // Вес частоты изменений категории
+		Boosts       map[string]float64 `yaml:"boosts"`       // Повышение приоритета по названию или URL категории, в часах давности
+	} `yaml:"scheduler"`
+
+	Tombstone struct {
+		MaxMissedRuns int `yaml:"maxMissedRuns"` // После скольких полных обходов без записи она помечается удаленной; 0 отключает
+	} `yaml:"tombstone"`
+	Ingredients struct {
+		CatalogPath string              `yaml:"catalogPath"` // Дополнительный каталог ингредиентов в формате встроенного
+		Synonyms    map[string][]string `yaml:"synonyms"`    // Канонический ингредиент и его синонимы
+	} `yaml:"ingredients"`
+	Nutrition struct {
+		TablePath      string  `yaml:"tablePath"`      // Дополнительная таблица состава продуктов в формате встроенного CSV
+		MaxDiscrepancy float64 `yaml:"maxDiscrepancy"` // Допустимое расхождение расчета с данными сайта, доля
+		MinCoverage    float64 `yaml:"minCoverage"`    // Доля учтенных ингредиентов, начиная с которой расчет сверяется с сайтом
+	} `yaml:"nutrition"`
+	Diet struct {
+		Rules []struct {
+			Tag      string   `yaml:"tag"`
+			Contains []string `yaml:"contains"` // Метка, если есть любой из ингредиентов
+			Excludes []string `yaml:"excludes"` // Метка, если состав распознан и ни одного из ингредиентов нет
+		} `yaml:"rules"`
+	} `yaml:"diet"`
+	Images struct {
+		Enabled         bool   `yaml:"enabled"`         // Загружать изображения рецептов в собственное хранилище
+		Store           string `yaml:"store"`           // file или s3
+		Path            string `yaml:"path"`            // Каталог хранилища file
+		Thumbnails      []int  `yaml:"thumbnails"`      // Ширины уменьшенных копий в пикселях
+		RPS             int    `yaml:"rps"`             // Общий для всех воркеров лимит загрузки изображений в секунду; 0 — как worker.rps
+		MaxHashDistance int    `yaml:"maxHashDistance"` // Изображения с перцептивными хешами не дальше этого расстояния считаются одинаковыми
+		S3              struct {
+			Endpoint  string `yaml:"endpoint"` // Адрес S3-совместимого сервиса, например MinIO
+			Region    string `yaml:"region"`
+			Bucket    string `yaml:"bucket"`
+			AccessKey string `yaml:"accessKey"` // Пустой — берется из AWS_ACCESS_KEY_ID
+			SecretKey string `yaml:"secretKey"` // Пустой — берется из AWS_SECRET_ACCESS_KEY
+		} `yaml:"s3"`
+	} `yaml:"images"`
+	Anomaly struct {
+		Enabled            bool    `yaml:"enabled"`            // Сравнивать извлечение с прошлыми запусками и проваливать запуск при аномалиях
+		BaselineRuns       int     `yaml:"baselineRuns"`       // Сколько последних успешных запусков образуют базовую линию
+		MaxDrop            float64 `yaml:"maxDrop"`            // Допустимое падение числа записей этапа, доля
+		MaxCategoryDrop    float64 `yaml:"maxCategoryDrop"`    // Допустимое падение числа рецептов категории, доля
+		MaxEmptyRateGrowth float64 `yaml:"maxEmptyRateGrowth"` // Допустимый рост доли пустых обязательных полей
+		MaxErrorRateGrowth float64 `yaml:"maxErrorRateGrowth"` // Допустимый рост доли ошибок валидации
+	} `yaml:"anomaly"`
 }
 
 // LoadConfig загружает конфигурацию из файла YAML
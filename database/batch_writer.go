@@ -0,0 +1,224 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+	"go.uber.org/zap"
+)
+
+// Значения по умолчанию для BatchWriter
+const (
+	DefaultBatchSize     = 500
+	DefaultFlushInterval = 2 * time.Second
+)
+
+// ErrWriterClosed возвращается при записи в остановленный BatchWriter
+var ErrWriterClosed = errors.New("batch writer is closed")
+
+// ErrFlushFailed возвращается из Close, если часть пачек не удалось записать
+var ErrFlushFailed = errors.New("batch flush failed")
+
+// BatchWriter накапливает категории и рецепты из разных результатов и сохраняет
+// их пачками по размеру или по таймеру. Реализует DBServiceInterface: SaveRecipes и
+// SaveCategories только ставят строки в очередь и блокируются, пока очередь заполнена,
+// поэтому об ошибках записи сообщают OnError, Failed и Close.
+type BatchWriter struct {
+	Logger  *zap.Logger
+	OnError func(table string, rows int, err error) // Вызывается из цикла сброса при ошибке записи пачки; задается до первой записи (может быть nil)
+
+	batchSize int
+	interval  time.Duration
+
+	writeRecipes    func(ctx context.Context, recipes []entity.Recipe) error
+	writeCategories func(ctx context.Context, categories []entity.Category) error
+
+	recipes    chan []entity.Recipe
+	categories chan []entity.Category
+
+	mu     sync.RWMutex // Защищает отправку в каналы от их закрытия
+	closed bool
+	done   chan struct{} // Закрывается после сброса последней пачки
+	failed atomic.Int64  // Строки пачек, которые не удалось записать
+}
+
+// NewBatchWriter создает BatchWriter, сохраняющий данные через COPY во временную таблицу
+func NewBatchWriter(db *DBService, batchSize int, interval time.Duration, logger *zap.Logger) *BatchWriter {
+	return newBatchWriter(db.CopyRecipes, db.CopyCategories, batchSize, interval, logger)
+}
+
+// newBatchWriter создает BatchWriter с заданными функциями записи и запускает цикл сброса
+func newBatchWriter(
+	writeRecipes func(ctx context.Context, recipes []entity.Recipe) error,
+	writeCategories func(ctx context.Context, categories []entity.Category) error,
+	batchSize int, interval time.Duration, logger *zap.Logger,
+) *BatchWriter {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+
+	w := &BatchWriter{
+		Logger:          logger,
+		batchSize:       batchSize,
+		interval:        interval,
+		writeRecipes:    writeRecipes,
+		writeCategories: writeCategories,
+		// Очередь ограничена, чтобы медленная база притормаживала обход, а не копила память
+		recipes:    make(chan []entity.Recipe, 16),
+		categories: make(chan []entity.Category, 16),
+		done:       make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// SaveRecipes ставит рецепты в очередь на запись
+func (w *BatchWriter) SaveRecipes(ctx context.Context, recipes []entity.Recipe) error {
+	if len(recipes) == 0 {
+		return nil
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	select {
+	case w.recipes <- recipes:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SaveCategories ставит категории в очередь на запись
+func (w *BatchWriter) SaveCategories(ctx context.Context, categories []entity.Category) error {
+	if len(categories) == 0 {
+		return nil
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	select {
+	case w.categories <- categories:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Failed возвращает количество строк, которые не удалось записать
+func (w *BatchWriter) Failed() int64 {
+	return w.failed.Load()
+}
+
+// Close прекращает прием данных и ждет сброса накопленных строк в базу. Возвращает
+// ErrFlushFailed, если за время работы не удалось записать хотя бы одну пачку.
+func (w *BatchWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.recipes)
+		close(w.categories)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if failed := w.Failed(); failed > 0 {
+		return fmt.Errorf("%w: %d rows not saved", ErrFlushFailed, failed)
+	}
+	return nil
+}
+
+// run накапливает строки и сбрасывает их по размеру пачки, по таймеру и при закрытии
+func (w *BatchWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var (
+		recipes    []entity.Recipe
+		categories []entity.Category
+	)
+
+	flushRecipes := func() {
+		if len(recipes) > 0 {
+			w.flush("recipes", len(recipes), func(ctx context.Context) error { return w.writeRecipes(ctx, recipes) })
+			recipes = nil
+		}
+	}
+	flushCategories := func() {
+		if len(categories) > 0 {
+			w.flush("categories", len(categories), func(ctx context.Context) error { return w.writeCategories(ctx, categories) })
+			categories = nil
+		}
+	}
+
+	recipesQueue, categoriesQueue := w.recipes, w.categories
+	for recipesQueue != nil || categoriesQueue != nil {
+		select {
+		case batch, ok := <-recipesQueue:
+			if !ok {
+				recipesQueue = nil
+				continue
+			}
+			recipes = append(recipes, batch...)
+			if len(recipes) >= w.batchSize {
+				flushRecipes()
+			}
+		case batch, ok := <-categoriesQueue:
+			if !ok {
+				categoriesQueue = nil
+				continue
+			}
+			categories = append(categories, batch...)
+			if len(categories) >= w.batchSize {
+				flushCategories()
+			}
+		case <-ticker.C:
+			// Категории сбрасываются первыми: рецепты ссылаются на них по смыслу
+			flushCategories()
+			flushRecipes()
+		}
+	}
+
+	flushCategories()
+	flushRecipes()
+}
+
+// flush записывает пачку и журналирует результат; ошибка записи не останавливает
+// цикл, а учитывается в Failed и передается в OnError
+func (w *BatchWriter) flush(table string, rows int, write func(ctx context.Context) error) {
+	if err := write(context.Background()); err != nil {
+		w.Logger.Error("Failed to flush batch", zap.String("table", table), zap.Int("rows", rows), zap.Error(err))
+		w.failed.Add(int64(rows))
+		if w.OnError != nil {
+			w.OnError(table, rows, err)
+		}
+		return
+	}
+	w.Logger.Debug("Batch flushed", zap.String("table", table), zap.Int("rows", rows))
+}
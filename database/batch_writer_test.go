@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recorder запоминает пачки, переданные на запись
+type recorder struct {
+	mu         sync.Mutex
+	recipes    [][]entity.Recipe
+	categories [][]entity.Category
+	block      chan struct{} // Если задан, запись рецептов ждет его закрытия
+}
+
+func (r *recorder) writeRecipes(ctx context.Context, recipes []entity.Recipe) error {
+	if r.block != nil {
+		<-r.block
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recipes = append(r.recipes, recipes)
+	return nil
+}
+
+func (r *recorder) writeCategories(ctx context.Context, categories []entity.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.categories = append(r.categories, categories)
+	return nil
+}
+
+func (r *recorder) recipeBatches() [][]entity.Recipe {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]entity.Recipe(nil), r.recipes...)
+}
+
+// TestBatchWriterFlushBySize проверяет объединение строк разных результатов в пачку заданного размера
+func TestBatchWriterFlushBySize(t *testing.T) {
+	rec := &recorder{}
+	w := newBatchWriter(rec.writeRecipes, rec.writeCategories, 3, time.Hour, zap.NewNop())
+	ctx := context.Background()
+
+	assert.NoError(t, w.SaveRecipes(ctx, []entity.Recipe{{Name: "a"}, {Name: "b"}}))
+	assert.NoError(t, w.SaveRecipes(ctx, []entity.Recipe{{Name: "c"}}))
+	assert.Eventually(t, func() bool { return len(rec.recipeBatches()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Len(t, rec.recipeBatches()[0], 3)
+
+	// Остаток сбрасывается при закрытии
+	assert.NoError(t, w.SaveRecipes(ctx, []entity.Recipe{{Name: "d"}}))
+	assert.NoError(t, w.SaveCategories(ctx, []entity.Category{{Name: "супы"}}))
+	assert.NoError(t, w.Close(ctx))
+	assert.Len(t, rec.recipeBatches(), 2)
+	assert.Len(t, rec.categories, 1)
+
+	assert.ErrorIs(t, w.SaveRecipes(ctx, []entity.Recipe{{Name: "e"}}), ErrWriterClosed)
+}
+
+// TestBatchWriterFlushByInterval проверяет сброс неполной пачки по таймеру
+func TestBatchWriterFlushByInterval(t *testing.T) {
+	rec := &recorder{}
+	w := newBatchWriter(rec.writeRecipes, rec.writeCategories, 100, 20*time.Millisecond, zap.NewNop())
+
+	assert.NoError(t, w.SaveRecipes(context.Background(), []entity.Recipe{{Name: "a"}}))
+	assert.Eventually(t, func() bool { return len(rec.recipeBatches()) == 1 }, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, w.Close(context.Background()))
+}
+
+// TestBatchWriterBackpressure проверяет, что при медленной записи SaveRecipes блокируется
+func TestBatchWriterBackpressure(t *testing.T) {
+	rec := &recorder{block: make(chan struct{})}
+	w := newBatchWriter(rec.writeRecipes, rec.writeCategories, 1, time.Hour, zap.NewNop())
+
+	// Очередь заполняется, пока запись первой пачки заблокирована
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	var err error
+	for i := 0; i < 100 && err == nil; i++ {
+		err = w.SaveRecipes(ctx, []entity.Recipe{{Name: "r"}})
+	}
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(rec.block)
+	assert.NoError(t, w.Close(context.Background()))
+}
+
+// TestBatchWriterFlushError проверяет, что ошибка записи пачки доходит до вызывающего
+func TestBatchWriterFlushError(t *testing.T) {
+	rec := &recorder{}
+	failing := func(ctx context.Context, recipes []entity.Recipe) error { return errors.New("copy failed") }
+	w := newBatchWriter(failing, rec.writeCategories, 2, time.Hour, zap.NewNop())
+
+	reported := make(chan string, 1)
+	w.OnError = func(table string, rows int, err error) { reported <- table }
+
+	ctx := context.Background()
+	assert.NoError(t, w.SaveCategories(ctx, []entity.Category{{Name: "супы"}}))
+	assert.NoError(t, w.SaveRecipes(ctx, []entity.Recipe{{Name: "a"}, {Name: "b"}}))
+
+	select {
+	case table := <-reported:
+		assert.Equal(t, "recipes", table)
+	case <-time.After(time.Second):
+		t.Fatal("flush error was not reported")
+	}
+	assert.Equal(t, int64(2), w.Failed())
+
+	// Категории записаны, но Close сообщает о потерянных рецептах
+	err := w.Close(ctx)
+	require.ErrorIs(t, err, ErrFlushFailed)
+	assert.Contains(t, err.Error(), "2 rows")
+	assert.Len(t, rec.categories, 1)
+}
@@ -2,10 +2,22 @@ package database
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"sort"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/anomaly"
+	"github.com/seniorcat/scraper/pkg/crawldiff"
+	"github.com/seniorcat/scraper/pkg/metrics"
+	"github.com/seniorcat/scraper/pkg/phash"
 )
 
 // DBServiceInterface определяет методы для работы с базой данных
@@ -14,11 +26,35 @@ type DBServiceInterface interface {
 	SaveRecipes(ctx context.Context, recipes []entity.Recipe) error
 }
 
+// DetailStore отбирает рецепты, страницы которых нужно загрузить
+type DetailStore interface {
+	StaleRecipes(ctx context.Context, recipes []entity.Recipe) ([]entity.Recipe, error)
+}
+
+// TombstoneStore помечает удаленными записи, страницы которых пропали с сайта
+type TombstoneStore interface {
+	MarkGone(ctx context.Context, canonicalURL string, reason string) error
+	MarkSeen(ctx context.Context, canonicalURLs []string) error
+}
+
+// ImageStore сохраняет сведения об изображениях рецептов, загруженных в хранилище
+type ImageStore interface {
+	SaveImage(ctx context.Context, image entity.RecipeImage) error
+	MissingImages(ctx context.Context, images []entity.RecipeImage) ([]entity.RecipeImage, error)
+}
+
+// CategoryStatsStore сохраняет историю обходов категорий для планировщика
+type CategoryStatsStore interface {
+	RecordCategoryCrawl(ctx context.Context, canonicalURL string, contentHash uint64) error
+}
+
 // DBService предоставляет доступ к методам работы с базой данных
 type DBService struct {
 	Pool             *pgxpool.Pool
 	CategorySaveChan chan []entity.Category // Канал для сохранения категорий
 	RecipeSaveChan   chan []entity.Recipe   // Канал для сохранения рецептов
+
+	RunID int64 // Запуск, к которому привязываются сохраняемые строки; задается до начала записи, 0 — без запуска
 }
 
 // NewDBService инициализирует соединение с базой данных PostgreSQL и запускает воркеры
@@ -41,6 +77,11 @@ func NewDBService(connString string) (*DBService, error) {
 	return dbService, nil
 }
 
+// Ping проверяет доступность базы данных
+func (db *DBService) Ping(ctx context.Context) error {
+	return db.Pool.Ping(ctx)
+}
+
 // saveCategoriesWorker - воркер для асинхронного сохранения категорий
 func (db *DBService) saveCategoriesWorker() {
 	for categories := range db.CategorySaveChan {
@@ -63,40 +104,366 @@ func (db *DBService) saveRecipesWorker() {
 
 // SaveCategories сохраняет список категорий в базу данных
 func (db *DBService) SaveCategories(ctx context.Context, categories []entity.Category) error {
+	return db.CopyCategories(ctx, categories)
+}
+
+// SaveRecipes сохраняет список рецептов в базу данных
+func (db *DBService) SaveRecipes(ctx context.Context, recipes []entity.Recipe) error {
+	return db.CopyRecipes(ctx, recipes)
+}
+
+// CopyRecipes сохраняет пачку рецептов: строки загружаются через COPY во временную
+// таблицу и переносятся в recipes одним запросом. Из повторов одного URL в пачке
+// сохраняется последний. Если содержимое рецепта изменилось, в recipe_versions
+// добавляется новая версия.
+func (db *DBService) CopyRecipes(ctx context.Context, recipes []entity.Recipe) (err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("copy_recipes", start, err) }(time.Now())
+
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	for _, category := range categories {
-		_, err = tx.Exec(ctx, "INSERT INTO categories (name, href) VALUES ($1, $2)", category.Name, category.Href)
-		if err != nil {
-			return err
-		}
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE recipes_staging (
+			seq BIGINT,
+			name TEXT,
+			href TEXT,
+			canonical_url TEXT,
+			fingerprint BIGINT,
+			ingredients TEXT[],
+			image_url TEXT,
+			details JSONB,
+			parsed_ingredients JSONB,
+			nutrition JSONB,
+			tags JSONB,
+			detailed BOOLEAN,
+			listing_hash TEXT
+		) ON COMMIT DROP`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"recipes_staging"},
+		[]string{"seq", "name", "href", "canonical_url", "fingerprint", "ingredients", "image_url", "details", "parsed_ingredients", "nutrition", "tags", "detailed", "listing_hash"},
+		pgx.CopyFromSlice(len(recipes), func(i int) ([]any, error) {
+			r := recipes[i]
+			details, err := marshalDetails(r.Details)
+			if err != nil {
+				return nil, err
+			}
+			parsed, err := marshalParsed(r.Parsed)
+			if err != nil {
+				return nil, err
+			}
+			nutrition, err := marshalNutrition(r.Nutrition)
+			if err != nil {
+				return nil, err
+			}
+			tags, err := marshalTags(r.Tags)
+			var listing *string
+			if !r.Detailed {
+				hash := listingHash(r)
+				listing = &hash
+			}
+			return []any{int64(i), r.Name, r.Href, r.CanonicalURL, int64(r.Fingerprint), r.Ingredients, r.ImageURL, details, parsed, nutrition, tags,
+				r.Detailed, listing}, err
+		}))
+	if err != nil {
+		return err
+	}
+
+	// Итоговое содержимое рецептов: страница списка не содержит ингредиентов,
+	// поэтому недостающие поля берутся из уже сохраненной записи. Название,
+	// изображение и отпечаток со страницы рецепта карточка списка не заменяет:
+	// иначе хеш содержимого менялся бы при каждой смене источника. Из нескольких
+	// строк пачки берется последняя со страницы рецепта, а хеш карточки — из
+	// последней карточки.
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE recipes_merged ON COMMIT DROP AS
+		SELECT
+			CASE WHEN s.detailed OR r.detailed_at IS NULL THEN s.name ELSE r.name END AS name,
+			s.href, NULLIF(s.canonical_url, '') AS canonical_url,
+			CASE WHEN s.detailed OR r.detailed_at IS NULL THEN s.fingerprint ELSE r.fingerprint END AS fingerprint,
+			COALESCE(s.ingredients, r.ingredients) AS ingredients,
+			CASE WHEN s.detailed OR r.detailed_at IS NULL THEN COALESCE(NULLIF(s.image_url, ''), r.image_url)
+				ELSE COALESCE(r.image_url, NULLIF(s.image_url, '')) END AS image_url,
+			COALESCE(s.details, r.details) AS details,
+			COALESCE(s.parsed_ingredients, r.parsed_ingredients) AS parsed_ingredients,
+			COALESCE(s.nutrition, r.nutrition) AS nutrition,
+			COALESCE(s.tags, r.tags) AS tags,
+			COALESCE((
+				SELECT l.listing_hash FROM recipes_staging l
+				WHERE l.canonical_url = s.canonical_url AND NOT l.detailed
+				ORDER BY l.seq DESC LIMIT 1
+			), r.listing_hash) AS listing_hash,
+			CASE WHEN s.detailed THEN now() ELSE r.detailed_at END AS detailed_at,
+			r.content_hash AS old_hash
+		FROM (
+			SELECT DISTINCT ON (COALESCE(NULLIF(canonical_url, ''), 'seq:' || seq)) *
+			FROM recipes_staging
+			ORDER BY COALESCE(NULLIF(canonical_url, ''), 'seq:' || seq), detailed DESC, seq DESC
+		) s
+		LEFT JOIN recipes r ON r.canonical_url = NULLIF(s.canonical_url, '')`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		ALTER TABLE recipes_merged ADD COLUMN content_hash TEXT;
+		UPDATE recipes_merged SET content_hash = md5(
+			name || E'\x1f' || COALESCE(array_to_string(ingredients, E'\x1e'), '') || E'\x1f' || COALESCE(image_url, ''))`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO recipe_versions (canonical_url, version, content_hash, name, ingredients, image_url, run_id)
+		SELECT m.canonical_url, COALESCE(v.last_version, 0) + 1, m.content_hash, m.name, m.ingredients, m.image_url, NULLIF($1::bigint, 0)
+		FROM recipes_merged m
+		LEFT JOIN (
+			SELECT canonical_url, max(version) AS last_version FROM recipe_versions GROUP BY canonical_url
+		) v ON v.canonical_url = m.canonical_url
+		WHERE m.canonical_url IS NOT NULL AND m.old_hash IS DISTINCT FROM m.content_hash`, db.RunID)
+	if err != nil {
+		return err
+	}
+
+	// Состав запуска для сравнения запусков между собой
+	_, err = tx.Exec(ctx, `
+		INSERT INTO crawl_run_items (run_id, kind, canonical_url, name, content_hash)
+		SELECT $1, 'recipe', canonical_url, name, content_hash FROM recipes_merged
+		WHERE $1 > 0 AND canonical_url IS NOT NULL
+		ON CONFLICT (run_id, kind, canonical_url) DO UPDATE SET
+			name = EXCLUDED.name,
+			content_hash = EXCLUDED.content_hash`, db.RunID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO recipes (name, href, canonical_url, fingerprint, ingredients, image_url, details, parsed_ingredients, nutrition, tags,
+			listing_hash, detailed_at, content_hash, last_seen_at, first_run_id, last_run_id)
+		SELECT name, href, canonical_url, fingerprint, ingredients, image_url, details, parsed_ingredients, nutrition, tags,
+			listing_hash, detailed_at, content_hash, now(), NULLIF($1::bigint, 0), NULLIF($1::bigint, 0)
+		FROM recipes_merged
+		ON CONFLICT (canonical_url) DO UPDATE SET
+			name = EXCLUDED.name,
+			href = EXCLUDED.href,
+			fingerprint = EXCLUDED.fingerprint,
+			ingredients = EXCLUDED.ingredients,
+			image_url = EXCLUDED.image_url,
+			details = EXCLUDED.details,
+			parsed_ingredients = EXCLUDED.parsed_ingredients,
+			nutrition = EXCLUDED.nutrition,
+			tags = EXCLUDED.tags,
+			listing_hash = EXCLUDED.listing_hash,
+			detailed_at = EXCLUDED.detailed_at,
+			content_hash = EXCLUDED.content_hash,
+			last_seen_at = EXCLUDED.last_seen_at,
+			last_run_id = COALESCE(EXCLUDED.last_run_id, recipes.last_run_id),
+			missed_runs = 0,
+			deleted_at = NULL,
+			deleted_reason = NULL`, db.RunID)
+	if err != nil {
+		return err
+	}
+
+	// Привязка рецептов к каноническим ингредиентам по разобранным строкам
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE recipes_linked ON COMMIT DROP AS
+		SELECT r.id AS recipe_id, e.position::int AS position, e.value->>'canonical' AS canonical
+		FROM recipes_merged m
+		JOIN recipes r ON r.canonical_url = m.canonical_url
+		CROSS JOIN LATERAL jsonb_array_elements(m.parsed_ingredients) WITH ORDINALITY AS e(value, position)
+		WHERE m.parsed_ingredients IS NOT NULL;
+
+		INSERT INTO ingredients (name)
+		SELECT DISTINCT canonical FROM recipes_linked WHERE canonical <> ''
+		ON CONFLICT (name) DO NOTHING;
+
+		DELETE FROM recipe_ingredients WHERE recipe_id IN (SELECT recipe_id FROM recipes_linked);
+
+		INSERT INTO recipe_ingredients (recipe_id, position, ingredient_id)
+		SELECT l.recipe_id, l.position, i.id
+		FROM recipes_linked l
+		JOIN ingredients i ON i.name = l.canonical`)
+	if err != nil {
+		return err
+	}
+
+	// Метки диет и аллергенов для запросов по метке
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE recipes_tagged ON COMMIT DROP AS
+		SELECT r.id AS recipe_id, m.tags
+		FROM recipes_merged m
+		JOIN recipes r ON r.canonical_url = m.canonical_url
+		WHERE m.tags IS NOT NULL;
+
+		DELETE FROM recipe_tags WHERE recipe_id IN (SELECT recipe_id FROM recipes_tagged);
+
+		INSERT INTO recipe_tags (recipe_id, tag, ingredients, reason)
+		SELECT t.recipe_id, e.tag, COALESCE(e.ingredients, '{}'), e.reason
+		FROM recipes_tagged t
+		CROSS JOIN LATERAL jsonb_to_recordset(t.tags) AS e(tag TEXT, ingredients TEXT[], reason TEXT)`)
+	if err != nil {
+		return err
 	}
 
 	return tx.Commit(ctx)
 }
 
-// SaveRecipes сохраняет список рецептов в базу данных
-func (db *DBService) SaveRecipes(ctx context.Context, recipes []entity.Recipe) error {
+// marshalDetails кодирует структурированные данные рецепта для столбца JSONB
+func marshalDetails(details *entity.RecipeDetails) ([]byte, error) {
+	if details == nil {
+		return nil, nil
+	}
+	return json.Marshal(details)
+}
+
+// marshalParsed кодирует разобранные ингредиенты для столбца JSONB
+func marshalParsed(parsed []entity.Ingredient) ([]byte, error) {
+	if len(parsed) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(parsed)
+}
+
+// marshalNutrition кодирует пищевую ценность для столбца JSONB
+func marshalNutrition(nutrition *entity.NutritionInfo) ([]byte, error) {
+	if nutrition == nil {
+		return nil, nil
+	}
+	return json.Marshal(nutrition)
+}
+
+// marshalTags кодирует метки рецепта для столбца JSONB; пустой список сохраняется,
+// чтобы отличать рецепт без меток от неклассифицированного
+func marshalTags(tags []entity.RecipeTag) ([]byte, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	return json.Marshal(tags)
+}
+
+// CopyCategories сохраняет пачку категорий через COPY во временную таблицу
+func (db *DBService) CopyCategories(ctx context.Context, categories []entity.Category) (err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("copy_categories", start, err) }(time.Now())
+
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	for _, recipe := range recipes {
-		_, err = tx.Exec(ctx, "INSERT INTO recipes (name, href) VALUES ($1, $2)", recipe.Name, recipe.Href)
-		if err != nil {
-			return err
-		}
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE categories_staging (
+			seq BIGINT,
+			name TEXT,
+			href TEXT,
+			canonical_url TEXT
+		) ON COMMIT DROP`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"categories_staging"},
+		[]string{"seq", "name", "href", "canonical_url"},
+		pgx.CopyFromSlice(len(categories), func(i int) ([]any, error) {
+			c := categories[i]
+			return []any{int64(i), c.Name, c.Href, c.CanonicalURL}, nil
+		}))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO categories (name, href, canonical_url, last_seen_at, first_run_id, last_run_id)
+		SELECT name, href, NULLIF(canonical_url, ''), now(), NULLIF($1::bigint, 0), NULLIF($1::bigint, 0) FROM (
+			SELECT DISTINCT ON (COALESCE(NULLIF(canonical_url, ''), 'seq:' || seq)) *
+			FROM categories_staging
+			ORDER BY COALESCE(NULLIF(canonical_url, ''), 'seq:' || seq), seq DESC
+		) latest
+		ON CONFLICT (canonical_url) DO UPDATE SET
+			name = EXCLUDED.name,
+			href = EXCLUDED.href,
+			last_seen_at = EXCLUDED.last_seen_at,
+			last_run_id = COALESCE(EXCLUDED.last_run_id, categories.last_run_id),
+			missed_runs = 0,
+			deleted_at = NULL,
+			deleted_reason = NULL`, db.RunID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO crawl_run_items (run_id, kind, canonical_url, name, content_hash)
+		SELECT DISTINCT ON (canonical_url) $1, 'category', canonical_url, name, md5(name)
+		FROM categories_staging
+		WHERE $1 > 0 AND canonical_url <> ''
+		ORDER BY canonical_url, seq DESC
+		ON CONFLICT (run_id, kind, canonical_url) DO UPDATE SET
+			name = EXCLUDED.name,
+			content_hash = EXCLUDED.content_hash`, db.RunID)
+	if err != nil {
+		return err
 	}
 
 	return tx.Commit(ctx)
 }
 
+// listingHash возвращает хеш карточки рецепта в списке категории: изменившаяся
+// карточка означает, что страницу рецепта нужно загрузить заново
+func listingHash(r entity.Recipe) string {
+	sum := md5.Sum([]byte(r.Name + "\x1f" + r.ImageURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// StaleRecipes возвращает рецепты из списка категории, страницы которых нужно
+// загрузить: новые, еще ни разу не загруженные и с изменившейся карточкой
+func (db *DBService) StaleRecipes(ctx context.Context, recipes []entity.Recipe) ([]entity.Recipe, error) {
+	if len(recipes) == 0 {
+		return nil, nil
+	}
+
+	urls := make([]string, len(recipes))
+	hashes := make([]string, len(recipes))
+	for i, r := range recipes {
+		urls[i], hashes[i] = r.CanonicalURL, listingHash(r)
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT u.canonical_url
+		FROM unnest($1::text[], $2::text[]) AS u(canonical_url, listing_hash)
+		JOIN recipes r ON r.canonical_url = u.canonical_url
+		WHERE r.detailed_at IS NOT NULL AND r.listing_hash = u.listing_hash`, urls, hashes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fresh := make(map[string]bool)
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		fresh[url] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var stale []entity.Recipe
+	for _, r := range recipes {
+		if !fresh[r.CanonicalURL] {
+			stale = append(stale, r)
+		}
+	}
+	return stale, nil
+}
+
 // CreateTables создаёт таблицы в базе данных
 func (db *DBService) CreateTables(ctx context.Context) error {
 	_, err := db.Pool.Exec(ctx, `
@@ -111,6 +478,820 @@ func (db *DBService) CreateTables(ctx context.Context) error {
 			name TEXT NOT NULL,
 			href TEXT NOT NULL
 		);
+
+		ALTER TABLE categories ADD COLUMN IF NOT EXISTS canonical_url TEXT;
+		CREATE UNIQUE INDEX IF NOT EXISTS categories_canonical_url_key ON categories (canonical_url);
+
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS canonical_url TEXT;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS fingerprint BIGINT;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS ingredients TEXT[];
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS listing_hash TEXT;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS detailed_at TIMESTAMPTZ;
+		CREATE UNIQUE INDEX IF NOT EXISTS recipes_canonical_url_key ON recipes (canonical_url);
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS image_url TEXT;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS content_hash TEXT;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS details JSONB;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS parsed_ingredients JSONB;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS nutrition JSONB;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS tags JSONB;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS prep_time_seconds INTEGER
+			GENERATED ALWAYS AS (NULLIF(details->>'prep_seconds', '')::integer) STORED;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS cook_time_seconds INTEGER
+			GENERATED ALWAYS AS (NULLIF(details->>'cook_seconds', '')::integer) STORED;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS total_time_seconds INTEGER
+			GENERATED ALWAYS AS (NULLIF(details->>'total_seconds', '')::integer) STORED;
+		CREATE INDEX IF NOT EXISTS recipes_total_time_seconds_idx ON recipes (total_time_seconds);
+
+		ALTER TABLE categories ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
+		ALTER TABLE categories ADD COLUMN IF NOT EXISTS missed_runs INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+		ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_reason TEXT;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS missed_runs INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS deleted_reason TEXT;
+
+		CREATE TABLE IF NOT EXISTS crawl_runs (
+			id BIGSERIAL PRIMARY KEY,
+			started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			finished_at TIMESTAMPTZ,
+			config JSONB,
+			version TEXT,
+			categories INTEGER,
+			recipes INTEGER,
+			new_recipes INTEGER,
+			updated_recipes INTEGER,
+			failed_tasks INTEGER,
+			duration_ms BIGINT,
+			exit_reason TEXT
+		);
+		ALTER TABLE crawl_runs ADD COLUMN IF NOT EXISTS yield_stats JSONB;
+		ALTER TABLE crawl_runs ADD COLUMN IF NOT EXISTS anomalies JSONB;
+
+		CREATE TABLE IF NOT EXISTS crawl_run_items (
+			run_id BIGINT NOT NULL REFERENCES crawl_runs (id) ON DELETE CASCADE,
+			kind TEXT NOT NULL,
+			canonical_url TEXT NOT NULL,
+			name TEXT NOT NULL,
+			content_hash TEXT NOT NULL,
+			PRIMARY KEY (run_id, kind, canonical_url)
+		);
+
+		ALTER TABLE categories ADD COLUMN IF NOT EXISTS first_run_id BIGINT REFERENCES crawl_runs (id);
+		ALTER TABLE categories ADD COLUMN IF NOT EXISTS last_run_id BIGINT REFERENCES crawl_runs (id);
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS first_run_id BIGINT REFERENCES crawl_runs (id);
+		ALTER TABLE recipes ADD COLUMN IF NOT EXISTS last_run_id BIGINT REFERENCES crawl_runs (id);
+
+		CREATE TABLE IF NOT EXISTS recipe_versions (
+			id SERIAL PRIMARY KEY,
+			canonical_url TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			content_hash TEXT NOT NULL,
+			name TEXT NOT NULL,
+			ingredients TEXT[],
+			image_url TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			UNIQUE (canonical_url, version)
+		);
+		ALTER TABLE recipe_versions ADD COLUMN IF NOT EXISTS run_id BIGINT REFERENCES crawl_runs (id);
+
+		CREATE TABLE IF NOT EXISTS ingredients (
+			id SERIAL PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE
+		);
+
+		CREATE TABLE IF NOT EXISTS recipe_ingredients (
+			recipe_id INTEGER NOT NULL REFERENCES recipes (id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			ingredient_id INTEGER NOT NULL REFERENCES ingredients (id),
+			PRIMARY KEY (recipe_id, position)
+		);
+		CREATE INDEX IF NOT EXISTS recipe_ingredients_ingredient_id_idx ON recipe_ingredients (ingredient_id);
+
+		CREATE TABLE IF NOT EXISTS recipe_tags (
+			recipe_id INTEGER NOT NULL REFERENCES recipes (id) ON DELETE CASCADE,
+			tag TEXT NOT NULL,
+			ingredients TEXT[] NOT NULL DEFAULT '{}',
+			reason TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (recipe_id, tag)
+		);
+		CREATE INDEX IF NOT EXISTS recipe_tags_tag_idx ON recipe_tags (tag);
+
+		CREATE TABLE IF NOT EXISTS recipe_images (
+			recipe_url TEXT NOT NULL,
+			source_url TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			content_hash TEXT NOT NULL,
+			mime_type TEXT NOT NULL,
+			width INTEGER NOT NULL,
+			height INTEGER NOT NULL,
+			size INTEGER NOT NULL,
+			blob_key TEXT NOT NULL,
+			thumbnails JSONB,
+			downloaded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (recipe_url, source_url)
+		);
+		CREATE INDEX IF NOT EXISTS recipe_images_content_hash_idx ON recipe_images (content_hash);
+		ALTER TABLE recipe_images ADD COLUMN IF NOT EXISTS ahash BIGINT;
+		ALTER TABLE recipe_images ADD COLUMN IF NOT EXISTS dhash BIGINT;
+		ALTER TABLE recipe_images ADD COLUMN IF NOT EXISTS phash BIGINT;
+		DROP INDEX IF EXISTS recipe_images_phash_idx;
+
+		CREATE TABLE IF NOT EXISTS category_stats (
+			canonical_url TEXT PRIMARY KEY,
+			last_crawled_at TIMESTAMPTZ NOT NULL,
+			crawls INTEGER NOT NULL DEFAULT 0,
+			changes INTEGER NOT NULL DEFAULT 0,
+			last_hash BIGINT
+		);
 	`)
 	return err
 }
+
+// LoadRecipeFingerprints возвращает отпечатки содержимого сохраненных рецептов по каноническому URL
+func (db *DBService) LoadRecipeFingerprints(ctx context.Context) (map[string]uint64, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT canonical_url, fingerprint FROM recipes
+		WHERE canonical_url IS NOT NULL AND fingerprint IS NOT NULL AND cardinality(ingredients) > 0`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fingerprints := make(map[string]uint64)
+	for rows.Next() {
+		var (
+			url         string
+			fingerprint int64
+		)
+		if err := rows.Scan(&url, &fingerprint); err != nil {
+			return nil, err
+		}
+		fingerprints[url] = uint64(fingerprint)
+	}
+
+	return fingerprints, rows.Err()
+}
+
+// LoadCategoryStats возвращает историю обходов категорий по каноническому URL
+func (db *DBService) LoadCategoryStats(ctx context.Context) (map[string]entity.CategoryStats, error) {
+	rows, err := db.Pool.Query(ctx, "SELECT canonical_url, last_crawled_at, crawls, changes FROM category_stats")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[string]entity.CategoryStats)
+	for rows.Next() {
+		var s entity.CategoryStats
+		if err := rows.Scan(&s.CanonicalURL, &s.LastCrawledAt, &s.Crawls, &s.Changes); err != nil {
+			return nil, err
+		}
+		stats[s.CanonicalURL] = s
+	}
+
+	return stats, rows.Err()
+}
+
+// RecordCategoryCrawl отмечает успешный обход категории; contentHash описывает состав
+// ее рецептов, и его смена по сравнению с прошлым обходом считается изменением
+func (db *DBService) RecordCategoryCrawl(ctx context.Context, canonicalURL string, contentHash uint64) (err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("record_category_crawl", start, err) }(time.Now())
+
+	_, err = db.Pool.Exec(ctx, `
+		INSERT INTO category_stats (canonical_url, last_crawled_at, crawls, changes, last_hash)
+		VALUES ($1, now(), 1, 0, $2)
+		ON CONFLICT (canonical_url) DO UPDATE SET
+			last_crawled_at = now(),
+			crawls = category_stats.crawls + 1,
+			changes = category_stats.changes + CASE WHEN category_stats.last_hash IS DISTINCT FROM EXCLUDED.last_hash THEN 1 ELSE 0 END,
+			last_hash = EXCLUDED.last_hash`,
+		canonicalURL, int64(contentHash))
+	return err
+}
+
+// ErrRecipeNotFound возвращается, если рецепта с таким каноническим URL нет
+var ErrRecipeNotFound = errors.New("recipe not found")
+
+// Recipe возвращает сохраненный рецепт с разобранными ингредиентами и структурированными данными
+func (db *DBService) Recipe(ctx context.Context, canonicalURL string) (entity.Recipe, error) {
+	var (
+		r                                entity.Recipe
+		details, parsed, nutrition, tags []byte
+	)
+	err := db.Pool.QueryRow(ctx, `
+		SELECT name, href, canonical_url, COALESCE(ingredients, '{}'), COALESCE(image_url, ''), details, parsed_ingredients, nutrition, tags
+		FROM recipes WHERE canonical_url = $1`, canonicalURL).
+		Scan(&r.Name, &r.Href, &r.CanonicalURL, &r.Ingredients, &r.ImageURL, &details, &parsed, &nutrition, &tags)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.Recipe{}, ErrRecipeNotFound
+	}
+	if err != nil {
+		return entity.Recipe{}, err
+	}
+
+	return r, unmarshalRecipeJSON(&r, details, parsed, nutrition, tags)
+}
+
+// unmarshalRecipeJSON раскодирует столбцы JSONB рецепта
+func unmarshalRecipeJSON(r *entity.Recipe, details, parsed, nutrition, tags []byte) error {
+	if details != nil {
+		r.Details = &entity.RecipeDetails{}
+		if err := json.Unmarshal(details, r.Details); err != nil {
+			return err
+		}
+	}
+	if parsed != nil {
+		if err := json.Unmarshal(parsed, &r.Parsed); err != nil {
+			return err
+		}
+	}
+	if nutrition != nil {
+		r.Nutrition = &entity.NutritionInfo{}
+		if err := json.Unmarshal(nutrition, r.Nutrition); err != nil {
+			return err
+		}
+	}
+	if tags != nil {
+		if err := json.Unmarshal(tags, &r.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncIngredients добавляет канонические ингредиенты каталога в таблицу ingredients
+func (db *DBService) SyncIngredients(ctx context.Context, names []string) (err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("sync_ingredients", start, err) }(time.Now())
+
+	_, err = db.Pool.Exec(ctx, `
+		INSERT INTO ingredients (name) SELECT unnest($1::text[])
+		ON CONFLICT (name) DO NOTHING`, names)
+	return err
+}
+
+// RecipesWithIngredient возвращает неудаленные рецепты, содержащие канонический ингредиент
+func (db *DBService) RecipesWithIngredient(ctx context.Context, name string) ([]entity.Recipe, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT r.name, r.href, COALESCE(r.canonical_url, '')
+		FROM recipes r
+		WHERE r.deleted_at IS NULL AND EXISTS (
+			SELECT 1 FROM recipe_ingredients ri
+			JOIN ingredients i ON i.id = ri.ingredient_id
+			WHERE ri.recipe_id = r.id AND i.name = $1)
+		ORDER BY r.name`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []entity.Recipe
+	for rows.Next() {
+		var r entity.Recipe
+		if err := rows.Scan(&r.Name, &r.Href, &r.CanonicalURL); err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, r)
+	}
+
+	return recipes, rows.Err()
+}
+
+// RecipesWithinTime возвращает неудаленные рецепты, общее время приготовления
+// которых известно и не превышает maxTime, от быстрых к долгим
+func (db *DBService) RecipesWithinTime(ctx context.Context, maxTime time.Duration) ([]entity.Recipe, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT name, href, COALESCE(canonical_url, ''), details
+		FROM recipes
+		WHERE deleted_at IS NULL AND total_time_seconds > 0 AND total_time_seconds <= $1
+		ORDER BY total_time_seconds, name`, int(maxTime/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []entity.Recipe
+	for rows.Next() {
+		var (
+			r       entity.Recipe
+			details []byte
+		)
+		if err := rows.Scan(&r.Name, &r.Href, &r.CanonicalURL, &details); err != nil {
+			return nil, err
+		}
+		if err := unmarshalRecipeJSON(&r, details, nil, nil, nil); err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, r)
+	}
+
+	return recipes, rows.Err()
+}
+
+// RecipesWithTags возвращает неудаленные рецепты, у которых есть все метки, вместе
+// с объяснениями этих меток
+func (db *DBService) RecipesWithTags(ctx context.Context, tags []string) ([]entity.Recipe, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT r.name, r.href, COALESCE(r.canonical_url, ''),
+			jsonb_agg(jsonb_build_object('tag', t.tag, 'ingredients', t.ingredients, 'reason', t.reason) ORDER BY t.tag)
+		FROM recipes r
+		JOIN recipe_tags t ON t.recipe_id = r.id AND t.tag = ANY($1)
+		WHERE r.deleted_at IS NULL
+		GROUP BY r.id
+		HAVING count(*) = cardinality($1)
+		ORDER BY r.name`, tags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []entity.Recipe
+	for rows.Next() {
+		var (
+			r    entity.Recipe
+			tags []byte
+		)
+		if err := rows.Scan(&r.Name, &r.Href, &r.CanonicalURL, &tags); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(tags, &r.Tags); err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, r)
+	}
+
+	return recipes, rows.Err()
+}
+
+// RecipeVersions возвращает историю версий рецепта по возрастанию номера
+func (db *DBService) RecipeVersions(ctx context.Context, canonicalURL string) ([]entity.RecipeVersion, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT canonical_url, version, content_hash, name, COALESCE(ingredients, '{}'), COALESCE(image_url, ''), created_at
+		FROM recipe_versions WHERE canonical_url = $1 ORDER BY version`, canonicalURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []entity.RecipeVersion
+	for rows.Next() {
+		var v entity.RecipeVersion
+		if err := rows.Scan(&v.CanonicalURL, &v.Version, &v.ContentHash, &v.Name, &v.Ingredients, &v.ImageURL, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// MarkGone помечает удаленными рецепт или категорию, страница которых ответила 404/410
+func (db *DBService) MarkGone(ctx context.Context, canonicalURL string, reason string) (err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("mark_gone", start, err) }(time.Now())
+
+	_, err = db.Pool.Exec(ctx, `
+		UPDATE recipes SET deleted_at = now(), deleted_reason = $2
+		WHERE canonical_url = $1 AND deleted_at IS NULL`, canonicalURL, reason)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Pool.Exec(ctx, `
+		UPDATE categories SET deleted_at = now(), deleted_reason = $2
+		WHERE canonical_url = $1 AND deleted_at IS NULL`, canonicalURL, reason)
+	return err
+}
+
+// MarkSeen отмечает встреченными в текущем обходе сохраненные рецепты, карточки
+// которых есть в списке категории, но сами рецепты не сохранялись
+func (db *DBService) MarkSeen(ctx context.Context, canonicalURLs []string) (err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("mark_seen", start, err) }(time.Now())
+
+	_, err = db.Pool.Exec(ctx, `
+		UPDATE recipes SET last_seen_at = now(), missed_runs = 0, deleted_at = NULL, deleted_reason = NULL
+		WHERE canonical_url = ANY($1)
+			AND (deleted_at IS NULL OR deleted_reason = $2)`, canonicalURLs, entity.DeletedNotListed)
+	return err
+}
+
+// SaveImage сохраняет сведения о загруженном изображении рецепта
+func (db *DBService) SaveImage(ctx context.Context, image entity.RecipeImage) (err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("save_image", start, err) }(time.Now())
+
+	thumbnails, err := json.Marshal(image.Thumbnails)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Pool.Exec(ctx, `
+		INSERT INTO recipe_images (recipe_url, source_url, kind, position, content_hash, mime_type, width, height, size, blob_key, thumbnails,
+			ahash, dhash, phash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (recipe_url, source_url) DO UPDATE SET
+			kind = EXCLUDED.kind,
+			position = EXCLUDED.position,
+			content_hash = EXCLUDED.content_hash,
+			mime_type = EXCLUDED.mime_type,
+			width = EXCLUDED.width,
+			height = EXCLUDED.height,
+			size = EXCLUDED.size,
+			blob_key = EXCLUDED.blob_key,
+			thumbnails = EXCLUDED.thumbnails,
+			ahash = EXCLUDED.ahash,
+			dhash = EXCLUDED.dhash,
+			phash = EXCLUDED.phash,
+			downloaded_at = now()`,
+		image.RecipeURL, image.SourceURL, image.Kind, image.Position, image.ContentHash, image.MimeType,
+		image.Width, image.Height, image.Size, image.Key, thumbnails,
+		int64(image.AHash), int64(image.DHash), int64(image.PHash))
+	return err
+}
+
+// MissingImages возвращает изображения, которых еще нет в recipe_images, и сохраненные
+// до появления перцептивных хешей; сравниваются URL рецепта и адрес изображения
+func (db *DBService) MissingImages(ctx context.Context, images []entity.RecipeImage) ([]entity.RecipeImage, error) {
+	if len(images) == 0 {
+		return nil, nil
+	}
+
+	recipeURLs := make([]string, len(images))
+	sourceURLs := make([]string, len(images))
+	for i, image := range images {
+		recipeURLs[i], sourceURLs[i] = image.RecipeURL, image.SourceURL
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT recipe_url, source_url FROM recipe_images
+		WHERE (recipe_url, source_url) IN (SELECT * FROM unnest($1::text[], $2::text[])) AND phash IS NOT NULL`, recipeURLs, sourceURLs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	known := make(map[[2]string]bool)
+	for rows.Next() {
+		var key [2]string
+		if err := rows.Scan(&key[0], &key[1]); err != nil {
+			return nil, err
+		}
+		known[key] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var missing []entity.RecipeImage
+	for _, image := range images {
+		if !known[[2]string{image.RecipeURL, image.SourceURL}] {
+			missing = append(missing, image)
+		}
+	}
+	return missing, nil
+}
+
+// RecipeImages возвращает изображения рецепта из хранилища: основные, затем шаги по порядку
+func (db *DBService) RecipeImages(ctx context.Context, recipeURL string) ([]entity.RecipeImage, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+recipeImageColumns+`
+		FROM recipe_images WHERE recipe_url = $1
+		ORDER BY kind = 'step', position`, recipeURL)
+	if err != nil {
+		return nil, err
+	}
+	return scanRecipeImages(rows)
+}
+
+// ImageClusters возвращает группы почти одинаковых изображений, встречающихся в
+// разных рецептах: хеш kind (phash.KindPerceptual и другие) каждого изображения
+// группы отстоит от представителя группы не больше чем на maxDistance. Группы
+// упорядочены по убыванию числа рецептов.
+//
+// Индекса по расстоянию Хэмминга нет: запрос читает все изображения с хешами,
+// а группировка идет в памяти, поэтому время и память растут с числом изображений.
+func (db *DBService) ImageClusters(ctx context.Context, kind string, maxDistance int) ([]entity.ImageCluster, error) {
+	if _, ok := (phash.Hashes{}).Get(kind); !ok {
+		return nil, fmt.Errorf("unknown image hash %q", kind)
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+recipeImageColumns+`
+		FROM recipe_images WHERE phash IS NOT NULL
+		ORDER BY recipe_url, kind = 'step', position`)
+	if err != nil {
+		return nil, err
+	}
+	images, err := scanRecipeImages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]uint64, len(images))
+	for i, image := range images {
+		hashes[i], _ = phash.Hashes{Average: image.AHash, Difference: image.DHash, Perceptual: image.PHash}.Get(kind)
+	}
+
+	var clusters []entity.ImageCluster
+	for _, group := range phash.Cluster(hashes, maxDistance) {
+		cluster := entity.ImageCluster{Images: make([]entity.RecipeImage, len(group))}
+		for i, index := range group {
+			cluster.Images[i] = images[index]
+		}
+		// Повторы изображения внутри одного рецепта не интересны
+		if len(cluster.Recipes()) > 1 {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].Recipes()) > len(clusters[j].Recipes())
+	})
+	return clusters, nil
+}
+
+// recipeImageColumns — столбцы recipe_images в порядке scanRecipeImages
+const recipeImageColumns = `recipe_url, source_url, kind, position, content_hash, mime_type, width, height, size, blob_key, thumbnails,
+			COALESCE(ahash, 0), COALESCE(dhash, 0), COALESCE(phash, 0)`
+
+// scanRecipeImages читает строки recipe_images и закрывает их
+func scanRecipeImages(rows pgx.Rows) ([]entity.RecipeImage, error) {
+	defer rows.Close()
+
+	var images []entity.RecipeImage
+	for rows.Next() {
+		var (
+			image      entity.RecipeImage
+			thumbnails []byte
+			a, d, p    int64 // BIGINT хранит хеши со знаком
+		)
+		if err := rows.Scan(&image.RecipeURL, &image.SourceURL, &image.Kind, &image.Position, &image.ContentHash,
+			&image.MimeType, &image.Width, &image.Height, &image.Size, &image.Key, &thumbnails,
+			&a, &d, &p); err != nil {
+			return nil, err
+		}
+		image.AHash, image.DHash, image.PHash = uint64(a), uint64(d), uint64(p)
+		if thumbnails != nil {
+			if err := json.Unmarshal(thumbnails, &image.Thumbnails); err != nil {
+				return nil, err
+			}
+		}
+		images = append(images, image)
+	}
+
+	return images, rows.Err()
+}
+
+// SweepMissing вызывается после полного обхода, начатого в runStartedAt: увеличивает
+// счетчик пропущенных обходов у записей таблицы recipes или categories, не встреченных
+// в нем, и помечает удаленными те, что не встречались maxMissed обходов подряд.
+// Возвращает количество помеченных записей.
+func (db *DBService) SweepMissing(ctx context.Context, table string, runStartedAt time.Time, maxMissed int) (deleted int64, err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("sweep_missing", start, err) }(time.Now())
+
+	if table != "recipes" && table != "categories" {
+		return 0, fmt.Errorf("sweep: unsupported table %q", table)
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE `+table+` SET missed_runs = missed_runs + 1
+		WHERE deleted_at IS NULL AND (last_seen_at IS NULL OR last_seen_at < $1)`, runStartedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE `+table+` SET deleted_at = now(), deleted_reason = $2
+		WHERE deleted_at IS NULL AND missed_runs >= $1`, maxMissed, entity.DeletedNotListed)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), tx.Commit(ctx)
+}
+
+// ExportRecipes возвращает сохраненные рецепты; удаленные включаются только по запросу
+func (db *DBService) ExportRecipes(ctx context.Context, includeDeleted bool) ([]entity.Recipe, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT name, href, COALESCE(canonical_url, ''), COALESCE(ingredients, '{}'), COALESCE(image_url, ''), details, parsed_ingredients, nutrition, tags,
+			COALESCE(last_seen_at, 'epoch'), COALESCE(deleted_at, 'epoch'), COALESCE(deleted_reason, '')
+		FROM recipes
+		WHERE $1 OR deleted_at IS NULL
+		ORDER BY id`, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []entity.Recipe
+	for rows.Next() {
+		var (
+			r                                entity.Recipe
+			details, parsed, nutrition, tags []byte
+		)
+		if err := rows.Scan(&r.Name, &r.Href, &r.CanonicalURL, &r.Ingredients, &r.ImageURL, &details, &parsed, &nutrition, &tags, &r.LastSeenAt, &r.DeletedAt, &r.DeletedReason); err != nil {
+			return nil, err
+		}
+		if err := unmarshalRecipeJSON(&r, details, parsed, nutrition, tags); err != nil {
+			return nil, err
+		}
+		r.LastSeenAt, r.DeletedAt = zeroEpoch(r.LastSeenAt), zeroEpoch(r.DeletedAt)
+		recipes = append(recipes, r)
+	}
+
+	return recipes, rows.Err()
+}
+
+// ExportCategories возвращает сохраненные категории; удаленные включаются только по запросу
+func (db *DBService) ExportCategories(ctx context.Context, includeDeleted bool) ([]entity.Category, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT name, href, COALESCE(canonical_url, ''),
+			COALESCE(last_seen_at, 'epoch'), COALESCE(deleted_at, 'epoch'), COALESCE(deleted_reason, '')
+		FROM categories
+		WHERE $1 OR deleted_at IS NULL
+		ORDER BY id`, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []entity.Category
+	for rows.Next() {
+		var c entity.Category
+		if err := rows.Scan(&c.Name, &c.Href, &c.CanonicalURL, &c.LastSeenAt, &c.DeletedAt, &c.DeletedReason); err != nil {
+			return nil, err
+		}
+		c.LastSeenAt, c.DeletedAt = zeroEpoch(c.LastSeenAt), zeroEpoch(c.DeletedAt)
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+// zeroEpoch заменяет подставленное вместо NULL начало эпохи нулевым временем
+func zeroEpoch(t time.Time) time.Time {
+	if t.Unix() == 0 {
+		return time.Time{}
+	}
+	return t
+}
+
+// StartRun регистрирует начало запуска парсера и возвращает его идентификатор
+func (db *DBService) StartRun(ctx context.Context, config []byte, version string) (id int64, err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("start_run", start, err) }(time.Now())
+
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO crawl_runs (config, version) VALUES ($1, $2) RETURNING id`,
+		config, version).Scan(&id)
+	return id, err
+}
+
+// FinishRun фиксирует завершение запуска; количество категорий и рецептов
+// подсчитывается по строкам, привязанным к запуску
+func (db *DBService) FinishRun(ctx context.Context, id int64, failedTasks int, exitReason string) (run entity.CrawlRun, err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("finish_run", start, err) }(time.Now())
+
+	row := db.Pool.QueryRow(ctx, `
+		UPDATE crawl_runs SET
+			finished_at = now(),
+			duration_ms = (extract(epoch FROM now() - started_at) * 1000)::bigint,
+			failed_tasks = $2,
+			exit_reason = $3,
+			categories = (SELECT count(*) FROM categories WHERE last_run_id = $1),
+			recipes = (SELECT count(*) FROM recipes WHERE last_run_id = $1),
+			new_recipes = (SELECT count(*) FROM recipes WHERE first_run_id = $1),
+			updated_recipes = (SELECT count(*) FROM recipe_versions WHERE run_id = $1 AND version > 1)
+		WHERE id = $1
+		RETURNING `+crawlRunColumns, id, failedTasks, exitReason)
+	return scanCrawlRun(row)
+}
+
+// CrawlRuns возвращает последние limit запусков, начиная с самого нового
+func (db *DBService) CrawlRuns(ctx context.Context, limit int) ([]entity.CrawlRun, error) {
+	rows, err := db.Pool.Query(ctx, `SELECT `+crawlRunColumns+` FROM crawl_runs ORDER BY id DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []entity.CrawlRun
+	for rows.Next() {
+		run, err := scanCrawlRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// crawlRunColumns — столбцы crawl_runs в порядке, ожидаемом scanCrawlRun
+const crawlRunColumns = `id, started_at, COALESCE(finished_at, 'epoch'), COALESCE(config::text, ''), COALESCE(version, ''),
+	COALESCE(categories, 0), COALESCE(recipes, 0), COALESCE(new_recipes, 0), COALESCE(updated_recipes, 0),
+	COALESCE(failed_tasks, 0), COALESCE(duration_ms, 0), COALESCE(exit_reason, '')`
+
+// scanCrawlRun считывает запуск из строки результата
+func scanCrawlRun(row pgx.Row) (entity.CrawlRun, error) {
+	var (
+		run        entity.CrawlRun
+		durationMs int64
+	)
+	err := row.Scan(&run.ID, &run.StartedAt, &run.FinishedAt, &run.Config, &run.Version,
+		&run.Categories, &run.Recipes, &run.NewRecipes, &run.UpdatedRecipes,
+		&run.FailedTasks, &durationMs, &run.ExitReason)
+	run.FinishedAt = zeroEpoch(run.FinishedAt)
+	run.Duration = time.Duration(durationMs) * time.Millisecond
+	return run, err
+}
+
+// CrawlRun возвращает запуск по идентификатору
+func (db *DBService) CrawlRun(ctx context.Context, id int64) (entity.CrawlRun, error) {
+	return scanCrawlRun(db.Pool.QueryRow(ctx, `SELECT `+crawlRunColumns+` FROM crawl_runs WHERE id = $1`, id))
+}
+
+// SaveYieldStats сохраняет показатели извлечения и обнаруженные аномалии запуска
+func (db *DBService) SaveYieldStats(ctx context.Context, runID int64, stats anomaly.Stats, anomalies []anomaly.Anomaly) (err error) {
+	defer func(start time.Time) { metrics.ObserveDBWrite("save_yield_stats", start, err) }(time.Now())
+
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	anomaliesJSON, err := json.Marshal(anomalies)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Pool.Exec(ctx, `UPDATE crawl_runs SET yield_stats = $2, anomalies = $3 WHERE id = $1`,
+		runID, statsJSON, anomaliesJSON)
+	return err
+}
+
+// YieldBaseline возвращает показатели извлечения последних limit полностью завершенных
+// запусков; запуски с аномалиями в базовую линию не входят
+func (db *DBService) YieldBaseline(ctx context.Context, limit int) ([]anomaly.Stats, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT yield_stats FROM crawl_runs
+		WHERE exit_reason = $1 AND yield_stats IS NOT NULL
+		ORDER BY id DESC LIMIT $2`, entity.RunCompleted, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []anomaly.Stats
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		stats := anomaly.NewStats()
+		if err := json.Unmarshal(raw, &stats); err != nil {
+			return nil, err
+		}
+		history = append(history, stats)
+	}
+
+	return history, rows.Err()
+}
+
+// RunSnapshot возвращает категории и рецепты, встреченные в запуске
+func (db *DBService) RunSnapshot(ctx context.Context, runID int64) ([]crawldiff.Item, error) {
+	return db.querySnapshot(ctx, `
+		SELECT kind, canonical_url, name, content_hash FROM crawl_run_items WHERE run_id = $1`, runID)
+}
+
+// CurrentSnapshot возвращает категории и рецепты, сохраненные в базе и не помеченные удаленными
+func (db *DBService) CurrentSnapshot(ctx context.Context) ([]crawldiff.Item, error) {
+	return db.querySnapshot(ctx, `
+		SELECT 'category', canonical_url, name, md5(name) FROM categories
+		WHERE canonical_url IS NOT NULL AND deleted_at IS NULL
+		UNION ALL
+		SELECT 'recipe', canonical_url, name, COALESCE(content_hash, '') FROM recipes
+		WHERE canonical_url IS NOT NULL AND deleted_at IS NULL`)
+}
+
+// querySnapshot считывает записи снимка
+func (db *DBService) querySnapshot(ctx context.Context, query string, args ...any) ([]crawldiff.Item, error) {
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []crawldiff.Item
+	for rows.Next() {
+		var item crawldiff.Item
+		if err := rows.Scan(&item.Kind, &item.URL, &item.Name, &item.ContentHash); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
@@ -3,12 +3,18 @@ package entity
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Category хранит информацию о категории
 type Category struct {
-	Name string
-	Href string
+	Name         string
+	Href         string
+	CanonicalURL string // Канонический абсолютный URL категории
+
+	LastSeenAt    time.Time // Время, когда категория последний раз встретилась при обходе
+	DeletedAt     time.Time // Время пометки удаленной; нулевое у активной категории
+	DeletedReason string    // Причина пометки удаленной
 }
 
 // Validate проверяет данные категории на корректность
@@ -37,3 +43,19 @@ func normalizeCategoryName(name string) string {
 func normalizeHref(href string) string {
 	return strings.TrimSpace(href)
 }
+
+// CategoryStats хранит историю обходов категории для планирования
+type CategoryStats struct {
+	CanonicalURL  string
+	LastCrawledAt time.Time // Время последнего успешного обхода
+	Crawls        int       // Количество успешных обходов
+	Changes       int       // Количество обходов, на которых состав рецептов изменился
+}
+
+// ChangeRate возвращает долю повторных обходов, на которых состав категории изменился
+func (s CategoryStats) ChangeRate() float64 {
+	if s.Crawls < 2 {
+		return 0
+	}
+	return float64(s.Changes) / float64(s.Crawls-1)
+}
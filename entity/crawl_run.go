@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// Причины завершения запуска парсера
+const (
+	RunCompleted   = "completed"    // Обход завершен полностью
+	RunInterrupted = "interrupted"  // Запуск остановлен сигналом до завершения обхода
+	RunAnomaly     = "anomaly"      // Обход завершен, но извлечение данных резко отклонилось от прошлых запусков
+	RunWriteFailed = "write_failed" // Часть данных не записана в базу; обход прерван
+)
+
+// CrawlRun — запись о запуске парсера
+type CrawlRun struct {
+	ID         int64
+	StartedAt  time.Time
+	FinishedAt time.Time // Нулевое, пока запуск не завершен
+	Config     string    // Снимок конфигурации в JSON
+	Version    string    // Версия сборки парсера
+
+	Categories     int // Категорий сохранено в запуске
+	Recipes        int // Рецептов сохранено в запуске
+	NewRecipes     int // Рецептов, впервые найденных в запуске
+	UpdatedRecipes int // Рецептов, содержимое которых изменилось
+	FailedTasks    int // Задач, завершившихся ошибкой
+
+	Duration   time.Duration
+	ExitReason string
+}
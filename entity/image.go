@@ -0,0 +1,84 @@
+package entity
+
+// Виды изображений рецепта
+const (
+	ImageHero = "hero" // Основное изображение рецепта
+	ImageStep = "step" // Изображение шага приготовления
+)
+
+// ImageRef — ссылка на изображение рецепта, найденная при извлечении
+type ImageRef struct {
+	URL      string
+	Kind     string // ImageHero или ImageStep
+	Position int    // Порядковый номер среди изображений того же вида
+}
+
+// RecipeImage — изображение рецепта, загруженное в хранилище
+type RecipeImage struct {
+	RecipeURL   string      // Канонический URL рецепта
+	SourceURL   string      // Адрес изображения на сайте
+	Kind        string      // ImageHero или ImageStep
+	Position    int         // Порядковый номер среди изображений того же вида
+	ContentHash string      // SHA-256 содержимого в hex; одинаковые изображения хранятся один раз
+	MimeType    string      // Тип по содержимому, например image/jpeg
+	Width       int         // Ширина в пикселях
+	Height      int         // Высота в пикселях
+	Size        int         // Размер в байтах
+	Key         string      // Ключ оригинала в хранилище
+	Thumbnails  []Thumbnail // Уменьшенные копии по возрастанию ширины
+	AHash       uint64      // Перцептивные хеши: похожие изображения дают близкие значения
+	DHash       uint64
+	PHash       uint64
+}
+
+// ImageCluster — группа почти одинаковых изображений из разных рецептов
+type ImageCluster struct {
+	Images []RecipeImage
+}
+
+// Recipes возвращает URL рецептов группы без повторов в порядке появления
+func (c ImageCluster) Recipes() []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, image := range c.Images {
+		if !seen[image.RecipeURL] {
+			seen[image.RecipeURL] = true
+			urls = append(urls, image.RecipeURL)
+		}
+	}
+	return urls
+}
+
+// Thumbnail — уменьшенная копия изображения в хранилище
+type Thumbnail struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Key    string `json:"key"`
+}
+
+// ImageRefs возвращает изображения рецепта без повторов: основные — ImageURL и
+// изображения из разметки, затем изображения шагов
+func (r Recipe) ImageRefs() []ImageRef {
+	seen := make(map[string]bool)
+	var refs []ImageRef
+	add := func(url, kind string, counter *int) {
+		if url == "" || seen[url] {
+			return
+		}
+		seen[url] = true
+		refs = append(refs, ImageRef{URL: url, Kind: kind, Position: *counter})
+		*counter++
+	}
+
+	hero, step := 0, 0
+	add(r.ImageURL, ImageHero, &hero)
+	if r.Details != nil {
+		for _, url := range r.Details.Images {
+			add(url, ImageHero, &hero)
+		}
+		for _, url := range r.Details.StepImages {
+			add(url, ImageStep, &step)
+		}
+	}
+	return refs
+}
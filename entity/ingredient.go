@@ -0,0 +1,12 @@
+package entity
+
+// Ingredient — строка ингредиента, разобранная на количество, единицу, название и примечание
+type Ingredient struct {
+	Raw         string  `json:"raw"`                    // Исходная строка
+	Quantity    float64 `json:"quantity,omitempty"`     // Количество; у диапазона — нижняя граница
+	QuantityMax float64 `json:"quantity_max,omitempty"` // Верхняя граница диапазона «2–3»
+	Unit        string  `json:"unit,omitempty"`         // Единица в каноническом виде, например «ст. л.»
+	Name        string  `json:"name"`
+	Note        string  `json:"note,omitempty"`      // Примечание о подготовке: «мелко нарезать»
+	Canonical   string  `json:"canonical,omitempty"` // Название ингредиента из каталога; пустое, если не найден
+}
@@ -0,0 +1,40 @@
+package entity
+
+// Nutrition — пищевая ценность: калории в ккал, белки, жиры и углеводы в граммах
+type Nutrition struct {
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Fat           float64 `json:"fat"`
+	Carbohydrates float64 `json:"carbohydrates"`
+}
+
+// NutritionInfo — пищевая ценность порции рецепта: рассчитанная по ингредиентам
+// и указанная сайтом, если он ее приводит
+type NutritionInfo struct {
+	Computed      *Nutrition `json:"computed,omitempty"`      // Расчет по таблице состава продуктов
+	Site          *Nutrition `json:"site,omitempty"`          // Значения из разметки страницы
+	Servings      int        `json:"servings"`                // Порций в расчете; 0 — порции неизвестны, расчет на весь рецепт
+	Coverage      float64    `json:"coverage"`                // Доля ингредиентов с количеством, учтенных в расчете
+	Missing       []string   `json:"missing,omitempty"`       // Ингредиенты с количеством, не вошедшие в расчет
+	Discrepancies []string   `json:"discrepancies,omitempty"` // Показатели, где расчет и сайт сильно расходятся
+}
+
+// Add возвращает сумму пищевой ценности
+func (n Nutrition) Add(other Nutrition) Nutrition {
+	return Nutrition{
+		Calories:      n.Calories + other.Calories,
+		Protein:       n.Protein + other.Protein,
+		Fat:           n.Fat + other.Fat,
+		Carbohydrates: n.Carbohydrates + other.Carbohydrates,
+	}
+}
+
+// Scale возвращает пищевую ценность, умноженную на коэффициент
+func (n Nutrition) Scale(factor float64) Nutrition {
+	return Nutrition{
+		Calories:      n.Calories * factor,
+		Protein:       n.Protein * factor,
+		Fat:           n.Fat * factor,
+		Carbohydrates: n.Carbohydrates * factor,
+	}
+}
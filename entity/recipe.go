@@ -3,12 +3,44 @@ package entity
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Recipe хранит информацию о рецепте
 type Recipe struct {
-	Name string
-	Href string
+	Name         string
+	Href         string
+	CanonicalURL string         // Канонический абсолютный URL рецепта
+	Ingredients  []string       // Строки ингредиентов в исходном виде
+	Parsed       []Ingredient   // Разобранные строки ингредиентов в том же порядке
+	ImageURL     string         // Адрес основного изображения
+	Details      *RecipeDetails // Структурированные данные страницы рецепта; nil, если их нет
+	Nutrition    *NutritionInfo // Пищевая ценность порции; nil, если ее не удалось получить
+	Tags         []RecipeTag    // Метки диет и аллергенов; nil, если классификация не проводилась
+	Detailed     bool           // Рецепт извлечен со страницы рецепта, а не из карточки списка
+
+	LastSeenAt    time.Time // Время, когда рецепт последний раз встретился при обходе
+	DeletedAt     time.Time // Время пометки удаленным; нулевое у активного рецепта
+	DeletedReason string    // Причина пометки удаленным
+	Fingerprint   uint64    // Отпечаток содержимого для поиска перепубликаций
+}
+
+// RecipeDetails — структурированные данные рецепта из разметки schema.org
+// (JSON-LD, microdata или RDFa); время хранится в исходном виде и в секундах
+type RecipeDetails struct {
+	Instructions     []string          `json:"instructions,omitempty"`
+	Yield            string            `json:"yield,omitempty"`
+	PrepTime         string            `json:"prep_time,omitempty"`
+	CookTime         string            `json:"cook_time,omitempty"`
+	TotalTime        string            `json:"total_time,omitempty"`
+	PrepSeconds      int               `json:"prep_seconds,omitempty"`      // 0 — время неизвестно
+	CookSeconds      int               `json:"cook_seconds,omitempty"`      // 0 — время неизвестно
+	TotalSeconds     int               `json:"total_seconds,omitempty"`     // Указанное общее время или сумма подготовки и приготовления
+	TimeInconsistent bool              `json:"time_inconsistent,omitempty"` // Подготовка и приготовление не сходятся с общим временем
+	Nutrition        map[string]string `json:"nutrition,omitempty"`         // Свойства NutritionInformation, например calories
+	Images           []string          `json:"images,omitempty"`
+	StepImages       []string          `json:"step_images,omitempty"` // Изображения шагов приготовления по порядку
+	Author           string            `json:"author,omitempty"`
 }
 
 // Validate проверяет данные рецепта на корректность
@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/seniorcat/scraper/pkg/units"
+)
+
+// ErrUnknownServings возвращается при пересчете рецепта, число порций которого неизвестно
+var ErrUnknownServings = errors.New("recipe servings are unknown")
+
+// servingsNumber — первое число в recipeYield: «4 порции», «на 6 персон»
+var servingsNumber = regexp.MustCompile(`\d+`)
+
+// Servings возвращает число порций рецепта или 0, если оно неизвестно
+func (r Recipe) Servings() int {
+	if r.Details == nil {
+		return 0
+	}
+	servings, _ := strconv.Atoi(servingsNumber.FindString(r.Details.Yield))
+	return servings
+}
+
+// Scale возвращает копию рецепта, пересчитанную на заданное число порций;
+// количества округляются до удобных значений
+func (r Recipe) Scale(servings int) (Recipe, error) {
+	if servings <= 0 {
+		return Recipe{}, fmt.Errorf("invalid servings %d", servings)
+	}
+	base := r.Servings()
+	if base == 0 {
+		return Recipe{}, ErrUnknownServings
+	}
+	factor := float64(servings) / float64(base)
+
+	scaled := r
+	scaled.Parsed = make([]Ingredient, len(r.Parsed))
+	for i, ingredient := range r.Parsed {
+		ingredient.Quantity = scaleQuantity(ingredient.Quantity, factor, ingredient.Unit)
+		ingredient.QuantityMax = scaleQuantity(ingredient.QuantityMax, factor, ingredient.Unit)
+		scaled.Parsed[i] = ingredient
+	}
+
+	details := *r.Details
+	details.Yield = strconv.Itoa(servings)
+	scaled.Details = &details
+	return scaled, nil
+}
+
+// InGrams возвращает ингредиенты, где все, что удается перевести, выражено в граммах
+func (r Recipe) InGrams() []Ingredient {
+	ingredients := make([]Ingredient, len(r.Parsed))
+	for i, ingredient := range r.Parsed {
+		ingredients[i] = ingredient.InGrams()
+	}
+	return ingredients
+}
+
+// Grams возвращает массу ингредиента в граммах, если ее можно вычислить по единице,
+// плотности или штучному весу канонического ингредиента
+func (i Ingredient) Grams() (float64, bool) {
+	if i.Quantity == 0 {
+		return 0, false
+	}
+	return units.Grams(i.Quantity, i.Unit, i.Canonical)
+}
+
+// InGrams возвращает ингредиент с количеством в граммах; непереводимый возвращается как есть
+func (i Ingredient) InGrams() Ingredient {
+	grams, ok := i.Grams()
+	if !ok {
+		return i
+	}
+
+	converted := i
+	converted.Quantity = units.Round(grams, units.Gram)
+	converted.QuantityMax = 0
+	if i.QuantityMax > 0 {
+		maxGrams, _ := units.Grams(i.QuantityMax, i.Unit, i.Canonical)
+		converted.QuantityMax = units.Round(maxGrams, units.Gram)
+	}
+	converted.Unit = units.Gram
+	return converted
+}
+
+// scaleQuantity умножает количество и округляет его; отсутствующее количество не меняется
+func scaleQuantity(quantity, factor float64, unit string) float64 {
+	if quantity == 0 {
+		return 0
+	}
+	return units.Round(quantity*factor, unit)
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecipeScale проверяет пересчет рецепта на другое число порций
+func TestRecipeScale(t *testing.T) {
+	recipe := Recipe{
+		Parsed: []Ingredient{
+			{Name: "муки", Canonical: "мука пшеничная", Quantity: 1, Unit: "стакан"},
+			{Name: "яйца", Canonical: "яйцо куриное", Quantity: 3},
+			{Name: "сахара", Canonical: "сахар", Quantity: 2, QuantityMax: 3, Unit: "ст. л."},
+			{Name: "соль", Canonical: "соль", Unit: "по вкусу"},
+		},
+		Details: &RecipeDetails{Yield: "4 порции"},
+	}
+	assert.Equal(t, 4, recipe.Servings())
+
+	scaled, err := recipe.Scale(6)
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, scaled.Parsed[0].Quantity)
+	assert.Equal(t, 4.5, scaled.Parsed[1].Quantity)
+	assert.Equal(t, 3.0, scaled.Parsed[2].Quantity)
+	assert.Equal(t, 4.5, scaled.Parsed[2].QuantityMax)
+	assert.Zero(t, scaled.Parsed[3].Quantity)
+	assert.Equal(t, "6", scaled.Details.Yield)
+
+	// Исходный рецепт не меняется
+	assert.Equal(t, 1.0, recipe.Parsed[0].Quantity)
+	assert.Equal(t, "4 порции", recipe.Details.Yield)
+
+	_, err = Recipe{}.Scale(2)
+	assert.ErrorIs(t, err, ErrUnknownServings)
+}
+
+// TestRecipeInGrams проверяет перевод ингредиентов в граммы
+func TestRecipeInGrams(t *testing.T) {
+	recipe := Recipe{Parsed: []Ingredient{
+		{Canonical: "мука пшеничная", Quantity: 1, Unit: "стакан"},
+		{Canonical: "яйцо куриное", Quantity: 2},
+		{Canonical: "молоко", Quantity: 0.5, Unit: "л"},
+		{Canonical: "чеснок", Quantity: 2, QuantityMax: 3, Unit: "зубчик"},
+		{Canonical: "соль", Unit: "по вкусу"},
+		{Name: "трюфель", Quantity: 1, Unit: "шт"},
+	}}
+
+	grams := recipe.InGrams()
+	assert.Equal(t, Ingredient{Canonical: "мука пшеничная", Quantity: 130, Unit: "г"}, grams[0])
+	assert.Equal(t, 110.0, grams[1].Quantity)
+	assert.Equal(t, 515.0, grams[2].Quantity)
+	assert.Equal(t, Ingredient{Canonical: "чеснок", Quantity: 10, QuantityMax: 15, Unit: "г"}, grams[3])
+	assert.Equal(t, recipe.Parsed[4], grams[4])
+	assert.Equal(t, recipe.Parsed[5], grams[5])
+}
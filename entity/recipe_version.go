@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecipeVersion — сохраненное состояние рецепта на момент изменения на сайте
+type RecipeVersion struct {
+	CanonicalURL string
+	Version      int    // Номер версии, начиная с 1
+	ContentHash  string // Хеш названия, ингредиентов и изображения
+	Name         string
+	Ingredients  []string
+	ImageURL     string
+	CreatedAt    time.Time
+}
+
+// RecipeDiff описывает изменения рецепта между двумя версиями
+type RecipeDiff struct {
+	From, To           int // Номера сравниваемых версий
+	OldName, NewName   string
+	AddedIngredients   []string
+	RemovedIngredients []string
+	OldImage, NewImage string
+}
+
+// DiffRecipeVersions сравнивает две версии рецепта
+func DiffRecipeVersions(from, to RecipeVersion) RecipeDiff {
+	diff := RecipeDiff{From: from.Version, To: to.Version}
+
+	if from.Name != to.Name {
+		diff.OldName, diff.NewName = from.Name, to.Name
+	}
+	if from.ImageURL != to.ImageURL {
+		diff.OldImage, diff.NewImage = from.ImageURL, to.ImageURL
+	}
+	diff.RemovedIngredients = subtract(from.Ingredients, to.Ingredients)
+	diff.AddedIngredients = subtract(to.Ingredients, from.Ingredients)
+
+	return diff
+}
+
+// Empty сообщает, что версии не отличаются
+func (d RecipeDiff) Empty() bool {
+	return d.OldName == d.NewName && d.OldImage == d.NewImage &&
+		len(d.AddedIngredients) == 0 && len(d.RemovedIngredients) == 0
+}
+
+// String возвращает изменения в виде, пригодном для чтения редакторами
+func (d RecipeDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "v%d -> v%d\n", d.From, d.To)
+	if d.Empty() {
+		b.WriteString("  без изменений\n")
+		return b.String()
+	}
+	if d.OldName != d.NewName {
+		fmt.Fprintf(&b, "  название: %q -> %q\n", d.OldName, d.NewName)
+	}
+	for _, ingredient := range d.RemovedIngredients {
+		fmt.Fprintf(&b, "  - %s\n", ingredient)
+	}
+	for _, ingredient := range d.AddedIngredients {
+		fmt.Fprintf(&b, "  + %s\n", ingredient)
+	}
+	if d.OldImage != d.NewImage {
+		fmt.Fprintf(&b, "  изображение: %s -> %s\n", d.OldImage, d.NewImage)
+	}
+	return b.String()
+}
+
+// subtract возвращает элементы a, которых нет в b, с учетом повторов
+func subtract(a, b []string) []string {
+	counts := make(map[string]int, len(b))
+	for _, item := range b {
+		counts[item]++
+	}
+
+	var rest []string
+	for _, item := range a {
+		if counts[item] > 0 {
+			counts[item]--
+			continue
+		}
+		rest = append(rest, item)
+	}
+	return rest
+}
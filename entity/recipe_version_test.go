@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiffRecipeVersions проверяет сравнение версий рецепта
+func TestDiffRecipeVersions(t *testing.T) {
+	v1 := RecipeVersion{Version: 1, Name: "борщ", Ingredients: []string{"свекла", "капуста", "соль"}, ImageURL: "a.jpg"}
+	v2 := RecipeVersion{Version: 2, Name: "борщ украинский", Ingredients: []string{"свекла", "капуста", "сало"}, ImageURL: "a.jpg"}
+
+	diff := DiffRecipeVersions(v1, v2)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, "борщ", diff.OldName)
+	assert.Equal(t, "борщ украинский", diff.NewName)
+	assert.Equal(t, []string{"сало"}, diff.AddedIngredients)
+	assert.Equal(t, []string{"соль"}, diff.RemovedIngredients)
+	assert.Empty(t, diff.NewImage)
+	assert.Contains(t, diff.String(), "+ сало")
+
+	assert.True(t, DiffRecipeVersions(v1, v1).Empty())
+}
@@ -0,0 +1,8 @@
+package entity
+
+// RecipeTag — метка диеты или аллергена рецепта с объяснением
+type RecipeTag struct {
+	Tag         string   `json:"tag"`                   // Например vegetarian, contains-nuts
+	Ingredients []string `json:"ingredients,omitempty"` // Канонические ингредиенты, по которым назначена метка
+	Reason      string   `json:"reason"`                // Объяснение метки
+}
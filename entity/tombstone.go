@@ -0,0 +1,19 @@
+package entity
+
+import "fmt"
+
+// Причины пометки рецептов и категорий удаленными
+const (
+	DeletedNotListed = "not_listed" // Не встречается на сайте несколько обходов подряд
+	DeletedGone      = "gone"       // Страница отвечает 404 или 410
+)
+
+// GoneReason возвращает причину удаления для страницы, ответившей statusCode
+func GoneReason(statusCode int) string {
+	return fmt.Sprintf("%s: http %d", DeletedGone, statusCode)
+}
+
+// IsGoneStatus сообщает, означает ли код ответа, что страница удалена
+func IsGoneStatus(statusCode int) bool {
+	return statusCode == 404 || statusCode == 410
+}
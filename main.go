@@ -1,27 +1,23 @@
 package main
 
 import (
-	"net/http"
+	"os"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/seniorcat/scraper/cmd"
 	"github.com/seniorcat/scraper/pkg/metrics"
 )
 
-func startMetricsServer() {
-	http.Handle("/metrics", promhttp.Handler())
-	http.ListenAndServe(":2112", nil)
-}
-
 func main() {
+	// Регистрация метрик; они отдаются административным сервером команды "run"
 	metrics.Init()
-	// Запуск HTTP-сервера для экспорта метрик
-	go startMetricsServer()
 	cli := cmd.NewCLI()
 
 	// Регистрация команды "run"
 	cli.RegisterCommand("run", "Запуск парсера", func(args []string) {
-		cmd.RunParser()
+		// Причина уже записана в журнал; ненулевой код нужен планировщику запусков
+		if err := cmd.RunParser(); err != nil {
+			os.Exit(1)
+		}
 	})
 
 	// Регистрация команды "initdb" для создания таблиц
@@ -29,6 +25,51 @@ func main() {
 		cmd.CreateTables()
 	})
 
+	// Регистрация команды "history" для просмотра изменений рецепта
+	cli.RegisterCommand("history", "История изменений рецепта: history <url> [версия-от версия-до]", func(args []string) {
+		cmd.RecipeHistory(args)
+	})
+
+	// Регистрация команды "export" для выгрузки данных
+	cli.RegisterCommand("export", "Выгрузка в JSON Lines: export [-type recipes|categories] [-deleted] [-o файл]", func(args []string) {
+		cmd.Export(args)
+	})
+
+	// Регистрация команды "runs" для просмотра истории запусков
+	cli.RegisterCommand("runs", "Последние запуски парсера: runs [-n количество]", func(args []string) {
+		cmd.ListRuns(args)
+	})
+
+	// Регистрация команды "diff" для сравнения запусков
+	cli.RegisterCommand("diff", "Сравнение запусков: diff [-format text|json|markdown] [-o файл] <запуск> [<запуск>|current]", func(args []string) {
+		cmd.Diff(args)
+	})
+
+	// Регистрация команды "ingredient" для поиска рецептов по ингредиенту
+	cli.RegisterCommand("ingredient", "Рецепты с ингредиентом: ingredient <название>", func(args []string) {
+		cmd.RecipesWithIngredient(args)
+	})
+
+	// Регистрация команды "diet" для поиска рецептов по меткам диет и аллергенов
+	cli.RegisterCommand("diet", "Рецепты с метками диет: diet <метка> [<метка>...]", func(args []string) {
+		cmd.RecipesWithTags(args)
+	})
+
+	// Регистрация команды "quick" для поиска быстрых рецептов
+	cli.RegisterCommand("quick", "Рецепты не дольше заданного времени: quick <минут>", func(args []string) {
+		cmd.QuickRecipes(args)
+	})
+
+	// Регистрация команды "images" для поиска одной фотографии в разных рецептах
+	cli.RegisterCommand("images", "Группы похожих изображений: images [-hash phash|dhash|ahash] [-d расстояние]", func(args []string) {
+		cmd.ImageClusters(args)
+	})
+
+	// Регистрация команды "serve" для запросов к сохраненным рецептам без обхода
+	cli.RegisterCommand("serve", "HTTP-сервер запросов: /recipes/scale", func(args []string) {
+		cmd.Serve()
+	})
+
 	// Добавляем команду "help" для справки
 	cli.RegisterCommand("help", "Вывод справки по командам", func(args []string) {
 		cli.PrintHelp()
@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Controller — управление обходом во время работы
+type Controller interface {
+	SetWorkersCount(count int) error
+	SetRPS(rps int) error
+	Pause()
+	Resume()
+}
+
+// SetController регистрирует эндпоинты управления обходом:
+// POST /control/pause, /control/resume, /control/workers?count=N и /control/rps?value=N.
+// Лимит rps задается для каждого воркера, а не для пула в целом. Эндпоинты требуют токен.
+func (s *Server) SetController(c Controller) {
+	s.mux.HandleFunc("POST /control/pause", s.control(func(r *http.Request) error {
+		c.Pause()
+		return nil
+	}))
+	s.mux.HandleFunc("POST /control/resume", s.control(func(r *http.Request) error {
+		c.Resume()
+		return nil
+	}))
+	s.mux.HandleFunc("POST /control/workers", s.control(func(r *http.Request) error {
+		count, err := intParam(r, "count")
+		if err != nil {
+			return err
+		}
+		return c.SetWorkersCount(count)
+	}))
+	s.mux.HandleFunc("POST /control/rps", s.control(func(r *http.Request) error {
+		rps, err := intParam(r, "value")
+		if err != nil {
+			return err
+		}
+		return c.SetRPS(rps)
+	}))
+}
+
+// control оборачивает действие управления в HTTP-обработчик, доступный только с токеном
+func (s *Server) control(action func(r *http.Request) error) http.HandlerFunc {
+	return s.authorized(func(w http.ResponseWriter, r *http.Request) {
+		if err := action(r); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		s.mu.RLock()
+		provider := s.status
+		s.mu.RUnlock()
+
+		// В ответ отдается актуальный статус, чтобы сразу увидеть эффект
+		if provider != nil {
+			writeJSON(w, http.StatusOK, provider())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+}
+
+// intParam читает целочисленный параметр запроса
+func intParam(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		raw = r.FormValue(name)
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s parameter %q", name, raw)
+	}
+	return value, nil
+}
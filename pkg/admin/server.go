@@ -0,0 +1,208 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// DefaultAddress — адрес административного сервера по умолчанию
+const DefaultAddress = ":2112"
+
+// readinessTimeout ограничивает время выполнения всех проверок готовности
+const readinessTimeout = 3 * time.Second
+
+// Check — проверка готовности сервиса; ошибка означает, что сервис не готов
+type Check func(ctx context.Context) error
+
+// Server — административный HTTP-сервер с метриками, пробами, профилированием и статусом.
+// Профилирование и управление обходом доступны только с токеном, заданным через SetToken.
+type Server struct {
+	Logger *zap.Logger
+
+	httpServer *http.Server
+	mux        *http.ServeMux
+
+	mu     sync.RWMutex
+	checks map[string]Check
+	status func() any
+	token  string
+}
+
+// NewServer создает административный сервер на заданном адресе
+func NewServer(address string, logger *zap.Logger) *Server {
+	if address == "" {
+		address = DefaultAddress
+	}
+
+	s := &Server{
+		Logger: logger,
+		mux:    http.NewServeMux(),
+		checks: make(map[string]Check),
+	}
+	s.httpServer = &http.Server{
+		Addr:              address,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	s.mux.Handle("/metrics", promhttp.Handler())
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+	s.mux.HandleFunc("/readyz", s.handleReady)
+	s.mux.HandleFunc("/status", s.handleStatus)
+
+	s.mux.HandleFunc("/debug/pprof/", s.authorized(pprof.Index))
+	s.mux.HandleFunc("/debug/pprof/cmdline", s.authorized(pprof.Cmdline))
+	s.mux.HandleFunc("/debug/pprof/profile", s.authorized(pprof.Profile))
+	s.mux.HandleFunc("/debug/pprof/symbol", s.authorized(pprof.Symbol))
+	s.mux.HandleFunc("/debug/pprof/trace", s.authorized(pprof.Trace))
+
+	return s
+}
+
+// AddReadinessCheck регистрирует проверку, выполняемую на /readyz
+func (s *Server) AddReadinessCheck(name string, check Check) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks[name] = check
+}
+
+// SetStatusProvider задает функцию, результат которой отдается на /status в виде JSON
+func (s *Server) SetStatusProvider(provider func() any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = provider
+}
+
+// SetToken задает Bearer-токен для профилирования и управления обходом.
+// Пока токен пуст, эти эндпоинты отвечают 403.
+func (s *Server) SetToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// authorized пропускает к обработчику только запросы с верным токеном
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		token := s.token
+		s.mu.RUnlock()
+
+		if token == "" {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "admin token is not configured"})
+			return
+		}
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid admin token"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Handle регистрирует дополнительный обработчик
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Start открывает порт и запускает обслуживание запросов в отдельной горутине.
+// Ошибка занятого порта возвращается сразу.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.Logger.Error("Admin server stopped", zap.Error(err))
+		}
+	}()
+
+	s.Logger.Info("Admin server started", zap.String("address", listener.Addr().String()))
+	return nil
+}
+
+// Shutdown корректно останавливает сервер, дожидаясь завершения активных запросов
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// handleHealth отвечает, что процесс жив
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReady выполняет все проверки готовности
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	checks := make(map[string]Check, len(s.checks))
+	names := make([]string, 0, len(s.checks))
+	for name, check := range s.checks {
+		checks[name] = check
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+	sort.Strings(names)
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	code := http.StatusOK
+	response := struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}{
+		Status: "ok",
+		Checks: make(map[string]string, len(names)),
+	}
+
+	for _, name := range names {
+		if err := checks[name](ctx); err != nil {
+			response.Checks[name] = err.Error()
+			response.Status = "fail"
+			code = http.StatusServiceUnavailable
+			continue
+		}
+		response.Checks[name] = "ok"
+	}
+
+	writeJSON(w, code, response)
+}
+
+// handleStatus отдает текущее состояние обхода
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.status
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "status is not available"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, provider())
+}
+
+// writeJSON записывает ответ в формате JSON
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
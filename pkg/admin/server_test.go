@@ -0,0 +1,157 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// serve выполняет запрос к обработчикам сервера без открытия порта
+func serve(s *Server, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+// TestServerHealth проверяет ответ /healthz
+func TestServerHealth(t *testing.T) {
+	s := NewServer("", zap.NewNop())
+
+	resp := serve(s, "/healthz")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+// TestServerReadiness проверяет, что /readyz отражает результаты проверок
+func TestServerReadiness(t *testing.T) {
+	s := NewServer("", zap.NewNop())
+	s.AddReadinessCheck("database", func(ctx context.Context) error { return nil })
+
+	resp := serve(s, "/readyz")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	s.AddReadinessCheck("workers", func(ctx context.Context) error { return errors.New("0 of 5 workers alive") })
+
+	resp = serve(s, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+
+	var body struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, "fail", body.Status)
+	assert.Equal(t, "ok", body.Checks["database"])
+	assert.Equal(t, "0 of 5 workers alive", body.Checks["workers"])
+}
+
+// TestServerStatus проверяет отдачу статуса в JSON
+func TestServerStatus(t *testing.T) {
+	s := NewServer("", zap.NewNop())
+
+	resp := serve(s, "/status")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+
+	s.SetStatusProvider(func() any { return map[string]int{"task_queue_depth": 3} })
+
+	resp = serve(s, "/status")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"task_queue_depth": 3}`, resp.Body.String())
+}
+
+// fakeController запоминает вызовы управления
+type fakeController struct {
+	workers int
+	rps     int
+	paused  bool
+}
+
+func (c *fakeController) SetWorkersCount(count int) error {
+	if count < 1 {
+		return errors.New("workers count must be positive")
+	}
+	c.workers = count
+	return nil
+}
+
+func (c *fakeController) SetRPS(rps int) error {
+	c.rps = rps
+	return nil
+}
+
+func (c *fakeController) Pause()  { c.paused = true }
+func (c *fakeController) Resume() { c.paused = false }
+
+// TestServerControl проверяет эндпоинты управления обходом
+func TestServerControl(t *testing.T) {
+	s := NewServer("", zap.NewNop())
+	s.SetToken("secret")
+	controller := &fakeController{}
+	s.SetController(controller)
+
+	post := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, path, nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		s.mux.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusOK, post("/control/pause").Code)
+	assert.True(t, controller.paused)
+	assert.Equal(t, http.StatusOK, post("/control/resume").Code)
+	assert.False(t, controller.paused)
+
+	assert.Equal(t, http.StatusOK, post("/control/workers?count=8").Code)
+	assert.Equal(t, 8, controller.workers)
+	assert.Equal(t, http.StatusBadRequest, post("/control/workers?count=0").Code)
+	assert.Equal(t, http.StatusBadRequest, post("/control/workers?count=many").Code)
+
+	assert.Equal(t, http.StatusOK, post("/control/rps?value=2").Code)
+	assert.Equal(t, 2, controller.rps)
+
+	// Управление доступно только через POST
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(s, "/control/pause").Code)
+}
+
+// TestServerToken проверяет, что управление и профилирование требуют токен
+func TestServerToken(t *testing.T) {
+	s := NewServer("", zap.NewNop())
+	controller := &fakeController{}
+	s.SetController(controller)
+
+	request := func(method, path, token string) int {
+		recorder := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		s.mux.ServeHTTP(recorder, r)
+		return recorder.Code
+	}
+
+	// Без настроенного токена эндпоинты отключены
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPost, "/control/pause", ""))
+	assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/debug/pprof/", ""))
+	assert.False(t, controller.paused)
+
+	s.SetToken("secret")
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "/control/pause", ""))
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "/control/pause", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/debug/pprof/", "wrong"))
+	assert.False(t, controller.paused)
+
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/control/pause", "secret"))
+	assert.True(t, controller.paused)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/debug/pprof/", "secret"))
+
+	// Метрики и пробы доступны без токена
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/healthz", ""))
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/metrics", ""))
+}
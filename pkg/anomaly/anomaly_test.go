@@ -0,0 +1,96 @@
+package anomaly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run создает показатели запуска с заданным числом рецептов и пустых изображений
+func run(recipes, emptyImages, invalid int, categories map[string]int) Stats {
+	c := NewCollector()
+	for i := 0; i < 10; i++ {
+		c.Extracted("category")
+	}
+	for i := 0; i < recipes; i++ {
+		c.Extracted("recipe")
+	}
+	for i := 0; i < emptyImages; i++ {
+		c.EmptyField("recipe", "image")
+	}
+	for i := 0; i < invalid; i++ {
+		c.ValidationError("recipe")
+	}
+	for url, n := range categories {
+		c.CategoryYield(url, n)
+	}
+	return c.Snapshot()
+}
+
+// history возвращает три обычных запуска
+func history() []Stats {
+	categories := map[string]int{"https://eda.ru/recepty/supy": 20}
+	return []Stats{
+		run(200, 5, 1, categories),
+		run(190, 4, 0, categories),
+		run(210, 6, 2, categories),
+	}
+}
+
+// TestDetectNormalRun проверяет, что обычный запуск не считается аномальным
+func TestDetectNormalRun(t *testing.T) {
+	anomalies := Detect(run(180, 5, 1, map[string]int{"https://eda.ru/recepty/supy": 18}), history(), DefaultThresholds)
+	assert.Empty(t, anomalies)
+}
+
+// TestDetectNoItems проверяет, что пустой этап — аномалия даже без истории
+func TestDetectNoItems(t *testing.T) {
+	current := NewStats()
+	current.stage("category")
+
+	anomalies := Detect(current, nil, DefaultThresholds)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, KindNoItems, anomalies[0].Kind)
+	assert.True(t, Critical(anomalies))
+
+	// Этап, который был в прошлых запусках, а теперь пропал, тоже пустой
+	anomalies = Detect(NewStats(), history(), DefaultThresholds)
+	assert.Len(t, anomalies, 2)
+}
+
+// TestDetectDrops проверяет падение числа записей, всплеск пустых полей и ошибок валидации
+func TestDetectDrops(t *testing.T) {
+	anomalies := Detect(run(50, 40, 30, map[string]int{"https://eda.ru/recepty/supy": 2}), history(), DefaultThresholds)
+
+	kinds := make(map[string]string)
+	for _, a := range anomalies {
+		kinds[a.Kind] = a.Severity
+	}
+	assert.Equal(t, map[string]string{
+		KindYieldDrop:        SeverityCritical,
+		KindValidationErrors: SeverityCritical,
+		KindEmptyFields:      SeverityCritical,
+		KindCategoryDrop:     SeverityWarning,
+	}, kinds)
+}
+
+// TestDetectShortHistory проверяет, что без достаточной истории сравнение не выполняется
+func TestDetectShortHistory(t *testing.T) {
+	anomalies := Detect(run(5, 5, 5, nil), history()[:2], DefaultThresholds)
+	assert.Empty(t, anomalies)
+}
+
+// TestCollectorSnapshot проверяет, что снимок не меняется при дальнейшем накоплении
+func TestCollectorSnapshot(t *testing.T) {
+	c := NewCollector()
+	c.EmptyField("recipe", "image")
+	snapshot := c.Snapshot()
+	c.EmptyField("recipe", "image")
+	assert.Equal(t, 1, snapshot.Stages["recipe"].EmptyFields["image"])
+
+	// Nil-сборщик допустим
+	var empty *Collector
+	empty.Extracted("recipe")
+	assert.Empty(t, empty.Snapshot().Stages)
+}
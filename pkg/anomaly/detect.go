@@ -0,0 +1,210 @@
+package anomaly
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Виды аномалий
+const (
+	KindNoItems          = "no_items"            // Этап не извлек ни одной записи
+	KindYieldDrop        = "yield_drop"          // Число записей этапа резко упало
+	KindCategoryDrop     = "category_yield_drop" // Число рецептов категории резко упало
+	KindEmptyFields      = "empty_fields"        // Обязательное поле стало пустым аномально часто
+	KindValidationErrors = "validation_errors"   // Всплеск ошибок валидации
+)
+
+// Уровни аномалий
+const (
+	SeverityWarning  = "warning"  // Только предупреждение
+	SeverityCritical = "critical" // Запуск считается неуспешным
+)
+
+// Thresholds — пороги обнаружения аномалий
+type Thresholds struct {
+	MinBaselineRuns    int     // Минимум прошлых запусков для сравнения с базовой линией
+	MaxDrop            float64 // Допустимое падение числа записей этапа, доля от базовой линии
+	MaxCategoryDrop    float64 // Допустимое падение числа рецептов категории
+	MinCategoryItems   int     // Категории с меньшей базовой линией не проверяются
+	MaxEmptyRateGrowth float64 // Допустимый рост доли записей с пустым полем, в долях
+	MaxErrorRateGrowth float64 // Допустимый рост доли ошибок валидации, в долях
+}
+
+// DefaultThresholds — пороги по умолчанию
+var DefaultThresholds = Thresholds{
+	MinBaselineRuns:    3,
+	MaxDrop:            0.5,
+	MaxCategoryDrop:    0.8,
+	MinCategoryItems:   5,
+	MaxEmptyRateGrowth: 0.3,
+	MaxErrorRateGrowth: 0.2,
+}
+
+// Anomaly — обнаруженное отклонение от базовой линии
+type Anomaly struct {
+	Kind     string  `json:"kind"`
+	Severity string  `json:"severity"`
+	Stage    string  `json:"stage,omitempty"`
+	Key      string  `json:"key,omitempty"` // Поле или URL категории
+	Current  float64 `json:"current"`
+	Baseline float64 `json:"baseline"`
+}
+
+// String возвращает описание аномалии для журнала
+func (a Anomaly) String() string {
+	target := a.Stage
+	if a.Key != "" {
+		target += " " + a.Key
+	}
+	return fmt.Sprintf("%s %s (%s): %.3g, baseline %.3g", a.Severity, a.Kind, target, a.Current, a.Baseline)
+}
+
+// Critical сообщает, есть ли среди аномалий критические
+func Critical(anomalies []Anomaly) bool {
+	for _, a := range anomalies {
+		if a.Severity == SeverityCritical {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect сравнивает показатели текущего запуска с медианой прошлых запусков.
+// Этап без единой записи считается аномалией и без истории.
+func Detect(current Stats, history []Stats, t Thresholds) []Anomaly {
+	var anomalies []Anomaly
+
+	stages := make(map[string]bool)
+	for name := range current.Stages {
+		stages[name] = true
+	}
+	for _, past := range history {
+		for name := range past.Stages {
+			stages[name] = true
+		}
+	}
+
+	for _, name := range sortedKeys(stages) {
+		st := current.Stages[name]
+		if st == nil {
+			st = &StageStats{}
+		}
+
+		if st.Items == 0 {
+			anomalies = append(anomalies, Anomaly{Kind: KindNoItems, Severity: SeverityCritical, Stage: name,
+				Baseline: median(history, func(s Stats) float64 { return float64(stageOf(s, name).Items) })})
+			continue
+		}
+		if len(history) < t.MinBaselineRuns {
+			continue
+		}
+
+		baseItems := median(history, func(s Stats) float64 { return float64(stageOf(s, name).Items) })
+		if baseItems > 0 && float64(st.Items) < baseItems*(1-t.MaxDrop) {
+			anomalies = append(anomalies, Anomaly{Kind: KindYieldDrop, Severity: SeverityCritical, Stage: name,
+				Current: float64(st.Items), Baseline: baseItems})
+		}
+
+		errorRate := rate(st.ValidationErrors, st.Items+st.ValidationErrors)
+		baseErrorRate := median(history, func(s Stats) float64 {
+			past := stageOf(s, name)
+			return rate(past.ValidationErrors, past.Items+past.ValidationErrors)
+		})
+		if errorRate > baseErrorRate+t.MaxErrorRateGrowth {
+			anomalies = append(anomalies, Anomaly{Kind: KindValidationErrors, Severity: SeverityCritical, Stage: name,
+				Current: errorRate, Baseline: baseErrorRate})
+		}
+
+		for _, field := range sortedKeys(fieldsOf(name, current, history)) {
+			emptyRate := rate(st.EmptyFields[field], st.Items)
+			baseEmptyRate := median(history, func(s Stats) float64 {
+				past := stageOf(s, name)
+				return rate(past.EmptyFields[field], past.Items)
+			})
+			if emptyRate > baseEmptyRate+t.MaxEmptyRateGrowth {
+				anomalies = append(anomalies, Anomaly{Kind: KindEmptyFields, Severity: SeverityCritical, Stage: name, Key: field,
+					Current: emptyRate, Baseline: baseEmptyRate})
+			}
+		}
+	}
+
+	// Падение в отдельных категориях возможно и без поломки разметки, поэтому это предупреждение
+	if len(history) >= t.MinBaselineRuns {
+		for _, url := range sortedKeys(boolKeys(current.Categories)) {
+			base := median(history, func(s Stats) float64 { return float64(s.Categories[url]) })
+			if base < float64(t.MinCategoryItems) {
+				continue
+			}
+			if n := float64(current.Categories[url]); n < base*(1-t.MaxCategoryDrop) {
+				anomalies = append(anomalies, Anomaly{Kind: KindCategoryDrop, Severity: SeverityWarning, Key: url,
+					Current: n, Baseline: base})
+			}
+		}
+	}
+
+	return anomalies
+}
+
+// stageOf возвращает показатели этапа или пустые, если этапа не было
+func stageOf(s Stats, name string) StageStats {
+	if st, ok := s.Stages[name]; ok && st != nil {
+		return *st
+	}
+	return StageStats{}
+}
+
+// fieldsOf возвращает поля этапа, встречавшиеся пустыми в текущем или прошлых запусках
+func fieldsOf(stage string, current Stats, history []Stats) map[string]bool {
+	fields := make(map[string]bool)
+	for _, s := range append([]Stats{current}, history...) {
+		for field := range stageOf(s, stage).EmptyFields {
+			fields[field] = true
+		}
+	}
+	return fields
+}
+
+// median возвращает медиану значения по прошлым запускам
+func median(history []Stats, value func(Stats) float64) float64 {
+	if len(history) == 0 {
+		return 0
+	}
+	values := make([]float64, len(history))
+	for i, s := range history {
+		values[i] = value(s)
+	}
+	sort.Float64s(values)
+
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// rate возвращает долю part от total
+func rate(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// boolKeys возвращает множество ключей словаря
+func boolKeys(m map[string]int) map[string]bool {
+	keys := make(map[string]bool, len(m))
+	for k := range m {
+		keys[k] = true
+	}
+	return keys
+}
+
+// sortedKeys возвращает ключи множества в алфавитном порядке
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
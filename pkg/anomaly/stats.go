@@ -0,0 +1,109 @@
+package anomaly
+
+import "sync"
+
+// StageStats — показатели извлечения данных на одном этапе обхода
+type StageStats struct {
+	Items            int            `json:"items"`             // Успешно извлеченные записи
+	ValidationErrors int            `json:"validation_errors"` // Записи, не прошедшие валидацию
+	EmptyFields      map[string]int `json:"empty_fields"`      // Записи с пустым обязательным полем, по полю
+}
+
+// Stats — показатели извлечения данных за запуск
+type Stats struct {
+	Stages     map[string]*StageStats `json:"stages"`
+	Categories map[string]int         `json:"categories"` // Рецептов, найденных на странице категории, по ее URL
+}
+
+// NewStats создает пустые показатели
+func NewStats() Stats {
+	return Stats{
+		Stages:     make(map[string]*StageStats),
+		Categories: make(map[string]int),
+	}
+}
+
+// stage возвращает показатели этапа, создавая их при первом обращении
+func (s Stats) stage(name string) *StageStats {
+	st, ok := s.Stages[name]
+	if !ok {
+		st = &StageStats{EmptyFields: make(map[string]int)}
+		s.Stages[name] = st
+	}
+	return st
+}
+
+// Collector накапливает показатели текущего запуска. Методы безопасны для
+// вызова на nil-сборщике, чтобы парсеры работали и без него.
+type Collector struct {
+	mu    sync.Mutex
+	stats Stats
+}
+
+// NewCollector создает сборщик показателей
+func NewCollector() *Collector {
+	return &Collector{stats: NewStats()}
+}
+
+// Extracted учитывает успешно извлеченную запись
+func (c *Collector) Extracted(stage string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.stage(stage).Items++
+}
+
+// ValidationError учитывает запись, не прошедшую валидацию
+func (c *Collector) ValidationError(stage string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.stage(stage).ValidationErrors++
+}
+
+// EmptyField учитывает запись с пустым обязательным полем
+func (c *Collector) EmptyField(stage, field string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.stage(stage).EmptyFields[field]++
+}
+
+// CategoryYield запоминает количество рецептов, найденных на странице категории
+func (c *Collector) CategoryYield(categoryURL string, recipes int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Categories[categoryURL] = recipes
+}
+
+// Snapshot возвращает копию накопленных показателей
+func (c *Collector) Snapshot() Stats {
+	snapshot := NewStats()
+	if c == nil {
+		return snapshot
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, st := range c.stats.Stages {
+		copied := &StageStats{Items: st.Items, ValidationErrors: st.ValidationErrors, EmptyFields: make(map[string]int, len(st.EmptyFields))}
+		for field, n := range st.EmptyFields {
+			copied.EmptyFields[field] = n
+		}
+		snapshot.Stages[name] = copied
+	}
+	for url, n := range c.stats.Categories {
+		snapshot.Categories[url] = n
+	}
+	return snapshot
+}
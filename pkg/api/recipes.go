@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/seniorcat/scraper/entity"
+	"go.uber.org/zap"
+)
+
+// RecipeStore — источник сохраненных рецептов для запросов
+type RecipeStore interface {
+	Recipe(ctx context.Context, canonicalURL string) (entity.Recipe, error)
+}
+
+// scaledRecipe — ответ эндпоинта пересчета рецепта
+type scaledRecipe struct {
+	URL         string              `json:"url"`
+	Name        string              `json:"name"`
+	Servings    int                 `json:"servings,omitempty"`
+	Ingredients []entity.Ingredient `json:"ingredients"`
+}
+
+// SetRecipeStore регистрирует эндпоинт пересчета рецепта:
+// GET /recipes/scale?url=<канонический URL>[&servings=N][&grams=true].
+// notFound — ошибка хранилища, означающая отсутствие рецепта.
+func (s *Server) SetRecipeStore(store RecipeStore, notFound error) {
+	s.mux.HandleFunc("GET /recipes/scale", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		recipe, err := store.Recipe(r.Context(), query.Get("url"))
+		if errors.Is(err, notFound) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			s.Logger.Error("Failed to load recipe", zap.String("url", query.Get("url")), zap.Error(err))
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load recipe"})
+			return
+		}
+
+		// Без servings рецепт отдается на исходное число порций
+		if query.Get("servings") != "" {
+			servings, err := intParam(r, "servings")
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			recipe, err = recipe.Scale(servings)
+			if errors.Is(err, entity.ErrUnknownServings) {
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+		}
+
+		response := scaledRecipe{
+			URL:         recipe.CanonicalURL,
+			Name:        recipe.Name,
+			Servings:    recipe.Servings(),
+			Ingredients: recipe.Parsed,
+		}
+		if grams, _ := strconv.ParseBool(query.Get("grams")); grams {
+			response.Ingredients = recipe.InGrams()
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+}
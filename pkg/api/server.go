@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultAddress — адрес сервера запросов по умолчанию
+const DefaultAddress = ":8080"
+
+// Server — HTTP-сервер запросов к сохраненным данным. Работает без обхода и не
+// отдает административные эндпоинты.
+type Server struct {
+	Logger *zap.Logger
+
+	httpServer *http.Server
+	mux        *http.ServeMux
+}
+
+// NewServer создает сервер запросов на заданном адресе
+func NewServer(address string, logger *zap.Logger) *Server {
+	if address == "" {
+		address = DefaultAddress
+	}
+
+	s := &Server{
+		Logger: logger,
+		mux:    http.NewServeMux(),
+	}
+	s.httpServer = &http.Server{
+		Addr:              address,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Start открывает порт и запускает обслуживание запросов в отдельной горутине.
+// Ошибка занятого порта возвращается сразу.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.Logger.Error("Query server stopped", zap.Error(err))
+		}
+	}()
+
+	s.Logger.Info("Query server started", zap.String("address", listener.Addr().String()))
+	return nil
+}
+
+// Shutdown корректно останавливает сервер, дожидаясь завершения активных запросов
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// writeJSON записывает ответ в формате JSON
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// intParam читает целочисленный параметр запроса
+func intParam(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s parameter %q", name, raw)
+	}
+	return value, nil
+}
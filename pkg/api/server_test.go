@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// serve выполняет запрос к обработчикам сервера без открытия порта
+func serve(s *Server, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+// errNoRecipe — ошибка отсутствия рецепта в тестовом хранилище
+var errNoRecipe = errors.New("recipe not found")
+
+// fakeRecipeStore отдает рецепты из памяти
+type fakeRecipeStore map[string]entity.Recipe
+
+func (s fakeRecipeStore) Recipe(_ context.Context, canonicalURL string) (entity.Recipe, error) {
+	recipe, ok := s[canonicalURL]
+	if !ok {
+		return entity.Recipe{}, errNoRecipe
+	}
+	return recipe, nil
+}
+
+// TestServerRecipeScale проверяет пересчет рецепта через API
+func TestServerRecipeScale(t *testing.T) {
+	s := NewServer("", zap.NewNop())
+	s.SetRecipeStore(fakeRecipeStore{
+		"https://example.com/recipes/1": {
+			Name:         "Блины",
+			CanonicalURL: "https://example.com/recipes/1",
+			Parsed: []entity.Ingredient{
+				{Name: "муки", Canonical: "мука пшеничная", Quantity: 1, Unit: "стакан"},
+				{Name: "молока", Canonical: "молоко", Quantity: 500, Unit: "мл"},
+			},
+			Details: &entity.RecipeDetails{Yield: "2 порции"},
+		},
+		"https://example.com/recipes/2": {Name: "Без порций", CanonicalURL: "https://example.com/recipes/2"},
+	}, errNoRecipe)
+
+	recorder := serve(s, "/recipes/scale?url=https://example.com/recipes/1&servings=4")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var scaled scaledRecipe
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &scaled))
+	assert.Equal(t, 4, scaled.Servings)
+	assert.Equal(t, 2.0, scaled.Ingredients[0].Quantity)
+	assert.Equal(t, 1000.0, scaled.Ingredients[1].Quantity)
+
+	// Перевод в граммы по плотности ингредиента
+	recorder = serve(s, "/recipes/scale?url=https://example.com/recipes/1&grams=true")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &scaled))
+	assert.Equal(t, "г", scaled.Ingredients[1].Unit)
+
+	assert.Equal(t, http.StatusNotFound, serve(s, "/recipes/scale?url=https://example.com/recipes/3").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(s, "/recipes/scale?url=https://example.com/recipes/1&servings=0").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(s, "/recipes/scale?url=https://example.com/recipes/2&servings=3").Code)
+}
+
+// TestServerNoAdminEndpoints проверяет, что сервер запросов не отдает административные эндпоинты
+func TestServerNoAdminEndpoints(t *testing.T) {
+	s := NewServer("", zap.NewNop())
+	s.SetRecipeStore(fakeRecipeStore{}, errNoRecipe)
+
+	for _, path := range []string{"/metrics", "/status", "/control/pause", "/debug/pprof/"} {
+		assert.Equal(t, http.StatusNotFound, serve(s, path).Code, path)
+	}
+}
@@ -0,0 +1,34 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Store — хранилище двоичных объектов по ключам вида images/ab/abcdef.jpg
+type Store interface {
+	// Put сохраняет объект; существующий объект с тем же ключом перезаписывается
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Exists проверяет наличие объекта
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// Типы хранилищ в конфигурации
+const (
+	KindFile = "file"
+	KindS3   = "s3"
+)
+
+// validKey проверяет, что ключ относительный и не выходит за пределы хранилища
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package blob
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFileStore проверяет запись и проверку наличия объектов в каталоге
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	require.NoError(t, err)
+
+	exists, err := store.Exists(ctx, "images/ab/abc.jpg")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, store.Put(ctx, "images/ab/abc.jpg", "image/jpeg", []byte("jpeg")))
+	exists, err = store.Exists(ctx, "images/ab/abc.jpg")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	data, err := os.ReadFile(filepath.Join(root, "images", "ab", "abc.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", string(data))
+
+	// Ключ не может выходить за пределы каталога
+	for _, key := range []string{"", "/etc/passwd", "../x", "images/../../x", "images//x"} {
+		assert.Error(t, store.Put(ctx, key, "", nil), key)
+	}
+}
+
+// TestSigningKey проверяет вывод ключа подписи на примере из документации AWS
+func TestSigningKey(t *testing.T) {
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}
+
+// TestS3Store проверяет запросы к S3-совместимому сервису
+func TestS3Store(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		objects = map[string]string{}
+		auth    string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		auth = r.Header.Get("Authorization")
+
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, sha256Hex(body), r.Header.Get("X-Amz-Content-Sha256"))
+			assert.Equal(t, "image/webp", r.Header.Get("Content-Type"))
+			objects[r.URL.EscapedPath()] = string(body)
+		case http.MethodHead:
+			if _, ok := objects[r.URL.EscapedPath()]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	store := NewS3Store(server.URL, "", "recipes", "minio", "minio-secret")
+	store.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, store.Put(ctx, "images/ab/a b+c.webp", "image/webp", []byte("webp")))
+	assert.Equal(t, "webp", objects["/recipes/images/ab/a%20b%2Bc.webp"])
+	assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=minio/20240501/us-east-1/s3/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="))
+
+	exists, err := store.Exists(ctx, "images/ab/a b+c.webp")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = store.Exists(ctx, "images/ab/missing.webp")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
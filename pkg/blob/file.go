@@ -0,0 +1,65 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileStore хранит объекты в каталоге локальной файловой системы
+type FileStore struct {
+	Root string
+}
+
+// NewFileStore создает хранилище в каталоге root, создавая его при необходимости
+func NewFileStore(root string) (*FileStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{Root: root}, nil
+}
+
+// Put записывает объект через временный файл, чтобы читатели не видели его частично
+func (s *FileStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.Root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Exists проверяет наличие файла объекта
+func (s *FileStore) Exists(ctx context.Context, key string) (bool, error) {
+	if err := validKey(key); err != nil {
+		return false, err
+	}
+
+	_, err := os.Stat(filepath.Join(s.Root, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
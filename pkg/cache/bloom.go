@@ -0,0 +1,310 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/seniorcat/scraper/pkg/metrics"
+)
+
+const (
+	// Значения по умолчанию для некорректных параметров фильтра
+	defaultBloomCapacity = 1 << 16
+	defaultBloomFPRate   = 0.001
+
+	// Каждый следующий фильтр вдвое вместительнее предыдущего
+	bloomGrowthFactor = 2
+	// Коэффициент ужесточения вероятности ложного срабатывания для следующих фильтров
+	bloomTighteningRatio = 0.8
+)
+
+// bloomMagic — сигнатура файла с сериализованным фильтром
+var bloomMagic = [4]byte{'S', 'B', 'F', '1'}
+
+// ErrInvalidBloomFile возвращается при чтении файла, не являющегося сохраненным фильтром
+var ErrInvalidBloomFile = errors.New("invalid bloom filter file")
+
+// BloomCache — масштабируемый фильтр Блума для множества посещенных ключей.
+// Требует памяти на порядки меньше, чем MemoryCache, но может давать ложные
+// срабатывания Exists с вероятностью не выше заданной. Когда текущий фильтр
+// заполняется, добавляется новый, большей емкости и с меньшей вероятностью ошибки.
+type BloomCache struct {
+	mu       sync.RWMutex
+	fpRate   float64
+	capacity uint64
+	filters  []*bloomFilter
+}
+
+// bloomFilter — один фильтр Блума фиксированного размера
+type bloomFilter struct {
+	bits     []uint64
+	m        uint64 // Количество бит
+	k        uint32 // Количество хеш-функций
+	capacity uint64 // Количество элементов, на которое рассчитан фильтр
+	count    uint64 // Количество добавленных элементов
+	setBits  uint64 // Количество установленных бит
+}
+
+// NewBloomCache создает фильтр, рассчитанный на capacity элементов до первого
+// расширения, с итоговой вероятностью ложного срабатывания не выше fpRate
+func NewBloomCache(capacity uint64, fpRate float64) *BloomCache {
+	if capacity == 0 {
+		capacity = defaultBloomCapacity
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = defaultBloomFPRate
+	}
+
+	b := &BloomCache{
+		fpRate:   fpRate,
+		capacity: capacity,
+	}
+	b.grow()
+	return b
+}
+
+// Set добавляет элемент в фильтр
+func (b *BloomCache) Set(key string) {
+	h1, h2 := bloomHash(key)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.exists(h1, h2) {
+		return
+	}
+
+	current := b.filters[len(b.filters)-1]
+	if current.count >= current.capacity {
+		b.grow()
+		current = b.filters[len(b.filters)-1]
+	}
+	current.add(h1, h2)
+
+	b.reportMetrics()
+}
+
+// Exists проверяет, добавлялся ли элемент в фильтр (возможны ложные срабатывания)
+func (b *BloomCache) Exists(key string) bool {
+	h1, h2 := bloomHash(key)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.exists(h1, h2)
+}
+
+// FillRatio возвращает долю установленных бит в текущем (последнем) фильтре
+func (b *BloomCache) FillRatio() float64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.filters[len(b.filters)-1].fillRatio()
+}
+
+// Save атомарно сохраняет фильтр в файл, чтобы загрузить его при следующем запуске
+func (b *BloomCache) Save(path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	b.mu.RLock()
+	err = b.encode(tmp)
+	b.mu.RUnlock()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadBloomCache загружает фильтр, ранее сохраненный методом Save
+func LoadBloomCache(path string) (*BloomCache, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	b, err := decodeBloomCache(bufio.NewReader(file))
+	if err != nil {
+		return nil, fmt.Errorf("load bloom filter %s: %w", path, err)
+	}
+
+	b.reportMetrics()
+	return b, nil
+}
+
+// exists проверяет наличие хеша во всех фильтрах; вызывается под блокировкой
+func (b *BloomCache) exists(h1, h2 uint64) bool {
+	for _, f := range b.filters {
+		if f.has(h1, h2) {
+			return true
+		}
+	}
+	return false
+}
+
+// grow добавляет новый фильтр; вызывается под блокировкой
+func (b *BloomCache) grow() {
+	i := len(b.filters)
+	capacity := b.capacity
+	for j := 0; j < i; j++ {
+		capacity *= bloomGrowthFactor
+	}
+
+	// Сумма вероятностей по всем фильтрам образует геометрический ряд,
+	// ограниченный исходной вероятностью fpRate
+	p := b.fpRate * (1 - bloomTighteningRatio) * math.Pow(bloomTighteningRatio, float64(i))
+	b.filters = append(b.filters, newBloomFilter(capacity, p))
+}
+
+// reportMetrics обновляет метрики заполненности; вызывается под блокировкой
+func (b *BloomCache) reportMetrics() {
+	metrics.BloomFillRatio.Set(b.filters[len(b.filters)-1].fillRatio())
+	metrics.BloomFilters.Set(float64(len(b.filters)))
+}
+
+// encode записывает фильтр в бинарном формате
+func (b *BloomCache) encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	header := []any{bloomMagic, math.Float64bits(b.fpRate), b.capacity, uint32(len(b.filters))}
+	for _, v := range header {
+		if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range b.filters {
+		fields := []any{f.m, f.k, f.capacity, f.count, f.setBits, f.bits}
+		for _, v := range fields {
+			if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// decodeBloomCache читает фильтр, записанный методом encode
+func decodeBloomCache(r io.Reader) (*BloomCache, error) {
+	var (
+		magic      [4]byte
+		fpBits     uint64
+		capacity   uint64
+		numFilters uint32
+	)
+	for _, v := range []any{&magic, &fpBits, &capacity, &numFilters} {
+		if err := binary.Read(r, binary.LittleEndian, v); err != nil {
+			return nil, err
+		}
+	}
+	if magic != bloomMagic || numFilters == 0 {
+		return nil, ErrInvalidBloomFile
+	}
+
+	b := &BloomCache{
+		fpRate:   math.Float64frombits(fpBits),
+		capacity: capacity,
+		filters:  make([]*bloomFilter, 0, numFilters),
+	}
+
+	for i := uint32(0); i < numFilters; i++ {
+		f := &bloomFilter{}
+		for _, v := range []any{&f.m, &f.k, &f.capacity, &f.count, &f.setBits} {
+			if err := binary.Read(r, binary.LittleEndian, v); err != nil {
+				return nil, err
+			}
+		}
+		if f.m == 0 || f.k == 0 {
+			return nil, ErrInvalidBloomFile
+		}
+		f.bits = make([]uint64, (f.m+63)/64)
+		if err := binary.Read(r, binary.LittleEndian, f.bits); err != nil {
+			return nil, err
+		}
+		b.filters = append(b.filters, f)
+	}
+
+	return b, nil
+}
+
+// newBloomFilter рассчитывает оптимальные размер и число хеш-функций для фильтра
+func newBloomFilter(capacity uint64, fpRate float64) *bloomFilter {
+	m := uint64(math.Ceil(-float64(capacity) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint32(math.Ceil(float64(m) / float64(capacity) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &bloomFilter{
+		bits:     make([]uint64, (m+63)/64),
+		m:        m,
+		k:        k,
+		capacity: capacity,
+	}
+}
+
+// add устанавливает биты элемента
+func (f *bloomFilter) add(h1, h2 uint64) {
+	for i := uint32(0); i < f.k; i++ {
+		pos := (h1 + uint64(i)*h2) % f.m
+		word, mask := pos/64, uint64(1)<<(pos%64)
+		if f.bits[word]&mask == 0 {
+			f.bits[word] |= mask
+			f.setBits++
+		}
+	}
+	f.count++
+}
+
+// has проверяет, установлены ли все биты элемента
+func (f *bloomFilter) has(h1, h2 uint64) bool {
+	for i := uint32(0); i < f.k; i++ {
+		pos := (h1 + uint64(i)*h2) % f.m
+		if f.bits[pos/64]&(uint64(1)<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// fillRatio возвращает долю установленных бит
+func (f *bloomFilter) fillRatio() float64 {
+	return float64(f.setBits) / float64(f.m)
+}
+
+// bloomHash возвращает два независимых 64-битных хеша ключа для двойного хеширования
+func bloomHash(key string) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write([]byte(key))
+	sum := h.Sum(nil)
+
+	h1 := binary.BigEndian.Uint64(sum[:8])
+	h2 := binary.BigEndian.Uint64(sum[8:])
+	// Нечетный шаг гарантирует, что позиции не зациклятся на одном бите
+	return h1, h2 | 1
+}
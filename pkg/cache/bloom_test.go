@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBloomCacheSetExists проверяет отсутствие ложноотрицательных ответов и расширение фильтра
+func TestBloomCacheSetExists(t *testing.T) {
+	bloom := NewBloomCache(100, 0.01)
+
+	for i := 0; i < 1000; i++ {
+		bloom.Set(fmt.Sprintf("https://eda.ru/recepty/%d", i))
+	}
+
+	for i := 0; i < 1000; i++ {
+		assert.True(t, bloom.Exists(fmt.Sprintf("https://eda.ru/recepty/%d", i)))
+	}
+
+	// 1000 элементов при емкости 100 требуют нескольких фильтров
+	assert.Greater(t, len(bloom.filters), 1)
+}
+
+// TestBloomCacheFalsePositiveRate проверяет, что доля ложных срабатываний не превышает заданную
+func TestBloomCacheFalsePositiveRate(t *testing.T) {
+	const fpRate = 0.01
+	bloom := NewBloomCache(1000, fpRate)
+
+	for i := 0; i < 5000; i++ {
+		bloom.Set(fmt.Sprintf("seen-%d", i))
+	}
+
+	falsePositives := 0
+	const probes = 20000
+	for i := 0; i < probes; i++ {
+		if bloom.Exists(fmt.Sprintf("unseen-%d", i)) {
+			falsePositives++
+		}
+	}
+
+	// Допускаем двукратный запас на статистический разброс
+	assert.Less(t, float64(falsePositives)/probes, fpRate*2)
+}
+
+// TestBloomCacheSaveLoad проверяет сохранение фильтра на диск и повторную загрузку
+func TestBloomCacheSaveLoad(t *testing.T) {
+	bloom := NewBloomCache(50, 0.001)
+	for i := 0; i < 200; i++ {
+		bloom.Set(fmt.Sprintf("key-%d", i))
+	}
+
+	path := filepath.Join(t.TempDir(), "cache", "seen.bloom")
+	require.NoError(t, bloom.Save(path))
+
+	loaded, err := LoadBloomCache(path)
+	require.NoError(t, err)
+
+	for i := 0; i < 200; i++ {
+		assert.True(t, loaded.Exists(fmt.Sprintf("key-%d", i)))
+	}
+	assert.Equal(t, bloom.FillRatio(), loaded.FillRatio())
+	assert.Len(t, loaded.filters, len(bloom.filters))
+}
@@ -4,6 +4,12 @@ import (
 	"sync"
 )
 
+// Cache описывает множество уже обработанных ключей
+type Cache interface {
+	Set(key string)
+	Exists(key string) bool
+}
+
 // Кеш в памяти для хранения данных
 type MemoryCache struct {
 	mu    sync.RWMutex
@@ -0,0 +1,151 @@
+package cooktime
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+)
+
+// Допуск сверки prep + cook с total: большее из абсолютного и относительного
+const (
+	ToleranceAbsolute = 5 * time.Minute
+	ToleranceRelative = 0.1
+)
+
+// isoDuration — длительность ISO 8601: P1DT2H, PT1H20M, PT0.5H
+var isoDuration = regexp.MustCompile(`(?i)^P(?:(\d+(?:[.,]\d+)?)W)?(?:(\d+(?:[.,]\d+)?)D)?(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+// isoUnits — единицы групп isoDuration по порядку
+var isoUnits = []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+// textDuration — число и единица в тексте: «1 час 20 минут», «40 мин», «полчаса», «1,5 ч»
+var textDuration = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?|полтора|полторы|пол)\s*-?\s*(\p{L}+)`)
+
+// textUnits — единицы по началу слова; проверяются по порядку, поэтому длинные
+// приставки идут раньше коротких
+var textUnits = []struct {
+	prefix string
+	unit   time.Duration
+}{
+	{"сут", 24 * time.Hour},
+	{"дн", 24 * time.Hour},
+	{"ден", 24 * time.Hour},
+	{"day", 24 * time.Hour},
+	{"час", time.Hour},
+	{"hour", time.Hour},
+	{"hr", time.Hour},
+	{"мин", time.Minute},
+	{"min", time.Minute},
+	{"сек", time.Second},
+	{"sec", time.Second},
+}
+
+// textAbbreviations — однобуквенные сокращения единиц
+var textAbbreviations = map[string]time.Duration{
+	"д": 24 * time.Hour,
+	"d": 24 * time.Hour,
+	"ч": time.Hour,
+	"h": time.Hour,
+	"м": time.Minute,
+	"m": time.Minute,
+	"с": time.Second,
+	"s": time.Second,
+}
+
+// Parse разбирает время приготовления в формате ISO 8601 (PT1H20M) или свободным
+// текстом на русском («1 час 20 минут», «40 мин», «полчаса»). В диапазоне
+// «20–30 минут» берется верхняя граница.
+func Parse(text string) (time.Duration, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, false
+	}
+
+	if match := isoDuration.FindStringSubmatch(text); match != nil {
+		var total time.Duration
+		found := false
+		for i, unit := range isoUnits {
+			if match[i+1] == "" {
+				continue
+			}
+			value, _ := parseNumber(match[i+1])
+			total += time.Duration(math.Round(value * float64(unit)))
+			found = true
+		}
+		return total, found
+	}
+
+	var total time.Duration
+	found := false
+	for _, match := range textDuration.FindAllStringSubmatch(text, -1) {
+		unit, ok := textUnit(strings.ToLower(match[2]))
+		if !ok {
+			continue
+		}
+		value, ok := parseNumber(match[1])
+		if !ok {
+			continue
+		}
+		total += time.Duration(math.Round(value * float64(unit)))
+		found = true
+	}
+	return total, found
+}
+
+// Normalize разбирает время подготовки, приготовления и общее время рецепта в
+// секунды. Если общее время не указано, оно складывается из подготовки и
+// приготовления. Возвращает false, если все три указаны и не сходятся.
+func Normalize(details *entity.RecipeDetails) bool {
+	if details == nil {
+		return true
+	}
+
+	prep, prepOK := Parse(details.PrepTime)
+	cook, cookOK := Parse(details.CookTime)
+	total, totalOK := Parse(details.TotalTime)
+	if !totalOK && (prepOK || cookOK) {
+		total, totalOK = prep+cook, true
+	}
+
+	details.PrepSeconds = int(prep / time.Second)
+	details.CookSeconds = int(cook / time.Second)
+	details.TotalSeconds = int(total / time.Second)
+	details.TimeInconsistent = prepOK && cookOK && totalOK && !Consistent(prep, cook, total)
+	return !details.TimeInconsistent
+}
+
+// Consistent проверяет, что prep + cook примерно равно total
+func Consistent(prep, cook, total time.Duration) bool {
+	tolerance := max(ToleranceAbsolute, time.Duration(float64(total)*ToleranceRelative))
+	diff := prep + cook - total
+	return diff >= -tolerance && diff <= tolerance
+}
+
+// textUnit возвращает единицу по слову
+func textUnit(word string) (time.Duration, bool) {
+	if unit, ok := textAbbreviations[word]; ok {
+		return unit, true
+	}
+	for _, u := range textUnits {
+		if strings.HasPrefix(word, u.prefix) {
+			return u.unit, true
+		}
+	}
+	return 0, false
+}
+
+// parseNumber разбирает число с точкой или запятой и словесные «пол», «полтора»
+func parseNumber(text string) (float64, bool) {
+	switch strings.ToLower(text) {
+	case "пол":
+		return 0.5, true
+	case "полтора", "полторы":
+		return 1.5, true
+	}
+	value, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+	return value, err == nil
+}
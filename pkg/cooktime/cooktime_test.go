@@ -0,0 +1,70 @@
+package cooktime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse проверяет разбор ISO 8601 и свободного текста
+func TestParse(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT1H20M":          80 * time.Minute,
+		"pt40m":            40 * time.Minute,
+		"P0DT2H":           2 * time.Hour,
+		"P1D":              24 * time.Hour,
+		"PT0.5H":           30 * time.Minute,
+		"PT90S":            90 * time.Second,
+		"1 час 20 минут":   80 * time.Minute,
+		"40 мин":           40 * time.Minute,
+		"40 мин.":          40 * time.Minute,
+		"2 часа":           2 * time.Hour,
+		"1 ч 15 м":         75 * time.Minute,
+		"1,5 часа":         90 * time.Minute,
+		"полчаса":          30 * time.Minute,
+		"полтора часа":     90 * time.Minute,
+		"20–30 минут":      30 * time.Minute,
+		"1 сутки":          24 * time.Hour,
+		"3 дня":            72 * time.Hour,
+		"45 секунд":        45 * time.Second,
+		"1 hour 5 minutes": 65 * time.Minute,
+	}
+	for text, expected := range cases {
+		duration, ok := Parse(text)
+		assert.True(t, ok, text)
+		assert.Equal(t, expected, duration, text)
+	}
+
+	for _, text := range []string{"", "PT", "P", "быстро", "4 порции"} {
+		_, ok := Parse(text)
+		assert.False(t, ok, text)
+	}
+}
+
+// TestNormalize проверяет перевод времени в секунды и сверку с общим временем
+func TestNormalize(t *testing.T) {
+	details := &entity.RecipeDetails{PrepTime: "PT15M", CookTime: "40 минут", TotalTime: "PT55M"}
+	assert.True(t, Normalize(details))
+	assert.Equal(t, 900, details.PrepSeconds)
+	assert.Equal(t, 2400, details.CookSeconds)
+	assert.Equal(t, 3300, details.TotalSeconds)
+	assert.False(t, details.TimeInconsistent)
+
+	// Общее время не указано — складывается из подготовки и приготовления
+	details = &entity.RecipeDetails{PrepTime: "10 мин", CookTime: "PT20M"}
+	assert.True(t, Normalize(details))
+	assert.Equal(t, 1800, details.TotalSeconds)
+
+	// Расхождение больше допуска
+	details = &entity.RecipeDetails{PrepTime: "PT15M", CookTime: "PT40M", TotalTime: "PT2H"}
+	assert.False(t, Normalize(details))
+	assert.True(t, details.TimeInconsistent)
+	assert.Equal(t, 7200, details.TotalSeconds)
+
+	details = &entity.RecipeDetails{Yield: "4 порции"}
+	assert.True(t, Normalize(details))
+	assert.Zero(t, details.TotalSeconds)
+	assert.True(t, Normalize(nil))
+}
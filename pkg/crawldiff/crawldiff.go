@@ -0,0 +1,234 @@
+package crawldiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Виды сравниваемых записей
+const (
+	KindCategory = "category"
+	KindRecipe   = "recipe"
+)
+
+// Форматы отчета
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// Item — запись, встреченная в запуске или сохраненная в базе
+type Item struct {
+	Kind        string `json:"kind"`
+	URL         string `json:"url"`
+	Name        string `json:"name"`
+	ContentHash string `json:"-"`
+}
+
+// Snapshot — набор записей, с которым выполняется сравнение
+type Snapshot struct {
+	Label string // Например, "run 12" или "current"
+	Items []Item
+}
+
+// Change — изменение одной записи между снимками
+type Change struct {
+	Kind    string `json:"kind"`
+	URL     string `json:"url"`
+	Name    string `json:"name"`
+	OldName string `json:"old_name,omitempty"` // Заполняется, если изменилось название
+}
+
+// Report — результат сравнения двух снимков
+type Report struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Notes   []string `json:"notes,omitempty"` // Предупреждения, например о незавершенном запуске
+	Added   []Change `json:"added"`
+	Removed []Change `json:"removed"`
+	Changed []Change `json:"changed"`
+}
+
+// Compare сравнивает снимки и возвращает добавленные, пропавшие и измененные записи
+func Compare(from, to Snapshot) Report {
+	report := Report{
+		From:    from.Label,
+		To:      to.Label,
+		Added:   []Change{},
+		Removed: []Change{},
+		Changed: []Change{},
+	}
+
+	before := index(from.Items)
+	after := index(to.Items)
+
+	for key, item := range after {
+		old, ok := before[key]
+		switch {
+		case !ok:
+			report.Added = append(report.Added, Change{Kind: item.Kind, URL: item.URL, Name: item.Name})
+		case old.ContentHash != item.ContentHash || old.Name != item.Name:
+			change := Change{Kind: item.Kind, URL: item.URL, Name: item.Name}
+			if old.Name != item.Name {
+				change.OldName = old.Name
+			}
+			report.Changed = append(report.Changed, change)
+		}
+	}
+	for key, item := range before {
+		if _, ok := after[key]; !ok {
+			report.Removed = append(report.Removed, Change{Kind: item.Kind, URL: item.URL, Name: item.Name})
+		}
+	}
+
+	sortChanges(report.Added)
+	sortChanges(report.Removed)
+	sortChanges(report.Changed)
+	return report
+}
+
+// Empty сообщает, что снимки не отличаются
+func (r Report) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// Write выводит отчет в заданном формате
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText, "":
+		return r.writeText(w)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case FormatMarkdown:
+		return r.writeMarkdown(w)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// writeText выводит отчет простым текстом
+func (r Report) writeText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Сравнение %s -> %s\n", r.From, r.To)
+	for _, note := range r.Notes {
+		fmt.Fprintf(&b, "! %s\n", note)
+	}
+	if r.Empty() {
+		b.WriteString("Изменений нет\n")
+	}
+
+	sections := []struct {
+		title   string
+		sign    string
+		changes []Change
+	}{
+		{"Добавлено", "+", r.Added},
+		{"Удалено", "-", r.Removed},
+		{"Изменено", "~", r.Changed},
+	}
+	for _, section := range sections {
+		if len(section.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n", section.title, len(section.changes))
+		for _, c := range section.changes {
+			fmt.Fprintf(&b, "  %s [%s] %s  %s%s\n", section.sign, kindTitle(c.Kind), c.Name, c.URL, renamed(c))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdown выводит отчет в Markdown для публикации
+func (r Report) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Изменения: %s → %s\n\n", r.From, r.To)
+	for _, note := range r.Notes {
+		fmt.Fprintf(&b, "> **Внимание:** %s\n\n", note)
+	}
+
+	fmt.Fprintf(&b, "| | Категории | Рецепты |\n|---|---|---|\n")
+	for _, row := range []struct {
+		title   string
+		changes []Change
+	}{{"Добавлено", r.Added}, {"Удалено", r.Removed}, {"Изменено", r.Changed}} {
+		fmt.Fprintf(&b, "| %s | %d | %d |\n", row.title, count(row.changes, KindCategory), count(row.changes, KindRecipe))
+	}
+
+	for _, section := range []struct {
+		title   string
+		changes []Change
+	}{{"Добавлено", r.Added}, {"Удалено", r.Removed}, {"Изменено", r.Changed}} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", section.title)
+		for _, c := range section.changes {
+			fmt.Fprintf(&b, "- %s: [%s](%s)%s\n", kindTitle(c.Kind), escapeMarkdown(c.Name), c.URL, escapeMarkdown(renamed(c)))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// index группирует записи по виду и URL
+func index(items []Item) map[string]Item {
+	m := make(map[string]Item, len(items))
+	for _, item := range items {
+		m[item.Kind+" "+item.URL] = item
+	}
+	return m
+}
+
+// sortChanges упорядочивает изменения: сначала категории, затем рецепты, внутри — по URL
+func sortChanges(changes []Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].URL < changes[j].URL
+	})
+}
+
+// count возвращает количество изменений заданного вида
+func count(changes []Change, kind string) int {
+	n := 0
+	for _, c := range changes {
+		if c.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// kindTitle возвращает название вида записи для отчета
+func kindTitle(kind string) string {
+	switch kind {
+	case KindCategory:
+		return "категория"
+	case KindRecipe:
+		return "рецепт"
+	}
+	return kind
+}
+
+// renamed возвращает пометку о смене названия
+func renamed(c Change) string {
+	if c.OldName == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (было: %s)", c.OldName)
+}
+
+// escapeMarkdown экранирует символы, ломающие разметку ссылок
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`, "|", `\|`).Replace(s)
+}
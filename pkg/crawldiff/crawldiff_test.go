@@ -0,0 +1,72 @@
+package crawldiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshots возвращает два запуска: борщ изменился, салат пропал, появились супы и окрошка
+func snapshots() (Snapshot, Snapshot) {
+	from := Snapshot{Label: "запуск 1", Items: []Item{
+		{Kind: KindRecipe, URL: "https://eda.ru/r/borsch", Name: "борщ", ContentHash: "a"},
+		{Kind: KindRecipe, URL: "https://eda.ru/r/salat", Name: "салат", ContentHash: "b"},
+		{Kind: KindRecipe, URL: "https://eda.ru/r/plov", Name: "плов", ContentHash: "c"},
+	}}
+	to := Snapshot{Label: "запуск 2", Items: []Item{
+		{Kind: KindRecipe, URL: "https://eda.ru/r/borsch", Name: "борщ украинский", ContentHash: "a2"},
+		{Kind: KindRecipe, URL: "https://eda.ru/r/plov", Name: "плов", ContentHash: "c"},
+		{Kind: KindRecipe, URL: "https://eda.ru/r/okroshka", Name: "окрошка", ContentHash: "d"},
+		{Kind: KindCategory, URL: "https://eda.ru/recepty/supy", Name: "супы", ContentHash: "e"},
+	}}
+	return from, to
+}
+
+// TestCompare проверяет поиск добавленных, пропавших и измененных записей
+func TestCompare(t *testing.T) {
+	report := Compare(snapshots())
+
+	require.Len(t, report.Added, 2)
+	assert.Equal(t, KindCategory, report.Added[0].Kind) // Категории идут первыми
+	assert.Equal(t, "https://eda.ru/r/okroshka", report.Added[1].URL)
+
+	require.Len(t, report.Removed, 1)
+	assert.Equal(t, "салат", report.Removed[0].Name)
+
+	require.Len(t, report.Changed, 1)
+	assert.Equal(t, "борщ украинский", report.Changed[0].Name)
+	assert.Equal(t, "борщ", report.Changed[0].OldName)
+
+	from, _ := snapshots()
+	assert.True(t, Compare(from, from).Empty())
+}
+
+// TestReportFormats проверяет вывод отчета в поддерживаемых форматах
+func TestReportFormats(t *testing.T) {
+	report := Compare(snapshots())
+	report.Notes = []string{"запуск 2 не был завершен полностью"}
+
+	var text bytes.Buffer
+	require.NoError(t, report.Write(&text, FormatText))
+	assert.Contains(t, text.String(), "+ [рецепт] окрошка")
+	assert.Contains(t, text.String(), "- [рецепт] салат")
+	assert.Contains(t, text.String(), "(было: борщ)")
+
+	var markdown bytes.Buffer
+	require.NoError(t, report.Write(&markdown, FormatMarkdown))
+	assert.Contains(t, markdown.String(), "| Добавлено | 1 | 1 |")
+	assert.Contains(t, markdown.String(), "[окрошка](https://eda.ru/r/okroshka)")
+	assert.Contains(t, markdown.String(), "**Внимание:**")
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out, FormatJSON))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Len(t, decoded.Added, 2)
+	assert.Equal(t, "запуск 1", decoded.From)
+
+	assert.Error(t, report.Write(&out, "xml"))
+}
@@ -0,0 +1,113 @@
+package dedup
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParams — параметры запроса, не влияющие на содержимое страницы
+var trackingParams = map[string]bool{
+	"gclid":     true,
+	"fbclid":    true,
+	"yclid":     true,
+	"ysclid":    true,
+	"_openstat": true,
+	"from":      true,
+	"ref":       true,
+	"utm":       true,
+}
+
+// CanonicalURL приводит ссылку к каноническому виду: абсолютный URL в нижнем регистре
+// хоста, без фрагмента, трекинговых параметров, порта по умолчанию и завершающего слеша
+func CanonicalURL(base, href string) (string, error) {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return "", fmt.Errorf("empty url")
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("parse base url %q: %w", base, err)
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return "", fmt.Errorf("parse url %q: %w", href, err)
+	}
+
+	u := baseURL.ResolveReference(ref)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported url scheme %q", u.Scheme)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Host = strings.TrimSuffix(u.Host, ":80")
+	u.Host = strings.TrimSuffix(u.Host, ":443")
+	u.Host = strings.TrimPrefix(u.Host, "www.")
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.User = nil
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	u.RawQuery = canonicalQuery(u.Query())
+
+	return u.String(), nil
+}
+
+// ResolveCanonical возвращает каноническую ссылку страницы: адрес из
+// <link rel=canonical>, если он указывает на тот же сайт, иначе адрес самой страницы
+func ResolveCanonical(pageURL, linkHref string) (string, error) {
+	page, err := CanonicalURL(pageURL, pageURL)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(linkHref) == "" {
+		return page, nil
+	}
+
+	link, err := CanonicalURL(page, linkHref)
+	if err != nil {
+		return page, nil
+	}
+
+	pageHost, _ := url.Parse(page)
+	linkHost, _ := url.Parse(link)
+	if pageHost.Host != linkHost.Host {
+		return page, nil
+	}
+
+	return link, nil
+}
+
+// canonicalQuery удаляет трекинговые параметры и сортирует оставшиеся
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		lower := strings.ToLower(key)
+		if trackingParams[lower] || strings.HasPrefix(lower, "utm_") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		vals := values[key]
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,152 @@
+package dedup
+
+import (
+	"sync"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/cache"
+)
+
+// DefaultMaxDistance — расстояние Хэмминга, до которого отпечатки считаются одинаковыми
+const DefaultMaxDistance = 3
+
+// Deduplicator находит рецепты, уже встречавшиеся в текущем обходе по каноническому
+// URL, и перепубликации одного рецепта под разными адресами по отпечатку содержимого
+type Deduplicator struct {
+	mu          sync.Mutex
+	seen        cache.Cache // Канонические URL, встреченные в текущем обходе
+	maxDistance int
+	bands       []map[uint64][]fingerprintEntry // Индекс отпечатков по полосам бит
+}
+
+// fingerprintEntry связывает отпечаток с каноническим URL рецепта
+type fingerprintEntry struct {
+	fingerprint uint64
+	url         string
+}
+
+// NewDeduplicator создает дедупликатор; seen хранит URL текущего обхода
+func NewDeduplicator(seen cache.Cache, maxDistance int) *Deduplicator {
+	if maxDistance < 0 || maxDistance > 63 {
+		maxDistance = DefaultMaxDistance
+	}
+
+	// По принципу Дирихле отпечатки с расстоянием не больше maxDistance
+	// совпадают хотя бы в одной из maxDistance+1 полос
+	bands := make([]map[uint64][]fingerprintEntry, maxDistance+1)
+	for i := range bands {
+		bands[i] = make(map[uint64][]fingerprintEntry)
+	}
+
+	return &Deduplicator{
+		seen:        seen,
+		maxDistance: maxDistance,
+		bands:       bands,
+	}
+}
+
+// Fingerprint вычисляет отпечаток содержимого рецепта по названию и ингредиентам
+func Fingerprint(recipe entity.Recipe) uint64 {
+	texts := make([]string, 0, len(recipe.Ingredients)+1)
+	texts = append(texts, recipe.Name)
+	texts = append(texts, recipe.Ingredients...)
+	return SimHash(texts...)
+}
+
+// Seed добавляет отпечаток рецепта из предыдущих обходов, не отмечая URL как встреченный
+func (d *Deduplicator) Seed(url string, fingerprint uint64) {
+	if url == "" || fingerprint == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.index(fingerprintEntry{fingerprint: fingerprint, url: url})
+}
+
+// Check проверяет, является ли рецепт дубликатом, и возвращает канонический URL оригинала.
+// Рецепт, не признанный дубликатом, запоминается.
+// Отпечаток сравнивается только для рецептов с ингредиентами: одного названия
+// недостаточно, чтобы отличить перепубликацию от другого рецепта с похожим именем.
+func (d *Deduplicator) Check(recipe entity.Recipe) (string, bool) {
+	url := recipe.CanonicalURL
+	if url == "" {
+		url = recipe.Href
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.seen.Exists(url) {
+		return url, true
+	}
+
+	useFingerprint := recipe.Fingerprint != 0 && len(recipe.Ingredients) > 0
+	if useFingerprint {
+		if original, ok := d.lookup(recipe.Fingerprint, url); ok {
+			return original, true
+		}
+	}
+
+	d.seen.Set(url)
+	if useFingerprint {
+		d.index(fingerprintEntry{fingerprint: recipe.Fingerprint, url: url})
+	}
+
+	return "", false
+}
+
+// CheckContent проверяет по отпечатку, не перепубликация ли рецепт со страницы
+// рецепта, и возвращает канонический URL оригинала. URL не проверяется: он уже
+// встретился в текущем обходе, когда рецепт нашелся в списке категории.
+func (d *Deduplicator) CheckContent(recipe entity.Recipe) (string, bool) {
+	url := recipe.CanonicalURL
+	if url == "" {
+		url = recipe.Href
+	}
+	if recipe.Fingerprint == 0 || len(recipe.Ingredients) == 0 {
+		return "", false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if original, ok := d.lookup(recipe.Fingerprint, url); ok {
+		return original, true
+	}
+	d.index(fingerprintEntry{fingerprint: recipe.Fingerprint, url: url})
+	return "", false
+}
+
+// lookup ищет близкий отпечаток рецепта с другим URL; вызывается под блокировкой
+func (d *Deduplicator) lookup(fingerprint uint64, url string) (string, bool) {
+	for i, band := range d.bands {
+		for _, entry := range band[d.bandKey(i, fingerprint)] {
+			if entry.url == url {
+				continue
+			}
+			if HammingDistance(entry.fingerprint, fingerprint) <= d.maxDistance {
+				return entry.url, true
+			}
+		}
+	}
+	return "", false
+}
+
+// index добавляет отпечаток во все полосы; вызывается под блокировкой
+func (d *Deduplicator) index(entry fingerprintEntry) {
+	for i, band := range d.bands {
+		key := d.bandKey(i, entry.fingerprint)
+		band[key] = append(band[key], entry)
+	}
+}
+
+// bandKey возвращает биты i-й полосы отпечатка
+func (d *Deduplicator) bandKey(i int, fingerprint uint64) uint64 {
+	width := 64 / len(d.bands)
+	shift := uint(i * width)
+	if i == len(d.bands)-1 {
+		return fingerprint >> shift
+	}
+	return (fingerprint >> shift) & (1<<uint(width) - 1)
+}
@@ -0,0 +1,140 @@
+package dedup
+
+import (
+	"testing"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCanonicalURL проверяет приведение разных вариантов ссылки к одному виду
+func TestCanonicalURL(t *testing.T) {
+	cases := []struct {
+		href string
+		want string
+	}{
+		{"/recepty/zavtraki/syrniki-12345", "https://eda.ru/recepty/zavtraki/syrniki-12345"},
+		{"/recepty/zavtraki/syrniki-12345/", "https://eda.ru/recepty/zavtraki/syrniki-12345"},
+		{"https://WWW.Eda.ru:443/recepty/zavtraki/syrniki-12345#comments", "https://eda.ru/recepty/zavtraki/syrniki-12345"},
+		{"/recepty/zavtraki/syrniki-12345?utm_source=tg&from=main", "https://eda.ru/recepty/zavtraki/syrniki-12345"},
+		{"/recepty?page=2&sort=new&gclid=abc", "https://eda.ru/recepty?page=2&sort=new"},
+		{"/recepty?sort=new&page=2", "https://eda.ru/recepty?page=2&sort=new"},
+		{"https://eda.ru", "https://eda.ru/"},
+	}
+
+	for _, c := range cases {
+		got, err := CanonicalURL("https://eda.ru", c.href)
+		require.NoError(t, err, c.href)
+		assert.Equal(t, c.want, got, c.href)
+	}
+
+	_, err := CanonicalURL("https://eda.ru", "javascript:void(0)")
+	assert.Error(t, err)
+}
+
+// TestResolveCanonical проверяет выбор ссылки из <link rel=canonical>
+func TestResolveCanonical(t *testing.T) {
+	got, err := ResolveCanonical("https://eda.ru/recepty/syrniki-1?utm_source=x", "/recepty/zavtraki/syrniki-1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://eda.ru/recepty/zavtraki/syrniki-1", got)
+
+	// Ссылка на другой сайт игнорируется
+	got, err = ResolveCanonical("https://eda.ru/recepty/syrniki-1", "https://example.com/syrniki")
+	require.NoError(t, err)
+	assert.Equal(t, "https://eda.ru/recepty/syrniki-1", got)
+}
+
+// TestSimHash проверяет, что похожие тексты дают близкие отпечатки
+func TestSimHash(t *testing.T) {
+	ingredients := []string{"творог 500 г", "яйцо 2 шт.", "мука 3 ст. л.", "сахар 2 ст. л.", "соль щепотка"}
+
+	a := SimHash(append([]string{"Сырники из творога"}, ingredients...)...)
+	b := SimHash(append([]string{"Сырники из творога!"}, ingredients...)...)
+	c := SimHash("Борщ с говядиной", "свекла 2 шт.", "говядина 500 г", "капуста 300 г", "картофель 3 шт.")
+
+	assert.LessOrEqual(t, HammingDistance(a, b), DefaultMaxDistance)
+	assert.Greater(t, HammingDistance(a, c), DefaultMaxDistance)
+	assert.Zero(t, SimHash(""))
+}
+
+// TestDeduplicatorCheck проверяет поиск дубликатов по URL и по отпечатку содержимого
+func TestDeduplicatorCheck(t *testing.T) {
+	d := NewDeduplicator(cache.NewMemoryCache(), DefaultMaxDistance)
+
+	original := entity.Recipe{
+		Name:         "сырники из творога",
+		CanonicalURL: "https://eda.ru/recepty/zavtraki/syrniki-1",
+		Ingredients:  []string{"творог 500 г", "яйцо 2 шт.", "мука 3 ст. л.", "сахар 2 ст. л."},
+	}
+	original.Fingerprint = Fingerprint(original)
+
+	_, duplicate := d.Check(original)
+	assert.False(t, duplicate)
+
+	// Тот же рецепт, найденный через другую категорию
+	url, duplicate := d.Check(original)
+	assert.True(t, duplicate)
+	assert.Equal(t, original.CanonicalURL, url)
+
+	// Перепубликация под новым адресом
+	repost := original
+	repost.CanonicalURL = "https://eda.ru/recepty/vypechka/syrniki-2"
+	url, duplicate = d.Check(repost)
+	assert.True(t, duplicate)
+	assert.Equal(t, original.CanonicalURL, url)
+
+	// Рецепт без ингредиентов сравнивается только по URL
+	other := entity.Recipe{Name: "сырники из творога", CanonicalURL: "https://eda.ru/recepty/zavtraki/syrniki-3"}
+	other.Fingerprint = Fingerprint(other)
+	_, duplicate = d.Check(other)
+	assert.False(t, duplicate)
+}
+
+// TestDeduplicatorSeed проверяет, что рецепт из прошлого обхода не считается дубликатом самого себя
+func TestDeduplicatorSeed(t *testing.T) {
+	d := NewDeduplicator(cache.NewMemoryCache(), DefaultMaxDistance)
+
+	recipe := entity.Recipe{
+		Name:         "борщ",
+		CanonicalURL: "https://eda.ru/recepty/supy/borsch-1",
+		Ingredients:  []string{"свекла 2 шт.", "говядина 500 г", "капуста 300 г"},
+	}
+	recipe.Fingerprint = Fingerprint(recipe)
+	d.Seed(recipe.CanonicalURL, recipe.Fingerprint)
+
+	_, duplicate := d.Check(recipe)
+	assert.False(t, duplicate)
+
+	repost := recipe
+	repost.CanonicalURL = "https://eda.ru/recepty/supy/borsch-2"
+	url, duplicate := d.Check(repost)
+	assert.True(t, duplicate)
+	assert.Equal(t, recipe.CanonicalURL, url)
+}
+
+// TestDeduplicatorCheckContent проверяет рецепты со страниц рецептов, URL которых уже встречен в списке
+func TestDeduplicatorCheckContent(t *testing.T) {
+	d := NewDeduplicator(cache.NewMemoryCache(), DefaultMaxDistance)
+
+	listing := entity.Recipe{Name: "сырники из творога", CanonicalURL: "https://eda.ru/recepty/zavtraki/syrniki-1"}
+	_, duplicate := d.Check(listing)
+	require.False(t, duplicate)
+
+	// Страница того же рецепта не считается дубликатом его карточки
+	detail := listing
+	detail.Ingredients = []string{"творог 500 г", "яйцо 2 шт.", "мука 3 ст. л.", "сахар 2 ст. л."}
+	detail.Fingerprint = Fingerprint(detail)
+	_, duplicate = d.CheckContent(detail)
+	assert.False(t, duplicate)
+	_, duplicate = d.CheckContent(detail)
+	assert.False(t, duplicate)
+
+	// Тот же состав под другим адресом — перепубликация
+	repost := detail
+	repost.CanonicalURL = "https://eda.ru/recepty/vypechka/syrniki-2"
+	url, duplicate := d.CheckContent(repost)
+	assert.True(t, duplicate)
+	assert.Equal(t, detail.CanonicalURL, url)
+}
@@ -0,0 +1,66 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// SimHash вычисляет 64-битный отпечаток текста: близкие по содержанию тексты
+// дают отпечатки с малым расстоянием Хэмминга
+func SimHash(texts ...string) uint64 {
+	var weights [64]int
+	features := 0
+
+	for _, text := range texts {
+		tokens := tokenize(text)
+		for i, token := range tokens {
+			addFeature(&weights, token)
+			// Пары соседних слов учитывают порядок слов
+			if i > 0 {
+				addFeature(&weights, tokens[i-1]+" "+token)
+			}
+			features++
+		}
+	}
+	if features == 0 {
+		return 0
+	}
+
+	var fingerprint uint64
+	for i, w := range weights {
+		if w > 0 {
+			fingerprint |= 1 << uint(i)
+		}
+	}
+	return fingerprint
+}
+
+// HammingDistance возвращает количество различающихся бит двух отпечатков
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// addFeature учитывает хеш признака в весах разрядов
+func addFeature(weights *[64]int, feature string) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	for i := range weights {
+		if sum&(1<<uint(i)) != 0 {
+			weights[i]++
+		} else {
+			weights[i]--
+		}
+	}
+}
+
+// tokenize разбивает текст на слова в нижнем регистре
+func tokenize(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,133 @@
+package diet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/ingredient"
+)
+
+// Rule — правило метки рецепта. Метка с Contains назначается, если в рецепте есть
+// любой из ингредиентов списка; метка с Excludes — если все ингредиенты рецепта
+// распознаны и ни одного из списка среди них нет.
+type Rule struct {
+	Tag      string
+	Contains []string
+	Excludes []string
+}
+
+// rule — правило с каноническими названиями ингредиентов
+type rule struct {
+	tag         string
+	ingredients map[string]bool
+	exclusive   bool // Метка по отсутствию ингредиентов
+}
+
+// Classifier назначает рецептам метки диет и аллергенов по каноническим ингредиентам
+type Classifier struct {
+	rules []rule
+}
+
+// NewClassifier создает классификатор. Ингредиенты правил могут быть записаны в
+// любой форме и сводятся к каноническим по каталогу; не найденный в каталоге
+// ингредиент — ошибка конфигурации.
+func NewClassifier(rules []Rule, catalog *ingredient.Catalog) (*Classifier, error) {
+	c := &Classifier{}
+	for _, r := range rules {
+		tag := strings.TrimSpace(r.Tag)
+		if tag == "" {
+			return nil, fmt.Errorf("diet rule without tag")
+		}
+		if (len(r.Contains) == 0) == (len(r.Excludes) == 0) {
+			return nil, fmt.Errorf("diet rule %s: exactly one of contains and excludes must be set", tag)
+		}
+
+		names := r.Contains
+		if len(r.Excludes) > 0 {
+			names = r.Excludes
+		}
+		ingredients := make(map[string]bool, len(names))
+		for _, name := range names {
+			canonical, ok := catalog.Match(name)
+			if !ok {
+				return nil, fmt.Errorf("diet rule %s: ingredient %q is not in the catalog", tag, name)
+			}
+			ingredients[canonical] = true
+		}
+
+		c.rules = append(c.rules, rule{tag: tag, ingredients: ingredients, exclusive: len(r.Excludes) > 0})
+	}
+	return c, nil
+}
+
+// Classify возвращает метки рецепта по разобранным ингредиентам в порядке правил.
+// Без разобранных ингредиентов возвращает nil. Безопасен для nil-классификатора.
+func (c *Classifier) Classify(ingredients []entity.Ingredient) []entity.RecipeTag {
+	if c == nil || len(ingredients) == 0 {
+		return nil
+	}
+
+	var canonical, unknown []string
+	for _, i := range ingredients {
+		if i.Canonical == "" {
+			unknown = append(unknown, i.Name)
+			continue
+		}
+		canonical = append(canonical, i.Canonical)
+	}
+	canonical = unique(canonical)
+
+	tags := []entity.RecipeTag{}
+	for _, r := range c.rules {
+		var matched []string
+		for _, name := range canonical {
+			if r.ingredients[name] {
+				matched = append(matched, name)
+			}
+		}
+
+		switch {
+		case !r.exclusive && len(matched) > 0:
+			tags = append(tags, entity.RecipeTag{
+				Tag:         r.tag,
+				Ingredients: matched,
+				Reason:      "содержит: " + strings.Join(matched, ", "),
+			})
+		case r.exclusive && len(matched) == 0 && len(unknown) == 0:
+			// Нераспознанный ингредиент может оказаться исключенным, поэтому метка
+			// отсутствия назначается только при полностью распознанном составе
+			tags = append(tags, entity.RecipeTag{
+				Tag:         r.tag,
+				Ingredients: canonical,
+				Reason:      "все ингредиенты распознаны, исключенных нет",
+			})
+		}
+	}
+	return tags
+}
+
+// Tags возвращает метки классификатора в порядке правил
+func (c *Classifier) Tags() []string {
+	if c == nil {
+		return nil
+	}
+	tags := make([]string, len(c.rules))
+	for i, r := range c.rules {
+		tags[i] = r.tag
+	}
+	return tags
+}
+
+// unique возвращает названия без повторов, отсортированные по алфавиту
+func unique(names []string) []string {
+	sort.Strings(names)
+	result := names[:0]
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package diet
+
+import (
+	"testing"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/ingredient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRules — правила, аналогичные конфигурации по умолчанию
+var testRules = []Rule{
+	{Tag: "vegetarian", Excludes: []string{"курица", "говядина", "бекон", "креветки"}},
+	{Tag: "lactose-free", Excludes: []string{"молоко", "сливки", "масло сливочное"}},
+	{Tag: "contains-nuts", Contains: []string{"грецкие орехи", "миндаль", "арахис"}},
+}
+
+// newTestClassifier создает классификатор по встроенному каталогу
+func newTestClassifier(t *testing.T, rules []Rule) *Classifier {
+	catalog, err := ingredient.DefaultCatalog()
+	require.NoError(t, err)
+	classifier, err := NewClassifier(rules, catalog)
+	require.NoError(t, err)
+	return classifier
+}
+
+// TestClassify проверяет назначение меток и объяснения
+func TestClassify(t *testing.T) {
+	classifier := newTestClassifier(t, testRules)
+	assert.Equal(t, []string{"vegetarian", "lactose-free", "contains-nuts"}, classifier.Tags())
+
+	tags := classifier.Classify([]entity.Ingredient{
+		{Name: "муки", Canonical: "мука пшеничная"},
+		{Name: "молока", Canonical: "молоко"},
+		{Name: "миндаля", Canonical: "миндаль"},
+		{Name: "муки для посыпки", Canonical: "мука пшеничная"},
+	})
+	assert.Equal(t, []entity.RecipeTag{
+		{Tag: "vegetarian", Ingredients: []string{"миндаль", "молоко", "мука пшеничная"}, Reason: "все ингредиенты распознаны, исключенных нет"},
+		{Tag: "contains-nuts", Ingredients: []string{"миндаль"}, Reason: "содержит: миндаль"},
+	}, tags)
+
+	// Нераспознанный ингредиент не позволяет назначить метки отсутствия
+	tags = classifier.Classify([]entity.Ingredient{
+		{Name: "муки", Canonical: "мука пшеничная"},
+		{Name: "трюфельного эскабече"},
+	})
+	assert.Empty(t, tags)
+	assert.NotNil(t, tags)
+
+	assert.Nil(t, classifier.Classify(nil))
+	var none *Classifier
+	assert.Nil(t, none.Classify([]entity.Ingredient{{Name: "соль", Canonical: "соль"}}))
+}
+
+// TestNewClassifierErrors проверяет ошибки конфигурации правил
+func TestNewClassifierErrors(t *testing.T) {
+	catalog, err := ingredient.DefaultCatalog()
+	require.NoError(t, err)
+
+	_, err = NewClassifier([]Rule{{Tag: "vegan"}}, catalog)
+	assert.Error(t, err)
+	_, err = NewClassifier([]Rule{{Tag: "vegan", Contains: []string{"мед"}, Excludes: []string{"мед"}}}, catalog)
+	assert.Error(t, err)
+	_, err = NewClassifier([]Rule{{Tag: "contains-truffle", Contains: []string{"трюфельное эскабече"}}}, catalog)
+	assert.Error(t, err)
+	_, err = NewClassifier([]Rule{{Contains: []string{"мед"}}}, catalog)
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package extract
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/seniorcat/scraper/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Названия стратегий извлечения в порядке предпочтения
+const (
+	StrategyJSONLD    = "json-ld"
+	StrategyMicrodata = "microdata"
+	StrategyRDFa      = "rdfa"
+	StrategySemantic  = "semantic"
+	StrategyCSS       = "css"
+	StrategyNone      = "none" // Ни одна стратегия не сработала
+)
+
+// Сколько элементов-кандидатов попадает в диагностический снимок
+const maxCandidates = 5
+
+// Strategy — один способ извлечь значение поля со страницы. Extract возвращает
+// false, если на странице нет данных в ожидаемом этой стратегией виде.
+type Strategy[T any] struct {
+	Name    string
+	Extract func(doc *goquery.Selection) (T, bool)
+}
+
+// Chain — упорядоченный список стратегий извлечения одного поля. Первая
+// сработавшая стратегия определяет значение; если не сработала ни одна,
+// в журнал пишется снимок элементов, похожих на искомое содержимое.
+type Chain[T any] struct {
+	Stage      string // Этап обхода для метрик
+	Field      string
+	Strategies []Strategy[T]
+	Optional   bool                                            // Поле бывает пустым и без поломки верстки; неудача пишется в журнал как отладочная
+	Candidates func(doc *goquery.Selection) *goquery.Selection // Элементы, похожие на искомые (может быть nil)
+}
+
+// Run применяет стратегии по порядку и возвращает значение первой сработавшей
+func (c Chain[T]) Run(doc *goquery.Selection, pageURL string, logger *zap.Logger) (T, bool) {
+	for _, strategy := range c.Strategies {
+		if value, ok := strategy.Extract(doc); ok {
+			metrics.ExtractionStrategy.WithLabelValues(c.Stage, c.Field, strategy.Name).Inc()
+			return value, true
+		}
+	}
+
+	metrics.ExtractionStrategy.WithLabelValues(c.Stage, c.Field, StrategyNone).Inc()
+	log := logger.Warn
+	if c.Optional {
+		log = logger.Debug
+	}
+	log("Ни одна стратегия извлечения не сработала",
+		zap.String("stage", c.Stage),
+		zap.String("field", c.Field),
+		zap.String("url", pageURL),
+		zap.Strings("strategies", c.names()),
+		zap.Strings("candidates", c.snapshot(doc)))
+
+	var zero T
+	return zero, false
+}
+
+// names возвращает названия стратегий цепочки
+func (c Chain[T]) names() []string {
+	names := make([]string, len(c.Strategies))
+	for i, strategy := range c.Strategies {
+		names[i] = strategy.Name
+	}
+	return names
+}
+
+// snapshot описывает элементы-кандидаты: путь для нового CSS-селектора и начало текста
+func (c Chain[T]) snapshot(doc *goquery.Selection) []string {
+	if c.Candidates == nil {
+		return nil
+	}
+
+	var snapshot []string
+	c.Candidates(doc).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		snapshot = append(snapshot, fmt.Sprintf("%s %q", Path(s), snippet(s.Text())))
+		return len(snapshot) < maxCandidates
+	})
+	return snapshot
+}
+
+// Path возвращает CSS-путь элемента из тегов и классов двух ближайших предков
+func Path(s *goquery.Selection) string {
+	var parts []string
+	for node := s; node.Length() > 0 && len(parts) < 3; node = node.Parent() {
+		name := goquery.NodeName(node)
+		if name == "html" || name == "body" {
+			break
+		}
+		if class, ok := node.Attr("class"); ok && strings.TrimSpace(class) != "" {
+			name += "." + strings.Join(strings.Fields(class), ".")
+		}
+		parts = append([]string{name}, parts...)
+	}
+	return strings.Join(parts, " > ")
+}
+
+// snippet сокращает текст элемента для журнала
+func snippet(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > 80 {
+		return string(runes[:80]) + "…"
+	}
+	return text
+}
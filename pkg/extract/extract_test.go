@@ -0,0 +1,149 @@
+package extract
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// parse разбирает HTML-страницу для тестов
+func parse(t *testing.T, html string) *goquery.Selection {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+	return doc.Selection
+}
+
+// textOf создает стратегию, возвращающую текст первого элемента по селектору
+func textOf(name, selector string) Strategy[string] {
+	return Strategy[string]{Name: name, Extract: func(doc *goquery.Selection) (string, bool) {
+		text := strings.TrimSpace(doc.Find(selector).First().Text())
+		return text, text != ""
+	}}
+}
+
+// TestChainFallback проверяет переход к следующей стратегии и снимок кандидатов при неудаче
+func TestChainFallback(t *testing.T) {
+	chain := Chain[string]{
+		Stage:      "recipe_detail",
+		Field:      "name",
+		Strategies: []Strategy[string]{textOf(StrategyMicrodata, `[itemprop="name"]`), textOf(StrategySemantic, "h1")},
+		Candidates: func(doc *goquery.Selection) *goquery.Selection { return doc.Find(`[class*="title"]`) },
+	}
+
+	value, ok := chain.Run(parse(t, `<h1>Борщ</h1>`), "https://eda.ru/r", zap.NewNop())
+	assert.True(t, ok)
+	assert.Equal(t, "Борщ", value)
+
+	core, logs := observer.New(zap.WarnLevel)
+	_, ok = chain.Run(parse(t, `<div class="page"><span class="recipe-title big">Щи</span></div>`), "https://eda.ru/r", zap.New(core))
+	assert.False(t, ok)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, []any{`div.page > span.recipe-title.big "Щи"`}, logs.All()[0].ContextMap()["candidates"])
+}
+
+// TestJSONLD проверяет разбор @graph, массивов и фильтрацию по типу
+func TestJSONLD(t *testing.T) {
+	doc := parse(t, `<script type="application/ld+json">{"@graph":[{"@type":"WebPage"},{"@type":["Recipe"],"name":"Борщ","image":[{"url":"/b.jpg"}]}]}</script>
+<script type="application/ld+json">not json</script>`)
+
+	recipes := JSONLD(doc, "recipe")
+	require.Len(t, recipes, 1)
+	assert.Equal(t, "Борщ", String(recipes[0]["name"]))
+	assert.Equal(t, "/b.jpg", String(recipes[0]["image"]))
+}
+
+// TestMicrodata проверяет чтение свойств из content, href и текста
+func TestMicrodata(t *testing.T) {
+	doc := parse(t, `<div itemscope itemtype="https://schema.org/Recipe">
+<span itemprop="name"> Борщ </span><meta itemprop="image" content="/b.jpg">
+<li itemprop="recipeIngredient">Свекла</li><li itemprop="recipeIngredient">Капуста</li></div>`)
+
+	scope := ItemScopes(doc, "Recipe")
+	require.Equal(t, 1, scope.Length())
+	assert.Equal(t, "Борщ", Prop(scope, "name"))
+	assert.Equal(t, "/b.jpg", Prop(scope, "image"))
+	assert.Equal(t, []string{"Свекла", "Капуста"}, Props(scope, "recipeIngredient"))
+}
+
+// TestRecipeFromMarkup проверяет, что microdata, RDFa и JSON-LD дают одинаковый рецепт
+func TestRecipeFromMarkup(t *testing.T) {
+	microdata := parse(t, `<div itemscope itemtype="https://schema.org/Recipe">
+<h1 itemprop="name">Борщ</h1>
+<img itemprop="image" src="/b.jpg">
+<span itemprop="author" itemscope itemtype="https://schema.org/Person"><span itemprop="name">Анна</span></span>
+<meta itemprop="recipeYield" content="4 порции">
+<time itemprop="cookTime" datetime="PT1H">1 час</time>
+<li itemprop="recipeIngredient">Свекла 2 шт</li><li itemprop="recipeIngredient">Капуста</li>
+<div itemprop="nutrition" itemscope itemtype="https://schema.org/NutritionInformation"><span itemprop="calories">120 ккал</span></div>
+<ol><li itemprop="recipeInstructions" itemscope itemtype="https://schema.org/HowToStep"><span itemprop="text">Нарезать  овощи</span></li>
+<li itemprop="recipeInstructions" itemscope itemtype="https://schema.org/HowToStep"><span itemprop="text">Варить</span></li></ol>
+<div itemscope itemtype="https://schema.org/Recipe"><span itemprop="name">Похожий рецепт</span></div>
+</div>`)
+
+	rdfa := parse(t, `<div vocab="https://schema.org/" typeof="Recipe">
+<h1 property="name">Борщ</h1>
+<img property="image" src="/b.jpg">
+<span property="author" typeof="Person"><span property="name">Анна</span></span>
+<meta property="recipeYield" content="4 порции">
+<time property="cookTime" datetime="PT1H">1 час</time>
+<li property="recipeIngredient">Свекла 2 шт</li><li property="schema:recipeIngredient">Капуста</li>
+<div property="nutrition" typeof="NutritionInformation"><span property="calories">120 ккал</span></div>
+<ol><li property="recipeInstructions">Нарезать овощи</li><li property="recipeInstructions">Варить</li></ol>
+</div>`)
+
+	jsonLD := parse(t, `<script type="application/ld+json">{"@context":"https://schema.org","@type":"Recipe",
+"name":"Борщ","image":{"@type":"ImageObject","url":"/b.jpg"},"author":{"@type":"Person","name":"Анна"},
+"recipeYield":"4 порции","cookTime":"PT1H","recipeIngredient":["Свекла 2 шт","Капуста"],
+"nutrition":{"@type":"NutritionInformation","calories":"120 ккал"},
+"recipeInstructions":[{"@type":"HowToSection","itemListElement":[{"@type":"HowToStep","text":"Нарезать овощи"}]},"Варить"]}</script>`)
+
+	for name, doc := range map[string]*goquery.Selection{"microdata": microdata, "rdfa": rdfa, "json-ld": jsonLD} {
+		extract := map[string]func(*goquery.Selection) (entity.Recipe, bool){
+			"microdata": RecipeFromMicrodata,
+			"rdfa":      RecipeFromRDFa,
+			"json-ld":   RecipeFromJSONLD,
+		}[name]
+
+		recipe, ok := extract(doc)
+		require.True(t, ok, name)
+		assert.Equal(t, entity.Recipe{
+			Name:        "Борщ",
+			Ingredients: []string{"Свекла 2 шт", "Капуста"},
+			Details: &entity.RecipeDetails{
+				Instructions: []string{"Нарезать овощи", "Варить"},
+				Yield:        "4 порции",
+				CookTime:     "PT1H",
+				Nutrition:    map[string]string{"calories": "120 ккал"},
+				Images:       []string{"/b.jpg"},
+				Author:       "Анна",
+			},
+		}, recipe, name)
+	}
+
+	_, ok := RecipeFromJSONLD(microdata)
+	assert.False(t, ok)
+}
+
+// TestRecipeStepImages проверяет сбор изображений шагов из HowToStep
+func TestRecipeStepImages(t *testing.T) {
+	jsonLD := parse(t, `<script type="application/ld+json">{"@type":"Recipe","name":"Борщ",
+"recipeInstructions":[{"@type":"HowToSection","itemListElement":[{"@type":"HowToStep","text":"Нарезать","image":"/s1.jpg"}]},
+{"@type":"HowToStep","text":"Варить","image":{"@type":"ImageObject","url":"/s2.jpg"}},"Подать"]}</script>`)
+	recipe, ok := RecipeFromJSONLD(jsonLD)
+	require.True(t, ok)
+	assert.Equal(t, []string{"/s1.jpg", "/s2.jpg"}, recipe.Details.StepImages)
+
+	microdata := parse(t, `<div itemscope itemtype="https://schema.org/Recipe"><h1 itemprop="name">Борщ</h1>
+<div itemprop="recipeInstructions" itemscope itemtype="https://schema.org/HowToStep"><img itemprop="image" src="/s1.jpg"><span itemprop="text">Нарезать</span></div>
+<div itemprop="recipeInstructions" itemscope itemtype="https://schema.org/HowToStep"><span itemprop="text">Варить</span></div>
+</div>`)
+	recipe, ok = RecipeFromMicrodata(microdata)
+	require.True(t, ok)
+	assert.Equal(t, []string{"/s1.jpg"}, recipe.Details.StepImages)
+}
@@ -0,0 +1,129 @@
+package extract
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Syntax описывает атрибуты разметки элементов schema.org в HTML
+type Syntax struct {
+	Scope    string // Атрибут элемента, начинающего вложенный объект
+	Type     string // Атрибут с типом объекта
+	Property string // Атрибут с названием свойства
+}
+
+// Поддерживаемые виды разметки
+var (
+	Microdata = Syntax{Scope: "itemscope", Type: "itemtype", Property: "itemprop"}
+	RDFa      = Syntax{Scope: "typeof", Type: "typeof", Property: "property"}
+)
+
+// Item — объект schema.org, собранный из атрибутов разметки
+type Item struct {
+	Types []string // Типы без пространства имен, например Recipe
+	Props map[string][]Value
+}
+
+// Value — значение свойства: строка или вложенный объект
+type Value struct {
+	Text string
+	Item *Item
+}
+
+// Items возвращает объекты заданного типа из разметки страницы
+func Items(doc *goquery.Selection, syntax Syntax, itemType string) []*Item {
+	var items []*Item
+	doc.Find("[" + syntax.Scope + "]").Each(func(i int, s *goquery.Selection) {
+		item := parseItem(s, syntax)
+		if item.Is(itemType) {
+			items = append(items, item)
+		}
+	})
+	return items
+}
+
+// Is сообщает, относится ли объект к типу
+func (it *Item) Is(itemType string) bool {
+	for _, t := range it.Types {
+		if strings.EqualFold(t, itemType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Text возвращает первое непустое строковое значение свойства; у вложенного
+// объекта берется его name, url или text
+func (it *Item) Text(name string) string {
+	for _, value := range it.Texts(name) {
+		return value
+	}
+	return ""
+}
+
+// Texts возвращает непустые строковые значения свойства
+func (it *Item) Texts(name string) []string {
+	var texts []string
+	for _, value := range it.Props[name] {
+		text := value.Text
+		if value.Item != nil {
+			text = firstText(value.Item, "name", "url", "contentUrl", "text")
+		}
+		if text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// firstText возвращает первое непустое значение из свойств объекта
+func firstText(item *Item, names ...string) string {
+	for _, name := range names {
+		for _, value := range item.Props[name] {
+			if value.Text != "" {
+				return value.Text
+			}
+		}
+	}
+	return ""
+}
+
+// parseItem собирает свойства объекта, не заходя внутрь вложенных объектов
+func parseItem(scope *goquery.Selection, syntax Syntax) *Item {
+	item := &Item{Props: make(map[string][]Value)}
+	for _, t := range strings.Fields(scope.AttrOr(syntax.Type, "")) {
+		item.Types = append(item.Types, localName(t))
+	}
+
+	var walk func(parent *goquery.Selection)
+	walk = func(parent *goquery.Selection) {
+		parent.Children().Each(func(i int, child *goquery.Selection) {
+			_, nested := child.Attr(syntax.Scope)
+			if names, ok := child.Attr(syntax.Property); ok {
+				value := Value{Text: PropValue(child)}
+				if nested {
+					value = Value{Item: parseItem(child, syntax)}
+				}
+				for _, name := range strings.Fields(names) {
+					item.Props[localName(name)] = append(item.Props[localName(name)], value)
+				}
+			}
+			// Свойства вложенного объекта принадлежат ему, а не внешнему
+			if !nested {
+				walk(child)
+			}
+		})
+	}
+	walk(scope)
+
+	return item
+}
+
+// localName убирает из типа или свойства пространство имен: schema:name, https://schema.org/name
+func localName(name string) string {
+	if i := strings.LastIndexAny(name, "/:#"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
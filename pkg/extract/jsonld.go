@@ -0,0 +1,99 @@
+package extract
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// JSONLD возвращает объекты schema.org из блоков application/ld+json заданных
+// типов; массивы и @graph разворачиваются, некорректные блоки пропускаются
+func JSONLD(doc *goquery.Selection, types ...string) []map[string]any {
+	var objects []map[string]any
+	doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var data any
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			return
+		}
+		for _, object := range flatten(data) {
+			if hasType(object, types) {
+				objects = append(objects, object)
+			}
+		}
+	})
+	return objects
+}
+
+// flatten разворачивает массивы и @graph в список объектов
+func flatten(data any) []map[string]any {
+	var objects []map[string]any
+	switch v := data.(type) {
+	case []any:
+		for _, item := range v {
+			objects = append(objects, flatten(item)...)
+		}
+	case map[string]any:
+		objects = append(objects, v)
+		if graph, ok := v["@graph"]; ok {
+			objects = append(objects, flatten(graph)...)
+		}
+	}
+	return objects
+}
+
+// hasType проверяет @type объекта; пустой список типов подходит к любому объекту
+func hasType(object map[string]any, types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range Strings(object["@type"]) {
+		for _, want := range types {
+			if strings.EqualFold(t, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String возвращает строковое значение свойства: саму строку, первый элемент
+// массива или url, @id, name вложенного объекта
+func String(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		for _, item := range v {
+			if s := String(item); s != "" {
+				return s
+			}
+		}
+	case map[string]any:
+		for _, key := range []string{"url", "@id", "name"} {
+			if s := String(v[key]); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// Strings возвращает непустые строковые значения свойства
+func Strings(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		items = []any{value}
+	}
+
+	var values []string
+	for _, item := range items {
+		if s := String(item); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
@@ -0,0 +1,56 @@
+package extract
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ItemScopes возвращает элементы microdata с itemtype, оканчивающимся на заданный тип schema.org
+func ItemScopes(doc *goquery.Selection, itemType string) *goquery.Selection {
+	return doc.Find("[itemscope][itemtype]").FilterFunction(func(i int, s *goquery.Selection) bool {
+		for _, t := range strings.Fields(s.AttrOr("itemtype", "")) {
+			if strings.HasSuffix(strings.TrimRight(t, "/"), "/"+itemType) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Prop возвращает первое непустое значение свойства microdata внутри элемента
+func Prop(scope *goquery.Selection, name string) string {
+	values := Props(scope, name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Props возвращает непустые значения свойства microdata внутри элемента
+func Props(scope *goquery.Selection, name string) []string {
+	var values []string
+	scope.Find("[itemprop]").Each(func(i int, s *goquery.Selection) {
+		for _, prop := range strings.Fields(s.AttrOr("itemprop", "")) {
+			if prop != name {
+				continue
+			}
+			if value := PropValue(s); value != "" {
+				values = append(values, value)
+			}
+			return
+		}
+	})
+	return values
+}
+
+// PropValue возвращает значение элемента со свойством microdata или RDFa: атрибут
+// content, datetime, href, src или resource, иначе текст
+func PropValue(s *goquery.Selection) string {
+	for _, attr := range []string{"content", "datetime", "href", "src", "resource"} {
+		if value, ok := s.Attr(attr); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return strings.Join(strings.Fields(s.Text()), " ")
+}
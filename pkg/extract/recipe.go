@@ -0,0 +1,224 @@
+package extract
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/seniorcat/scraper/entity"
+)
+
+// RecipeFromJSONLD извлекает рецепт из объекта Recipe в JSON-LD
+func RecipeFromJSONLD(doc *goquery.Selection) (entity.Recipe, bool) {
+	for _, object := range JSONLD(doc, "Recipe") {
+		recipe := entity.Recipe{
+			Name:        String(object["name"]),
+			Ingredients: Strings(firstNonNil(object["recipeIngredient"], object["ingredients"])),
+			Details: &entity.RecipeDetails{
+				Instructions: jsonLDInstructions(object["recipeInstructions"]),
+				Yield:        String(object["recipeYield"]),
+				PrepTime:     String(object["prepTime"]),
+				CookTime:     String(object["cookTime"]),
+				TotalTime:    String(object["totalTime"]),
+				Nutrition:    jsonLDNutrition(object["nutrition"]),
+				Images:       Strings(object["image"]),
+				StepImages:   jsonLDStepImages(object["recipeInstructions"]),
+				Author:       jsonLDName(object["author"]),
+			},
+		}
+		if complete(recipe) {
+			return recipe, true
+		}
+	}
+	return entity.Recipe{}, false
+}
+
+// RecipeFromMicrodata извлекает рецепт из элемента Recipe в разметке microdata
+func RecipeFromMicrodata(doc *goquery.Selection) (entity.Recipe, bool) {
+	return recipeFromItems(Items(doc, Microdata, "Recipe"))
+}
+
+// RecipeFromRDFa извлекает рецепт из элемента Recipe в разметке RDFa
+func RecipeFromRDFa(doc *goquery.Selection) (entity.Recipe, bool) {
+	return recipeFromItems(Items(doc, RDFa, "Recipe"))
+}
+
+// recipeFromItems возвращает первый рецепт с названием или ингредиентами
+func recipeFromItems(items []*Item) (entity.Recipe, bool) {
+	for _, item := range items {
+		recipe := entity.Recipe{
+			Name:        item.Text("name"),
+			Ingredients: append(item.Texts("recipeIngredient"), item.Texts("ingredients")...),
+			Details: &entity.RecipeDetails{
+				Instructions: itemInstructions(item.Props["recipeInstructions"]),
+				Yield:        item.Text("recipeYield"),
+				PrepTime:     item.Text("prepTime"),
+				CookTime:     item.Text("cookTime"),
+				TotalTime:    item.Text("totalTime"),
+				Images:       item.Texts("image"),
+				StepImages:   itemStepImages(item.Props["recipeInstructions"]),
+				Author:       item.Text("author"),
+			},
+		}
+		for _, value := range item.Props["nutrition"] {
+			if value.Item != nil {
+				recipe.Details.Nutrition = itemNutrition(value.Item)
+				break
+			}
+		}
+		if complete(recipe) {
+			return recipe, true
+		}
+	}
+	return entity.Recipe{}, false
+}
+
+// complete отсеивает пустые объекты Recipe, например вложенные ссылки на другие рецепты
+func complete(recipe entity.Recipe) bool {
+	return recipe.Name != "" || len(recipe.Ingredients) > 0
+}
+
+// itemInstructions разворачивает шаги HowToStep и разделы HowToSection
+func itemInstructions(values []Value) []string {
+	var steps []string
+	for _, value := range values {
+		switch {
+		case value.Item == nil:
+			steps = appendStep(steps, value.Text)
+		case value.Item.Is("HowToSection"):
+			steps = append(steps, itemInstructions(value.Item.Props["itemListElement"])...)
+		default:
+			steps = appendStep(steps, firstText(value.Item, "text", "name", "description"))
+		}
+	}
+	return steps
+}
+
+// itemStepImages собирает изображения шагов HowToStep, в том числе внутри HowToSection
+func itemStepImages(values []Value) []string {
+	var images []string
+	for _, value := range values {
+		switch {
+		case value.Item == nil:
+		case value.Item.Is("HowToSection"):
+			images = append(images, itemStepImages(value.Item.Props["itemListElement"])...)
+		default:
+			images = append(images, value.Item.Texts("image")...)
+		}
+	}
+	return images
+}
+
+// itemNutrition возвращает строковые свойства NutritionInformation
+func itemNutrition(item *Item) map[string]string {
+	nutrition := make(map[string]string)
+	for name := range item.Props {
+		if text := item.Text(name); text != "" {
+			nutrition[name] = text
+		}
+	}
+	if len(nutrition) == 0 {
+		return nil
+	}
+	return nutrition
+}
+
+// jsonLDInstructions разворачивает шаги из строки, массива строк, HowToStep и HowToSection
+func jsonLDInstructions(value any) []string {
+	var steps []string
+	switch v := value.(type) {
+	case string:
+		steps = appendStep(steps, v)
+	case []any:
+		for _, item := range v {
+			steps = append(steps, jsonLDInstructions(item)...)
+		}
+	case map[string]any:
+		if hasType(v, []string{"HowToSection"}) {
+			return jsonLDInstructions(v["itemListElement"])
+		}
+		steps = appendStep(steps, firstNonEmptyString(String(v["text"]), String(v["name"]), String(v["description"])))
+	}
+	return steps
+}
+
+// jsonLDStepImages собирает изображения шагов HowToStep, в том числе внутри HowToSection
+func jsonLDStepImages(value any) []string {
+	var images []string
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			images = append(images, jsonLDStepImages(item)...)
+		}
+	case map[string]any:
+		if hasType(v, []string{"HowToSection"}) {
+			return jsonLDStepImages(v["itemListElement"])
+		}
+		images = append(images, Strings(v["image"])...)
+	}
+	return images
+}
+
+// jsonLDNutrition возвращает строковые свойства объекта NutritionInformation
+func jsonLDNutrition(value any) map[string]string {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	nutrition := make(map[string]string)
+	for name, v := range object {
+		if strings.HasPrefix(name, "@") {
+			continue
+		}
+		if text := String(v); text != "" {
+			nutrition[name] = text
+		}
+	}
+	if len(nutrition) == 0 {
+		return nil
+	}
+	return nutrition
+}
+
+// jsonLDName возвращает имя автора: строку или name объекта Person/Organization
+func jsonLDName(value any) string {
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			if name := jsonLDName(item); name != "" {
+				return name
+			}
+		}
+	case map[string]any:
+		return String(v["name"])
+	}
+	return String(value)
+}
+
+// appendStep добавляет непустой шаг с нормализованными пробелами
+func appendStep(steps []string, step string) []string {
+	if step = strings.Join(strings.Fields(step), " "); step != "" {
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// firstNonNil возвращает первое заданное значение
+func firstNonNil(values ...any) any {
+	for _, value := range values {
+		if value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// firstNonEmptyString возвращает первую непустую строку
+func firstNonEmptyString(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
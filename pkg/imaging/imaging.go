@@ -0,0 +1,139 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Регистрация декодеров форматов
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"sort"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/blob"
+	"github.com/seniorcat/scraper/pkg/phash"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Параметры обработки по умолчанию
+var (
+	DefaultWidths  = []int{320, 640}
+	DefaultQuality = 85
+)
+
+// MaxPixels — предел площади изображения: больший файл не декодируется, чтобы
+// не исчерпать память на уменьшенных копиях
+const MaxPixels = 40_000_000
+
+// Processor сохраняет изображения в хранилище и создает уменьшенные копии.
+// Ключи строятся по хешу содержимого, поэтому одно изображение хранится один раз.
+type Processor struct {
+	Store   blob.Store
+	Widths  []int // Ширины уменьшенных копий
+	Quality int   // Качество JPEG уменьшенных копий
+}
+
+// NewProcessor создает обработчик с ширинами уменьшенных копий; пустой список — значения по умолчанию
+func NewProcessor(store blob.Store, widths []int) *Processor {
+	if len(widths) == 0 {
+		widths = DefaultWidths
+	}
+	widths = append([]int(nil), widths...)
+	sort.Ints(widths)
+	return &Processor{Store: store, Widths: widths, Quality: DefaultQuality}
+}
+
+// Process определяет тип, размеры, хеш содержимого и перцептивные хеши изображения,
+// сохраняет оригинал и уменьшенные копии. Копии не создаются для ширин не меньше исходной.
+func (p *Processor) Process(ctx context.Context, data []byte) (entity.RecipeImage, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return entity.RecipeImage{}, fmt.Errorf("decode image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return entity.RecipeImage{}, fmt.Errorf("image size %dx%d is out of range", config.Width, config.Height)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	stored := entity.RecipeImage{
+		ContentHash: hash,
+		MimeType:    http.DetectContentType(data),
+		Width:       config.Width,
+		Height:      config.Height,
+		Size:        len(data),
+		Key:         fmt.Sprintf("images/%s/%s.%s", hash[:2], hash, extension(format)),
+	}
+
+	if err := p.putOnce(ctx, stored.Key, stored.MimeType, func() ([]byte, error) { return data, nil }); err != nil {
+		return entity.RecipeImage{}, err
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return entity.RecipeImage{}, fmt.Errorf("decode image: %w", err)
+	}
+	hashes := phash.Compute(source)
+	stored.AHash, stored.DHash, stored.PHash = hashes.Average, hashes.Difference, hashes.Perceptual
+
+	for _, width := range p.Widths {
+		if width <= 0 || width >= config.Width {
+			continue
+		}
+		thumbnail := entity.Thumbnail{
+			Width:  width,
+			Height: max(1, config.Height*width/config.Width),
+			Key:    fmt.Sprintf("thumbnails/%d/%s/%s.jpg", width, hash[:2], hash),
+		}
+
+		err := p.putOnce(ctx, thumbnail.Key, "image/jpeg", func() ([]byte, error) {
+			return p.resize(source, thumbnail.Width, thumbnail.Height)
+		})
+		if err != nil {
+			return entity.RecipeImage{}, err
+		}
+		stored.Thumbnails = append(stored.Thumbnails, thumbnail)
+	}
+	return stored, nil
+}
+
+// putOnce сохраняет объект, если его еще нет в хранилище; данные готовятся только при записи
+func (p *Processor) putOnce(ctx context.Context, key, contentType string, data func() ([]byte, error)) error {
+	exists, err := p.Store.Exists(ctx, key)
+	if err != nil || exists {
+		return err
+	}
+	body, err := data()
+	if err != nil {
+		return err
+	}
+	return p.Store.Put(ctx, key, contentType, body)
+}
+
+// resize уменьшает изображение и кодирует его в JPEG; прозрачность заменяется белым фоном
+func (p *Processor) resize(source image.Image, width, height int) ([]byte, error) {
+	target := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(target, target.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(target, target.Bounds(), source, source.Bounds(), draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, target, &jpeg.Options{Quality: p.Quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// extension возвращает расширение файла по имени формата декодера
+func extension(format string) string {
+	if format == "jpeg" {
+		return "jpg"
+	}
+	return format
+}
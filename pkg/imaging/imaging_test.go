@@ -0,0 +1,92 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seniorcat/scraper/pkg/blob"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore считает записи в хранилище
+type countingStore struct {
+	blob.Store
+	puts int
+}
+
+func (s *countingStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	s.puts++
+	return s.Store.Put(ctx, key, contentType, data)
+}
+
+// testPNG создает PNG заданного размера
+func testPNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, x%height, color.NRGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// TestProcess проверяет метаданные, ключи и уменьшенные копии
+func TestProcess(t *testing.T) {
+	ctx := context.Background()
+	files, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	store := &countingStore{Store: files}
+	processor := NewProcessor(store, []int{640, 320, 1200})
+
+	data := testPNG(t, 800, 400)
+	stored, err := processor.Process(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", stored.MimeType)
+	assert.Equal(t, 800, stored.Width)
+	assert.Equal(t, 400, stored.Height)
+	assert.Equal(t, len(data), stored.Size)
+	assert.Len(t, stored.ContentHash, 64)
+	assert.Equal(t, "images/"+stored.ContentHash[:2]+"/"+stored.ContentHash+".png", stored.Key)
+	assert.NotZero(t, stored.PHash)
+	assert.NotZero(t, stored.DHash)
+
+	// Копия шире оригинала не создается
+	require.Len(t, stored.Thumbnails, 2)
+	assert.Equal(t, 320, stored.Thumbnails[0].Width)
+	assert.Equal(t, 160, stored.Thumbnails[0].Height)
+	assert.Equal(t, 640, stored.Thumbnails[1].Width)
+	assert.Equal(t, 3, store.puts)
+
+	for _, thumbnail := range stored.Thumbnails {
+		exists, err := files.Exists(ctx, thumbnail.Key)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	}
+	thumbnailData, err := readFile(files, stored.Thumbnails[0].Key)
+	require.NoError(t, err)
+	decoded, err := jpeg.Decode(bytes.NewReader(thumbnailData))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 320, 160), decoded.Bounds())
+
+	// Повторная обработка того же содержимого ничего не записывает
+	again, err := processor.Process(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, stored, again)
+	assert.Equal(t, 3, store.puts)
+
+	_, err = processor.Process(ctx, []byte("<html>not an image</html>"))
+	assert.Error(t, err)
+}
+
+// readFile читает объект файлового хранилища
+func readFile(store *blob.FileStore, key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(store.Root, filepath.FromSlash(key)))
+}
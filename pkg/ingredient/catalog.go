@@ -0,0 +1,149 @@
+package ingredient
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/seniorcat/scraper/entity"
+	"gopkg.in/yaml.v2"
+)
+
+// bundledCatalog — встроенный каталог ингредиентов
+//
+//go:embed catalog.yaml
+var bundledCatalog []byte
+
+// Entry — канонический ингредиент и его синонимы
+type Entry struct {
+	Name     string   `yaml:"name"`
+	Synonyms []string `yaml:"synonyms"`
+}
+
+// Catalog сопоставляет названия ингредиентов каноническим по основам слов
+type Catalog struct {
+	forms []form // Формы названий; сначала содержащие больше слов
+}
+
+// form — название или синоним в виде набора основ
+type form struct {
+	lemmas    []string
+	canonical string
+}
+
+// NewCatalog создает каталог из записей; записи с одинаковым названием объединяются
+func NewCatalog(entries []Entry) *Catalog {
+	c := &Catalog{}
+	for _, entry := range entries {
+		canonical := strings.ToLower(strings.TrimSpace(entry.Name))
+		if canonical == "" {
+			continue
+		}
+		for _, name := range append([]string{canonical}, entry.Synonyms...) {
+			if lemmas := Lemmas(name); len(lemmas) > 0 {
+				c.forms = append(c.forms, form{lemmas: lemmas, canonical: canonical})
+			}
+		}
+	}
+
+	// Более полное совпадение важнее: «масло сливочное» раньше «масла»
+	sort.SliceStable(c.forms, func(i, j int) bool {
+		return len(c.forms[i].lemmas) > len(c.forms[j].lemmas)
+	})
+	return c
+}
+
+// DefaultCatalog создает каталог из встроенного файла
+func DefaultCatalog() (*Catalog, error) {
+	return LoadCatalog("", nil)
+}
+
+// LoadCatalog создает каталог из встроенного файла, дополнительного файла (если
+// путь задан) и синонимов из конфигурации
+func LoadCatalog(path string, synonyms map[string][]string) (*Catalog, error) {
+	var entries []Entry
+	if err := yaml.Unmarshal(bundledCatalog, &entries); err != nil {
+		return nil, fmt.Errorf("bundled ingredient catalog: %w", err)
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var extra []Entry
+		if err := yaml.Unmarshal(data, &extra); err != nil {
+			return nil, fmt.Errorf("ingredient catalog %s: %w", path, err)
+		}
+		entries = append(entries, extra...)
+	}
+
+	// Порядок ключей карты случаен, поэтому записи из конфигурации сортируются
+	names := make([]string, 0, len(synonyms))
+	for name := range synonyms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entries = append(entries, Entry{Name: name, Synonyms: synonyms[name]})
+	}
+
+	return NewCatalog(entries), nil
+}
+
+// Match возвращает канонический ингредиент для названия. Подходит форма, все
+// основы которой есть в названии; из подходящих выбирается самая полная.
+func (c *Catalog) Match(name string) (string, bool) {
+	lemmas := Lemmas(name)
+	if len(lemmas) == 0 {
+		return "", false
+	}
+
+	present := make(map[string]bool, len(lemmas))
+	for _, lemma := range lemmas {
+		present[lemma] = true
+	}
+
+	for _, f := range c.forms {
+		if containsAll(present, f.lemmas) {
+			return f.canonical, true
+		}
+	}
+	return "", false
+}
+
+// Link заполняет канонические названия разобранных ингредиентов. Безопасен для nil-каталога.
+func (c *Catalog) Link(ingredients []entity.Ingredient) {
+	if c == nil {
+		return
+	}
+	for i := range ingredients {
+		ingredients[i].Canonical, _ = c.Match(ingredients[i].Name)
+	}
+}
+
+// Names возвращает канонические названия каталога по алфавиту
+func (c *Catalog) Names() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, f := range c.forms {
+		if !seen[f.canonical] {
+			seen[f.canonical] = true
+			names = append(names, f.canonical)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// containsAll проверяет, что все основы формы есть в названии
+func containsAll(present map[string]bool, lemmas []string) bool {
+	for _, lemma := range lemmas {
+		if !present[lemma] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package ingredient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLemma проверяет сведение словоформ к общей основе
+func TestLemma(t *testing.T) {
+	assert.Equal(t, Lemma("яйца"), Lemma("яйцо"))
+	assert.Equal(t, Lemma("Сливочного"), Lemma("сливочное"))
+	assert.Equal(t, Lemma("свёклы"), Lemma("свекла"))
+	assert.Equal(t, "лук", Lemma("лук"))
+	assert.Equal(t, []string{"какао-порошок"}, Lemmas("какао-порошок"))
+}
+
+// TestCatalogMatch проверяет сопоставление словоформ и синонимов каноническому ингредиенту
+func TestCatalogMatch(t *testing.T) {
+	catalog, err := DefaultCatalog()
+	require.NoError(t, err)
+
+	cases := map[string]string{
+		"яйца":                    "яйцо куриное",
+		"Яйцо куриное":            "яйцо куриное",
+		"яиц":                     "яйцо куриное",
+		"творога 5%":              "творог",
+		"сливочного масла":        "масло сливочное",
+		"масло растительное":      "масло растительное",
+		"лук зелёный":             "лук зеленый",
+		"луковицы":                "лук репчатый",
+		"перец черный молотый":    "перец черный молотый",
+		"Свёклы среднего размера": "свекла",
+	}
+	for name, want := range cases {
+		got, ok := catalog.Match(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, ok := catalog.Match("трюфельное эскабече")
+	assert.False(t, ok)
+}
+
+// TestLoadCatalogExtensions проверяет дополнение встроенного каталога файлом и конфигурацией
+func TestLoadCatalogExtensions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "extra.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- name: тахини\n  synonyms: [кунжутная паста]\n"), 0o644))
+
+	catalog, err := LoadCatalog(path, map[string][]string{"творог": {"зерненый творог"}, "рикотта": nil})
+	require.NoError(t, err)
+
+	ingredients := []entity.Ingredient{{Name: "кунжутной пасты"}, {Name: "рикотты"}, {Name: "неизвестное"}}
+	catalog.Link(ingredients)
+	assert.Equal(t, "тахини", ingredients[0].Canonical)
+	assert.Equal(t, "рикотта", ingredients[1].Canonical)
+	assert.Empty(t, ingredients[2].Canonical)
+	assert.Contains(t, catalog.Names(), "тахини")
+
+	// Nil-каталог ничего не меняет
+	var empty *Catalog
+	empty.Link(ingredients)
+}
@@ -0,0 +1,47 @@
+package ingredient
+
+import (
+	"strings"
+	"unicode"
+)
+
+// endings — окончания существительных и прилагательных, от длинных к коротким
+var endings = []string{
+	"иями", "ями", "ами", "ого", "его", "ому", "ему", "ыми", "ими", "ией",
+	"ой", "ей", "ий", "ый", "ая", "яя", "ое", "ее", "ые", "ие", "ую", "юю",
+	"ов", "ев", "ам", "ям", "ах", "ях", "ом", "ем", "ью",
+	"а", "я", "о", "е", "ы", "и", "у", "ю", "ь", "й",
+}
+
+// minStem — минимальная длина основы в буквах, короче которой окончание не отсекается
+const minStem = 3
+
+// Lemma приводит слово к основе: нижний регистр, ё заменяется на е, отсекается
+// окончание. Беглые гласные («яйцо» — «яиц») не восстанавливаются, такие формы
+// задаются синонимами каталога.
+func Lemma(word string) string {
+	word = strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+	runes := []rune(word)
+	for _, ending := range endings {
+		e := []rune(ending)
+		if len(runes)-len(e) >= minStem && strings.HasSuffix(word, ending) {
+			return string(runes[:len(runes)-len(e)])
+		}
+	}
+	return word
+}
+
+// Lemmas разбивает текст на слова и возвращает их основы
+func Lemmas(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	})
+
+	lemmas := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.Trim(word, "-"); word != "" {
+			lemmas = append(lemmas, Lemma(word))
+		}
+	}
+	return lemmas
+}
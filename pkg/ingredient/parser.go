@@ -0,0 +1,133 @@
+package ingredient
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/seniorcat/scraper/entity"
+)
+
+// number — целое, десятичное (с точкой или запятой), дробь или смешанное число «1 1/2»
+const number = `\d+\s+\d+/\d+|\d+/\d+|\d+(?:[.,]\d+)?`
+
+// quantity — число или диапазон «2-3»
+const quantity = `(` + number + `)(?:\s*-\s*(` + number + `))?`
+
+var (
+	// Количество в начале строки: «2 ст. л. сахара»
+	leadingQuantity = regexp.MustCompile(`(?i)^` + quantity + `\s*(?:(` + unitPattern + `)(?:\s+|$))?(.*)$`)
+	// Количество в конце строки: «Сахар 2 ст. л.»
+	trailingQuantity = regexp.MustCompile(`(?i)^(.*?)\s+` + quantity + `\s*(` + unitPattern + `)?$`)
+	// Единица без количества в конце строки: «Соль щепотка»
+	trailingUnit = regexp.MustCompile(`(?i)^(.*?)\s+(` + unitPattern + `)$`)
+
+	toTaste     = regexp.MustCompile(`(?i)\s*по\s+вкусу\s*`)
+	parentheses = regexp.MustCompile(`\s*\(([^)]*)\)`)
+	// Запятая, не разделяющая цифры десятичной дроби
+	noteComma = regexp.MustCompile(`\s*,\s*(?:[^\d]|$)`)
+)
+
+// Дробные символы Unicode
+var vulgarFractions = strings.NewReplacer(
+	"½", " 1/2", "⅓", " 1/3", "⅔", " 2/3", "¼", " 1/4", "¾", " 3/4", "⅛", " 1/8",
+	"–", "-", "—", "-", " ", " ",
+)
+
+// Parse разбирает строку ингредиента; нераспознанная строка целиком становится названием
+func Parse(line string) entity.Ingredient {
+	ingredient := entity.Ingredient{Raw: line}
+
+	text := strings.Join(strings.Fields(vulgarFractions.Replace(line)), " ")
+
+	// Примечания: текст в скобках и после запятой
+	var notes []string
+	for _, m := range parentheses.FindAllStringSubmatch(text, -1) {
+		if note := strings.TrimSpace(m[1]); note != "" {
+			notes = append(notes, note)
+		}
+	}
+	text = parentheses.ReplaceAllString(text, "")
+	if loc := noteComma.FindStringIndex(text); loc != nil {
+		if note := strings.TrimSpace(strings.TrimLeft(text[loc[0]:], ", ")); note != "" {
+			notes = append(notes, note)
+		}
+		text = text[:loc[0]]
+	}
+
+	if toTaste.MatchString(text) {
+		ingredient.Unit = UnitToTaste
+		text = strings.TrimSpace(toTaste.ReplaceAllString(text, " "))
+	}
+
+	switch m := leadingQuantity.FindStringSubmatch(text); {
+	case m != nil && m[4] != "":
+		ingredient.Quantity, ingredient.QuantityMax = parseRange(m[1], m[2])
+		ingredient.Unit = firstUnit(CanonicalUnit(m[3]), ingredient.Unit)
+		text = m[4]
+	default:
+		if m := trailingQuantity.FindStringSubmatch(text); m != nil {
+			ingredient.Quantity, ingredient.QuantityMax = parseRange(m[2], m[3])
+			ingredient.Unit = firstUnit(CanonicalUnit(m[4]), ingredient.Unit)
+			text = m[1]
+		} else if m := trailingUnit.FindStringSubmatch(text); m != nil && ingredient.Unit == "" {
+			ingredient.Unit = CanonicalUnit(m[2])
+			text = m[1]
+		}
+	}
+
+	ingredient.Name = strings.Trim(text, " -:—.")
+	ingredient.Note = strings.Join(notes, "; ")
+	return ingredient
+}
+
+// ParseAll разбирает строки ингредиентов рецепта
+func ParseAll(lines []string) []entity.Ingredient {
+	if len(lines) == 0 {
+		return nil
+	}
+	ingredients := make([]entity.Ingredient, len(lines))
+	for i, line := range lines {
+		ingredients[i] = Parse(line)
+	}
+	return ingredients
+}
+
+// firstUnit возвращает первую непустую единицу
+func firstUnit(units ...string) string {
+	for _, unit := range units {
+		if unit != "" {
+			return unit
+		}
+	}
+	return ""
+}
+
+// parseRange возвращает количество и верхнюю границу диапазона
+func parseRange(from, to string) (float64, float64) {
+	min := parseNumber(from)
+	if to == "" {
+		return min, 0
+	}
+	return min, parseNumber(to)
+}
+
+// parseNumber разбирает целое, десятичное, дробь или смешанное число
+func parseNumber(s string) float64 {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+
+	var total float64
+	for _, part := range strings.Fields(s) {
+		if num, den, ok := strings.Cut(part, "/"); ok {
+			n, _ := strconv.ParseFloat(num, 64)
+			d, _ := strconv.ParseFloat(den, 64)
+			if d != 0 {
+				total += n / d
+			}
+			continue
+		}
+		v, _ := strconv.ParseFloat(part, 64)
+		total += v
+	}
+	return total
+}
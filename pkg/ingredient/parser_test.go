@@ -0,0 +1,42 @@
+package ingredient
+
+import (
+	"testing"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse проверяет разбор типичных строк ингредиентов
+func TestParse(t *testing.T) {
+	cases := []struct {
+		line string
+		want entity.Ingredient
+	}{
+		{"2 ст. л. сахара", entity.Ingredient{Quantity: 2, Unit: UnitTablespoon, Name: "сахара"}},
+		{"Сахар 2 ст.л.", entity.Ingredient{Quantity: 2, Unit: UnitTablespoon, Name: "Сахар"}},
+		{"1/2 ч. л. соли", entity.Ingredient{Quantity: 0.5, Unit: UnitTeaspoon, Name: "соли"}},
+		{"Мука 1 ½ стакана", entity.Ingredient{Quantity: 1.5, Unit: UnitGlass, Name: "Мука"}},
+		{"Молоко 0,5 л", entity.Ingredient{Quantity: 0.5, Unit: UnitLiter, Name: "Молоко"}},
+		{"Сливочное масло 50 г", entity.Ingredient{Quantity: 50, Unit: UnitGram, Name: "Сливочное масло"}},
+		{"200 мл сливок 33%", entity.Ingredient{Quantity: 200, Unit: UnitMilliliter, Name: "сливок 33%"}},
+		{"Чеснок 2–3 зубчика", entity.Ingredient{Quantity: 2, QuantityMax: 3, Unit: UnitClove, Name: "Чеснок"}},
+		{"2 головки лука", entity.Ingredient{Quantity: 2, Unit: UnitHead, Name: "лука"}},
+		{"Соль щепотка", entity.Ingredient{Unit: UnitPinch, Name: "Соль"}},
+		{"Перец черный молотый по вкусу", entity.Ingredient{Unit: UnitToTaste, Name: "Перец черный молотый"}},
+		{"Лук репчатый 1 шт., мелко нарезать", entity.Ingredient{Quantity: 1, Unit: UnitPiece, Name: "Лук репчатый", Note: "мелко нарезать"}},
+		{"3 яйца (комнатной температуры)", entity.Ingredient{Quantity: 3, Name: "яйца", Note: "комнатной температуры"}},
+		{"Лавровый лист", entity.Ingredient{Name: "Лавровый лист"}},
+	}
+
+	for _, c := range cases {
+		c.want.Raw = c.line
+		assert.Equal(t, c.want, Parse(c.line), c.line)
+	}
+}
+
+// TestParseAll проверяет, что пустой список ингредиентов остается пустым
+func TestParseAll(t *testing.T) {
+	assert.Nil(t, ParseAll(nil))
+	assert.Len(t, ParseAll([]string{"Соль", "Сахар 1 ч. л."}), 2)
+}
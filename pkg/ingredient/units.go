@@ -0,0 +1,79 @@
+package ingredient
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/seniorcat/scraper/pkg/units"
+)
+
+// Канонические единицы измерения
+const (
+	UnitTablespoon = units.Tablespoon
+	UnitTeaspoon   = units.Teaspoon
+	UnitKilogram   = units.Kilogram
+	UnitGram       = units.Gram
+	UnitLiter      = units.Liter
+	UnitMilliliter = units.Milliliter
+	UnitGlass      = units.Glass
+	UnitPinch      = units.Pinch
+	UnitPiece      = units.Piece
+	UnitClove      = units.Clove
+	UnitBunch      = units.Bunch
+	UnitSprig      = units.Sprig
+	UnitHead       = units.Head
+	UnitPack       = units.Pack
+	UnitCan        = units.Can
+	UnitToTaste    = units.ToTaste
+)
+
+// unitForms — написания единиц; более длинные формы проверяются раньше коротких
+var unitForms = []struct {
+	unit    string
+	pattern string
+}{
+	{UnitTablespoon, `столов\p{L}*\s+лож\p{L}*|ст\.?\s*лож\p{L}*\.?|ст\.?\s*л\.?`},
+	{UnitTeaspoon, `чайн\p{L}*\s+лож\p{L}*|ч\.?\s*лож\p{L}*\.?|ч\.?\s*л\.?`},
+	{UnitKilogram, `килограмм\p{L}*|кг\.?`},
+	{UnitGram, `грамм\p{L}*|гр\.?|г\.?`},
+	{UnitMilliliter, `миллилитр\p{L}*|мл\.?`},
+	{UnitLiter, `литр\p{L}*|л\.?`},
+	{UnitGlass, `стакан\p{L}*|ст\.`},
+	{UnitPinch, `щепот\p{L}*|щеп\.?`},
+	{UnitPiece, `штук\p{L}*|шт\.?`},
+	{UnitClove, `зубч\p{L}*|зубок`},
+	{UnitBunch, `пуч\p{L}*`},
+	{UnitSprig, `веточ\p{L}*|веток`},
+	{UnitHead, `голов\p{L}*`},
+	{UnitPack, `упаковк\p{L}*|упак\.?|уп\.`},
+	{UnitCan, `банк\p{L}*|банок`},
+}
+
+// unitPattern — альтернатива всех написаний единиц
+var unitPattern = func() string {
+	patterns := make([]string, len(unitForms))
+	for i, form := range unitForms {
+		patterns[i] = form.pattern
+	}
+	return strings.Join(patterns, "|")
+}()
+
+// unitMatchers — выражения для определения канонической единицы по найденному написанию
+var unitMatchers = func() []*regexp.Regexp {
+	matchers := make([]*regexp.Regexp, len(unitForms))
+	for i, form := range unitForms {
+		matchers[i] = regexp.MustCompile(`(?i)^(?:` + form.pattern + `)$`)
+	}
+	return matchers
+}()
+
+// CanonicalUnit возвращает каноническую единицу для написания или пустую строку
+func CanonicalUnit(form string) string {
+	form = strings.TrimSpace(form)
+	for i, matcher := range unitMatchers {
+		if matcher.MatchString(form) {
+			return unitForms[i].unit
+		}
+	}
+	return ""
+}
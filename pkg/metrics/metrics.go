@@ -1,8 +1,12 @@
 package metrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"time"
 
-// Счетчик запросов парсера
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Счетчик HTTP-запросов парсера
 var RequestCounter = prometheus.NewCounter(
 	prometheus.CounterOpts{
 		Name: "parser_requests_total",
@@ -10,7 +14,210 @@ var RequestCounter = prometheus.NewCounter(
 	},
 )
 
+// Заполненность текущего фильтра Блума в кеше посещенных ключей
+var BloomFillRatio = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "cache_bloom_fill_ratio",
+		Help: "Share of set bits in the active bloom filter of the seen-set cache.",
+	},
+)
+
+// Количество фильтров в масштабируемом фильтре Блума
+var BloomFilters = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "cache_bloom_filters",
+		Help: "Number of filters in the scalable bloom filter of the seen-set cache.",
+	},
+)
+
+// Длительность загрузки страниц по сайту и этапу обхода
+var FetchDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "parser_fetch_duration_seconds",
+		Help:    "Time from sending a request to receiving the response.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	},
+	[]string{"site", "stage"},
+)
+
+// Размер ответов по сайту и этапу обхода
+var ResponseSize = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "parser_response_size_bytes",
+		Help:    "Size of response bodies.",
+		Buckets: prometheus.ExponentialBuckets(1024, 4, 8),
+	},
+	[]string{"site", "stage"},
+)
+
+// Ответы по HTTP-статусу; status="error" для запросов, завершившихся без ответа
+var ResponsesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "parser_responses_total",
+		Help: "Total number of responses by HTTP status.",
+	},
+	[]string{"site", "stage", "status"},
+)
+
+// Извлеченные элементы (категории, рецепты) по сайту и этапу
+var ItemsExtracted = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "parser_items_extracted_total",
+		Help: "Total number of items extracted and validated.",
+	},
+	[]string{"site", "stage"},
+)
+
+// Элементы, отброшенные валидацией
+var ValidationErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "parser_validation_errors_total",
+		Help: "Total number of extracted items rejected by validation.",
+	},
+	[]string{"site", "stage"},
+)
+
+// Глубина очереди задач TaskController
+var TaskQueueDepth = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "worker_task_queue_depth",
+		Help: "Number of tasks waiting in the task queue.",
+	},
+)
+
+// Глубина очереди результатов TaskController
+var ResultQueueDepth = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "worker_result_queue_depth",
+		Help: "Number of results waiting to be saved.",
+	},
+)
+
+// Количество воркеров, занятых обработкой задачи
+var WorkersBusy = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "worker_busy",
+		Help: "Number of recipe workers currently processing a task.",
+	},
+)
+
+// Длительность записи в базу данных по операции
+var DBWriteDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "db_write_duration_seconds",
+		Help:    "Duration of database write operations.",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{"operation"},
+)
+
+// Ошибки записи в базу данных по операции
+var DBWriteErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "db_write_errors_total",
+		Help: "Total number of failed database write operations.",
+	},
+	[]string{"operation"},
+)
+
+// Время ожидания токена в лимитере запросов
+var RateLimiterWait = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Name:    "rate_limiter_wait_seconds",
+		Help:    "Time spent waiting for a rate limiter token.",
+		Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	},
+)
+
+// Задачи, снятые сторожем по превышению времени выполнения
+var WatchdogTaskTimeouts = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "watchdog_task_timeouts_total",
+		Help: "Total number of tasks cancelled by the watchdog for exceeding the deadline.",
+	},
+)
+
+// Воркеры, перезапущенные сторожем после падения или зависания
+var WatchdogWorkerRestarts = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "watchdog_worker_restarts_total",
+		Help: "Total number of dead or hung recipe workers replaced by the watchdog.",
+	},
+)
+
+// Задачи, для типа которых не зарегистрирован обработчик
+var UnknownTaskTypes = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "parser_unknown_task_types_total",
+		Help: "Total number of tasks rejected because no handler is registered for their type.",
+	},
+	[]string{"type"},
+)
+
+// Аномалии извлечения данных, обнаруженные по итогам запуска
+var AnomaliesDetected = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "parser_anomalies_detected_total",
+		Help: "Total number of extraction anomalies detected against the historical baseline.",
+	},
+	[]string{"kind", "severity", "stage"},
+)
+
+// Признак запуска, проваленного из-за аномалий извлечения (1 — провален)
+var RunAnomalous = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "parser_run_anomalous",
+		Help: "Set to 1 when the last finished run failed because of critical extraction anomalies; restored from run history at startup.",
+	},
+)
+
+// Стратегии, которыми извлечены поля страниц
+var ExtractionStrategy = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "parser_extraction_strategy_total",
+		Help: "Total number of extracted page fields by the strategy that succeeded; strategy=\"none\" when all strategies failed.",
+	},
+	[]string{"stage", "field", "strategy"},
+)
+
+// Показатели пищевой ценности, где данные сайта сильно расходятся с расчетом по ингредиентам
+var NutritionDiscrepancies = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "parser_nutrition_discrepancies_total",
+		Help: "Total number of recipe nutrition fields where site values differ from the ingredient-based calculation.",
+	},
+	[]string{"field"},
+)
+
+// ObserveDBWrite учитывает длительность и результат записи в базу данных
+func ObserveDBWrite(operation string, start time.Time, err error) {
+	DBWriteDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		DBWriteErrors.WithLabelValues(operation).Inc()
+	}
+}
+
 // Init регистрирует метрики
 func Init() {
 	prometheus.MustRegister(RequestCounter)
+	prometheus.MustRegister(BloomFillRatio)
+	prometheus.MustRegister(BloomFilters)
+	prometheus.MustRegister(FetchDuration)
+	prometheus.MustRegister(ResponseSize)
+	prometheus.MustRegister(ResponsesTotal)
+	prometheus.MustRegister(ItemsExtracted)
+	prometheus.MustRegister(ValidationErrors)
+	prometheus.MustRegister(TaskQueueDepth)
+	prometheus.MustRegister(ResultQueueDepth)
+	prometheus.MustRegister(WorkersBusy)
+	prometheus.MustRegister(DBWriteDuration)
+	prometheus.MustRegister(DBWriteErrors)
+	prometheus.MustRegister(RateLimiterWait)
+	prometheus.MustRegister(WatchdogTaskTimeouts)
+	prometheus.MustRegister(WatchdogWorkerRestarts)
+	prometheus.MustRegister(UnknownTaskTypes)
+	prometheus.MustRegister(AnomaliesDetected)
+	prometheus.MustRegister(RunAnomalous)
+	prometheus.MustRegister(ExtractionStrategy)
+	prometheus.MustRegister(NutritionDiscrepancies)
 }
@@ -0,0 +1,208 @@
+package nutrition
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/units"
+)
+
+// Пороги сверки расчета с данными сайта по умолчанию
+const (
+	DefaultMaxDiscrepancy = 0.3 // Допустимое относительное расхождение
+	DefaultMinCoverage    = 0.8 // Минимальная полнота расчета, при которой сверка имеет смысл
+)
+
+// Показатели пищевой ценности
+const (
+	FieldCalories      = "calories"
+	FieldProtein       = "protein"
+	FieldFat           = "fat"
+	FieldCarbohydrates = "carbohydrates"
+)
+
+// siteProperties — свойства NutritionInformation schema.org по показателям
+var siteProperties = map[string]string{
+	FieldCalories:      "calories",
+	FieldProtein:       "proteinContent",
+	FieldFat:           "fatContent",
+	FieldCarbohydrates: "carbohydrateContent",
+}
+
+// discrepancyFloor — нижняя граница знаменателя при сверке, чтобы малые значения
+// («0,5 г жиров») не давали огромных относительных расхождений
+var discrepancyFloor = map[string]float64{
+	FieldCalories:      50,
+	FieldProtein:       5,
+	FieldFat:           5,
+	FieldCarbohydrates: 5,
+}
+
+// siteNumber — число в значении свойства: «250 ккал», «12,5 г»
+var siteNumber = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+
+// Calculator рассчитывает пищевую ценность рецептов по таблице состава продуктов
+// и сверяет ее со значениями сайта
+type Calculator struct {
+	Table          *Table
+	MaxDiscrepancy float64
+	MinCoverage    float64
+}
+
+// NewCalculator создает калькулятор с порогами по умолчанию
+func NewCalculator(table *Table) *Calculator {
+	return &Calculator{
+		Table:          table,
+		MaxDiscrepancy: DefaultMaxDiscrepancy,
+		MinCoverage:    DefaultMinCoverage,
+	}
+}
+
+// Analyze рассчитывает пищевую ценность порции рецепта по разобранным ингредиентам
+// с каноническими названиями и сверяет ее с данными сайта. Сайт указывает значения
+// на порцию, поэтому без числа порций сверка не проводится. Возвращает nil, если
+// нет ни расчета, ни данных сайта. Безопасен для nil-калькулятора.
+func (c *Calculator) Analyze(recipe entity.Recipe) *entity.NutritionInfo {
+	if c == nil {
+		return nil
+	}
+
+	info := &entity.NutritionInfo{Servings: recipe.Servings()}
+	computed, counted, covered := c.compute(recipe.Parsed, &info.Missing)
+	if counted > 0 {
+		info.Coverage = round(float64(covered)/float64(counted), 100)
+	}
+	if covered > 0 {
+		if info.Servings > 0 {
+			computed = computed.Scale(1 / float64(info.Servings))
+		}
+		computed = roundNutrition(computed)
+		info.Computed = &computed
+	}
+
+	var present map[string]bool
+	if recipe.Details != nil {
+		info.Site, present = siteNutrition(recipe.Details.Nutrition)
+	}
+	if info.Computed == nil && info.Site == nil {
+		return nil
+	}
+
+	// Без порций расчет сделан на весь рецепт и с порцией на сайте не сравним
+	if info.Computed != nil && info.Site != nil && info.Servings > 0 && info.Coverage >= c.MinCoverage {
+		info.Discrepancies = discrepancies(*info.Computed, *info.Site, present, c.MaxDiscrepancy)
+	}
+	return info
+}
+
+// compute суммирует пищевую ценность ингредиентов рецепта. Учитываются ингредиенты
+// с количеством; «по вкусу» и ингредиенты без количества в расчет не входят.
+func (c *Calculator) compute(ingredients []entity.Ingredient, missing *[]string) (total entity.Nutrition, counted, covered int) {
+	for _, ingredient := range ingredients {
+		if ingredient.Quantity == 0 || ingredient.Unit == units.ToTaste {
+			continue
+		}
+		counted++
+
+		grams, ok := ingredient.Grams()
+		per100g, known := c.Table.Lookup(ingredient.Canonical)
+		if !ok || !known || ingredient.Canonical == "" {
+			*missing = append(*missing, ingredient.Name)
+			continue
+		}
+		covered++
+		total = total.Add(per100g.Scale(grams / 100))
+	}
+	return total, counted, covered
+}
+
+// siteNutrition разбирает свойства NutritionInformation; present отмечает показатели,
+// которые сайт указал
+func siteNutrition(properties map[string]string) (*entity.Nutrition, map[string]bool) {
+	var nutrition entity.Nutrition
+	present := make(map[string]bool)
+	targets := map[string]*float64{
+		FieldCalories:      &nutrition.Calories,
+		FieldProtein:       &nutrition.Protein,
+		FieldFat:           &nutrition.Fat,
+		FieldCarbohydrates: &nutrition.Carbohydrates,
+	}
+
+	for field, property := range siteProperties {
+		value, ok := siteValue(properties[property])
+		if !ok {
+			continue
+		}
+		// Калорийность иногда указана в килоджоулях
+		if field == FieldCalories && isKilojoules(properties[property]) {
+			value /= 4.184
+		}
+		*targets[field] = round(value, 10)
+		present[field] = true
+	}
+
+	if len(present) == 0 {
+		return nil, nil
+	}
+	return &nutrition, present
+}
+
+// siteValue извлекает число из значения свойства
+func siteValue(text string) (float64, bool) {
+	number := siteNumber.FindString(text)
+	if number == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.Replace(number, ",", ".", 1), 64)
+	return value, err == nil
+}
+
+// isKilojoules проверяет, что калорийность указана в килоджоулях
+func isKilojoules(text string) bool {
+	text = strings.ToLower(text)
+	return strings.Contains(text, "кдж") || strings.Contains(text, "kj")
+}
+
+// discrepancies возвращает показатели, указанные сайтом, относительное расхождение
+// которых с расчетом превышает порог
+func discrepancies(computed, site entity.Nutrition, present map[string]bool, maxDiscrepancy float64) []string {
+	pairs := []struct {
+		field          string
+		computed, site float64
+	}{
+		{FieldCalories, computed.Calories, site.Calories},
+		{FieldProtein, computed.Protein, site.Protein},
+		{FieldFat, computed.Fat, site.Fat},
+		{FieldCarbohydrates, computed.Carbohydrates, site.Carbohydrates},
+	}
+
+	var fields []string
+	for _, pair := range pairs {
+		if !present[pair.field] {
+			continue
+		}
+		base := math.Max(pair.site, discrepancyFloor[pair.field])
+		if math.Abs(pair.computed-pair.site)/base > maxDiscrepancy {
+			fields = append(fields, pair.field)
+		}
+	}
+	return fields
+}
+
+// roundNutrition округляет показатели до десятых
+func roundNutrition(n entity.Nutrition) entity.Nutrition {
+	return entity.Nutrition{
+		Calories:      round(n.Calories, 10),
+		Protein:       round(n.Protein, 10),
+		Fat:           round(n.Fat, 10),
+		Carbohydrates: round(n.Carbohydrates, 10),
+	}
+}
+
+// round округляет значение до 1/scale
+func round(value, scale float64) float64 {
+	return math.Round(value*scale) / scale
+}
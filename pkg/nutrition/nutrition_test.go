@@ -0,0 +1,109 @@
+package nutrition
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/ingredient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTableCoversCatalog проверяет, что у каждого ингредиента каталога есть пищевая ценность
+func TestTableCoversCatalog(t *testing.T) {
+	table, err := DefaultTable()
+	require.NoError(t, err)
+	catalog, err := ingredient.DefaultCatalog()
+	require.NoError(t, err)
+
+	for _, name := range catalog.Names() {
+		_, ok := table.Lookup(name)
+		assert.True(t, ok, name)
+	}
+}
+
+// TestLoadTableOverride проверяет, что дополнительный файл заменяет встроенные значения
+func TestLoadTableOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "extra.csv")
+	require.NoError(t, os.WriteFile(path, []byte("name,calories,protein,fat,carbohydrates\nТворог,236,15,18,2.8\nкиноа,368,14.1,6.1,64.2\n"), 0o644))
+
+	table, err := LoadTable(path)
+	require.NoError(t, err)
+
+	cottage, ok := table.Lookup("творог")
+	require.True(t, ok)
+	assert.Equal(t, 236.0, cottage.Calories)
+	_, ok = table.Lookup("киноа")
+	assert.True(t, ok)
+
+	require.NoError(t, os.WriteFile(path, []byte("киноа,много,14.1,6.1,64.2\n"), 0o644))
+	_, err = LoadTable(path)
+	assert.Error(t, err)
+}
+
+// TestCalculatorAnalyze проверяет расчет на порцию и сверку с данными сайта
+func TestCalculatorAnalyze(t *testing.T) {
+	table, err := DefaultTable()
+	require.NoError(t, err)
+	calculator := NewCalculator(table)
+
+	recipe := entity.Recipe{
+		Parsed: []entity.Ingredient{
+			{Name: "муки", Canonical: "мука пшеничная", Quantity: 1, Unit: "стакан"},
+			{Name: "молока", Canonical: "молоко", Quantity: 500, Unit: "мл"},
+			{Name: "яйца", Canonical: "яйцо куриное", Quantity: 2},
+			{Name: "соль", Canonical: "соль", Unit: "по вкусу"},
+			{Name: "шафрана", Quantity: 1, Unit: "г"},
+		},
+		Details: &entity.RecipeDetails{
+			Yield: "2 порции",
+			Nutrition: map[string]string{
+				"calories":       "460 ккал",
+				"proteinContent": "21 г",
+				"fatContent":     "5 г",
+			},
+		},
+	}
+
+	info := calculator.Analyze(recipe)
+	require.NotNil(t, info)
+	require.NotNil(t, info.Computed)
+	assert.Equal(t, 2, info.Servings)
+	assert.InDelta(t, 458, info.Computed.Calories, 0.2)
+	assert.InDelta(t, 21.1, info.Computed.Protein, 0.1)
+	assert.InDelta(t, 15.3, info.Computed.Fat, 0.1)
+	assert.Equal(t, 0.75, info.Coverage)
+	assert.Equal(t, []string{"шафрана"}, info.Missing)
+	assert.Equal(t, &entity.Nutrition{Calories: 460, Protein: 21, Fat: 5}, info.Site)
+
+	// При неполном расчете сверка не проводится
+	assert.Empty(t, info.Discrepancies)
+
+	calculator.MinCoverage = 0.5
+	info = calculator.Analyze(recipe)
+	assert.Equal(t, []string{FieldFat}, info.Discrepancies)
+
+	// Без числа порций расчет на весь рецепт с сайтом не сверяется
+	recipe.Details.Yield = ""
+	info = calculator.Analyze(recipe)
+	require.NotNil(t, info.Computed)
+	assert.Zero(t, info.Servings)
+	assert.InDelta(t, 916, info.Computed.Calories, 0.4)
+	assert.Empty(t, info.Discrepancies)
+
+	// Без ингредиентов и данных сайта пищевая ценность неизвестна
+	assert.Nil(t, calculator.Analyze(entity.Recipe{Name: "пусто"}))
+	var none *Calculator
+	assert.Nil(t, none.Analyze(recipe))
+}
+
+// TestSiteNutritionKilojoules проверяет перевод килоджоулей в килокалории
+func TestSiteNutritionKilojoules(t *testing.T) {
+	site, present := siteNutrition(map[string]string{"calories": "1046 кДж", "carbohydrateContent": "12,5 g"})
+	require.NotNil(t, site)
+	assert.InDelta(t, 250, site.Calories, 0.1)
+	assert.Equal(t, 12.5, site.Carbohydrates)
+	assert.False(t, present[FieldFat])
+}
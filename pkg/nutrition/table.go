@@ -0,0 +1,106 @@
+package nutrition
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/seniorcat/scraper/entity"
+)
+
+// bundledTable — встроенная таблица состава продуктов
+//
+//go:embed nutrients.csv
+var bundledTable []byte
+
+// Table — пищевая ценность канонических ингредиентов на 100 г
+type Table struct {
+	per100g map[string]entity.Nutrition
+}
+
+// NewTable создает таблицу из значений на 100 г по каноническим названиям
+func NewTable(per100g map[string]entity.Nutrition) *Table {
+	t := &Table{per100g: make(map[string]entity.Nutrition, len(per100g))}
+	for name, nutrition := range per100g {
+		t.per100g[strings.ToLower(strings.TrimSpace(name))] = nutrition
+	}
+	return t
+}
+
+// DefaultTable создает таблицу из встроенного файла
+func DefaultTable() (*Table, error) {
+	return LoadTable("")
+}
+
+// LoadTable создает таблицу из встроенного файла и дополнительного CSV (если путь
+// задан); строки дополнительного файла заменяют встроенные
+func LoadTable(path string) (*Table, error) {
+	per100g, err := readCSV(bytes.NewReader(bundledTable))
+	if err != nil {
+		return nil, fmt.Errorf("bundled nutrient table: %w", err)
+	}
+
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		extra, err := readCSV(file)
+		if err != nil {
+			return nil, fmt.Errorf("nutrient table %s: %w", path, err)
+		}
+		for name, nutrition := range extra {
+			per100g[name] = nutrition
+		}
+	}
+
+	return NewTable(per100g), nil
+}
+
+// Lookup возвращает пищевую ценность 100 г канонического ингредиента
+func (t *Table) Lookup(name string) (entity.Nutrition, bool) {
+	nutrition, ok := t.per100g[name]
+	return nutrition, ok
+}
+
+// readCSV читает таблицу с заголовком name,calories,protein,fat,carbohydrates;
+// строки, начинающиеся с #, пропускаются
+func readCSV(r io.Reader) (map[string]entity.Nutrition, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 5
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	per100g := make(map[string]entity.Nutrition, len(records))
+	for i, record := range records {
+		if i == 0 && record[0] == "name" {
+			continue // Заголовок
+		}
+
+		var values [4]float64
+		for j := range values {
+			values[j], err = strconv.ParseFloat(strings.TrimSpace(record[j+1]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+		per100g[strings.ToLower(strings.TrimSpace(record[0]))] = entity.Nutrition{
+			Calories:      values[0],
+			Protein:       values[1],
+			Fat:           values[2],
+			Carbohydrates: values[3],
+		}
+	}
+	return per100g, nil
+}
@@ -0,0 +1,106 @@
+package phash
+
+import (
+	"sort"
+
+	"github.com/seniorcat/scraper/pkg/dedup"
+)
+
+// DefaultMaxDistance — расстояние Хэмминга, до которого изображения считаются одинаковыми
+const DefaultMaxDistance = 8
+
+// Cluster группирует хеши вокруг представителей и возвращает индексы групп из
+// двух и более элементов. Каждый хеш отстоит от представителя своей группы не
+// больше чем на maxDistance, поэтому любые два хеша группы — не больше чем на
+// 2*maxDistance: цепочка постепенно меняющихся изображений не сливается в одну
+// группу. Представителями становятся сначала самые частые хеши. Группы
+// упорядочены по убыванию размера, индексы внутри группы — по возрастанию.
+func Cluster(hashes []uint64, maxDistance int) [][]int {
+	if maxDistance < 0 || maxDistance > 63 {
+		maxDistance = DefaultMaxDistance
+	}
+
+	// Одинаковые хеши сравниваются один раз
+	unique := make(map[uint64]int)
+	values := make([]uint64, 0, len(hashes))
+	counts := make([]int, 0, len(hashes))
+	for _, hash := range hashes {
+		i, ok := unique[hash]
+		if !ok {
+			i = len(values)
+			unique[hash] = i
+			values = append(values, hash)
+			counts = append(counts, 0)
+		}
+		counts[i]++
+	}
+
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return counts[order[a]] > counts[order[b]] })
+
+	// По принципу Дирихле хеши с расстоянием не больше maxDistance совпадают
+	// хотя бы в одной из maxDistance+1 полос, поэтому хеш сравнивается только
+	// с представителями, у которых есть общая с ним полоса
+	bands := maxDistance + 1
+	leaders := make([]map[uint64][]int, bands)
+	for band := range leaders {
+		leaders[band] = make(map[uint64][]int)
+	}
+	group := make([]int, len(values))
+	for _, i := range order {
+		hash := values[i]
+		best, bestDistance := -1, maxDistance+1
+		for band := 0; band < bands; band++ {
+			for _, leader := range leaders[band][bandKey(band, bands, hash)] {
+				distance := dedup.HammingDistance(hash, values[leader])
+				if distance < bestDistance || distance == bestDistance && leader < best {
+					best, bestDistance = leader, distance
+				}
+			}
+		}
+		if best >= 0 {
+			group[i] = best
+			continue
+		}
+
+		// Хеш без близкого представителя начинает новую группу
+		group[i] = i
+		for band := 0; band < bands; band++ {
+			key := bandKey(band, bands, hash)
+			leaders[band][key] = append(leaders[band][key], i)
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i, hash := range hashes {
+		leader := group[unique[hash]]
+		groups[leader] = append(groups[leader], i)
+	}
+
+	var clusters [][]int
+	for _, group := range groups {
+		if len(group) > 1 {
+			clusters = append(clusters, group)
+		}
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i]) != len(clusters[j]) {
+			return len(clusters[i]) > len(clusters[j])
+		}
+		return clusters[i][0] < clusters[j][0]
+	})
+	return clusters
+}
+
+// bandKey возвращает биты полосы band из bands полос хеша
+func bandKey(band, bands int, hash uint64) uint64 {
+	width := 64 / bands
+	shift := uint(band * width)
+	if band == bands-1 {
+		return hash >> shift
+	}
+	return (hash >> shift) & (1<<uint(width) - 1)
+}
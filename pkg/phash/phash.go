@@ -0,0 +1,144 @@
+package phash
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Hashes — перцептивные хеши изображения. Похожие изображения (уменьшенные,
+// пережатые, с другим форматом) дают хеши с малым расстоянием Хэмминга.
+type Hashes struct {
+	Average    uint64 // aHash: яркость 8x8 относительно средней
+	Difference uint64 // dHash: перепады яркости между соседними точками
+	Perceptual uint64 // pHash: низкие частоты DCT относительно медианы
+}
+
+// Имена хешей для выбора при поиске похожих изображений
+const (
+	KindAverage    = "ahash"
+	KindDifference = "dhash"
+	KindPerceptual = "phash"
+)
+
+// normalizedSize — сторона полутонового изображения, из которого считаются хеши
+const normalizedSize = 32
+
+// Compute вычисляет все хеши изображения; прозрачность заменяется белым фоном
+func Compute(img image.Image) Hashes {
+	gray := grayscale(img, normalizedSize, normalizedSize)
+	return Hashes{
+		Average:    averageHash(gray),
+		Difference: differenceHash(gray),
+		Perceptual: perceptualHash(gray),
+	}
+}
+
+// Get возвращает хеш по имени; ok — false для неизвестного имени
+func (h Hashes) Get(kind string) (uint64, bool) {
+	switch kind {
+	case KindAverage:
+		return h.Average, true
+	case KindDifference:
+		return h.Difference, true
+	case KindPerceptual:
+		return h.Perceptual, true
+	}
+	return 0, false
+}
+
+// grayscale уменьшает изображение до width x height точек в оттенках серого
+func grayscale(img image.Image, width, height int) *image.Gray {
+	gray := image.NewGray(image.Rect(0, 0, width, height))
+	draw.Draw(gray, gray.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(gray, gray.Bounds(), img, img.Bounds(), draw.Over, nil)
+	return gray
+}
+
+// averageHash сравнивает яркость точек 8x8 со средней
+func averageHash(src *image.Gray) uint64 {
+	gray := grayscale(src, 8, 8)
+	sum := 0
+	for _, v := range gray.Pix {
+		sum += int(v)
+	}
+	mean := sum / len(gray.Pix)
+
+	var hash uint64
+	for i, v := range gray.Pix {
+		if int(v) > mean {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// differenceHash сравнивает соседние по горизонтали точки изображения 9x8
+func differenceHash(src *image.Gray) uint64 {
+	gray := grayscale(src, 9, 8)
+	var hash uint64
+	bit := 0
+	for y := 0; y < 8; y++ {
+		row := gray.Pix[y*gray.Stride:]
+		for x := 0; x < 8; x++ {
+			if row[x] < row[x+1] {
+				hash |= 1 << uint(bit)
+			}
+			bit++
+		}
+	}
+	return hash
+}
+
+// perceptualHash сравнивает коэффициенты DCT 8x8 низших частот изображения
+// 32x32 с их медианой; постоянная составляющая в медиану не входит
+func perceptualHash(gray *image.Gray) uint64 {
+	const n, k = normalizedSize, 8
+
+	var cosines [k][n]float64
+	for u := 0; u < k; u++ {
+		for x := 0; x < n; x++ {
+			cosines[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * n))
+		}
+	}
+
+	// Разделимое двумерное преобразование: сначала по строкам, затем по столбцам
+	var rows [n][k]float64
+	for y := 0; y < n; y++ {
+		line := gray.Pix[y*gray.Stride:]
+		for u := 0; u < k; u++ {
+			var sum float64
+			for x := 0; x < n; x++ {
+				sum += float64(line[x]) * cosines[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+
+	coefficients := make([]float64, 0, k*k)
+	for v := 0; v < k; v++ {
+		for u := 0; u < k; u++ {
+			var sum float64
+			for y := 0; y < n; y++ {
+				sum += rows[y][u] * cosines[v][y]
+			}
+			coefficients = append(coefficients, sum)
+		}
+	}
+
+	sorted := append([]float64(nil), coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+	for i, c := range coefficients {
+		if c > median {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
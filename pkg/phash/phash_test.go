@@ -0,0 +1,102 @@
+package phash
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/seniorcat/scraper/pkg/dedup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testImage создает изображение с градиентом и кругом; invert меняет светлое и темное
+func testImage(width, height int, invert bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(255 * x / width)
+			dx, dy := x-width/3, y-height/2
+			if dx*dx+dy*dy < height*height/9 {
+				v = 255 - v
+			}
+			if invert {
+				v = 255 - v
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v / 2, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
+
+// recompress пережимает изображение в JPEG низкого качества
+func recompress(t *testing.T, img image.Image) image.Image {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 40}))
+	decoded, err := jpeg.Decode(&buf)
+	require.NoError(t, err)
+	return decoded
+}
+
+// TestCompute проверяет, что хеши устойчивы к уменьшению и пережатию и различают разные изображения
+func TestCompute(t *testing.T) {
+	original := Compute(testImage(640, 480, false))
+	copied := Compute(recompress(t, testImage(320, 240, false)))
+	other := Compute(testImage(640, 480, true))
+
+	for _, kind := range []string{KindAverage, KindDifference, KindPerceptual} {
+		a, ok := original.Get(kind)
+		require.True(t, ok)
+		b, _ := copied.Get(kind)
+		c, _ := other.Get(kind)
+		assert.LessOrEqual(t, dedup.HammingDistance(a, b), DefaultMaxDistance, kind)
+		assert.Greater(t, dedup.HammingDistance(a, c), DefaultMaxDistance*2, kind)
+	}
+
+	_, ok := original.Get("md5")
+	assert.False(t, ok)
+}
+
+// TestCluster проверяет группировку близких хешей вокруг представителей
+func TestCluster(t *testing.T) {
+	hashes := []uint64{
+		0x0000000000000000,
+		0xFFFFFFFFFFFFFFFF,
+		0x0000000000000007, // 3 бита от первого
+		0xFFFFFFFF00000000,
+		0x000000000000003F, // 3 бита от третьего, 6 от первого
+		0xFFFFFFFFFFFFFFFF, // Совпадает со вторым
+	}
+
+	// Пятый близок только к третьему и по цепочке к группе первого не присоединяется
+	clusters := Cluster(hashes, 3)
+	assert.Equal(t, [][]int{{0, 2}, {1, 5}}, clusters)
+
+	// С большим порогом пятый близок к представителю группы
+	assert.Equal(t, [][]int{{0, 2, 4}, {1, 5}}, Cluster(hashes, 6))
+
+	// Нулевое расстояние объединяет только одинаковые хеши
+	assert.Equal(t, [][]int{{1, 5}}, Cluster(hashes, 0))
+	assert.Empty(t, Cluster(nil, 3))
+}
+
+// TestClusterChain проверяет, что постепенно меняющиеся хеши не сливаются в одну
+// группу: расстояние внутри группы ограничено удвоенным порогом
+func TestClusterChain(t *testing.T) {
+	var hashes []uint64
+	for bits := 0; bits <= 40; bits += 2 {
+		hashes = append(hashes, 1<<uint(bits)-1) // Соседи отличаются на 2 бита
+	}
+
+	clusters := Cluster(hashes, 4)
+	require.Greater(t, len(clusters), 1)
+	for _, cluster := range clusters {
+		for _, i := range cluster {
+			for _, j := range cluster {
+				assert.LessOrEqual(t, dedup.HammingDistance(hashes[i], hashes[j]), 8)
+			}
+		}
+	}
+}
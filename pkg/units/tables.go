@@ -0,0 +1,75 @@
+package units
+
+// Densities — плотность ингредиентов каталога, грамм в миллилитре
+var Densities = map[string]float64{
+	"вода":                 1,
+	"молоко":               1.03,
+	"кефир":                1.03,
+	"йогурт":               1.05,
+	"сливки":               1,
+	"сметана":              1.05,
+	"масло растительное":   0.92,
+	"масло оливковое":      0.92,
+	"масло сливочное":      0.91,
+	"мука пшеничная":       0.65,
+	"сахар":                0.9,
+	"сахарная пудра":       0.55,
+	"ванильный сахар":      0.9,
+	"соль":                 1.3,
+	"сода":                 1.2,
+	"разрыхлитель":         0.8,
+	"крахмал":              0.65,
+	"какао":                0.5,
+	"мед":                  1.4,
+	"рис":                  0.9,
+	"гречка":               0.8,
+	"овсяные хлопья":       0.45,
+	"изюм":                 0.65,
+	"творог":               0.8,
+	"майонез":              0.95,
+	"томатная паста":       1.1,
+	"соевый соус":          1.1,
+	"уксус":                1,
+	"лимон":                1.03, // Лимонный сок
+	"желатин":              0.7,
+	"перец черный молотый": 0.5,
+	"грецкий орех":         0.45,
+	"миндаль":              0.6,
+	"арахис":               0.6,
+}
+
+// PieceWeights — средний вес штучных единиц ингредиентов каталога, грамм
+var PieceWeights = map[string]map[string]float64{
+	"яйцо куриное":         {Piece: 55},
+	"яичный желток":        {Piece: 18},
+	"яичный белок":         {Piece: 33},
+	"лук репчатый":         {Piece: 100, Head: 100},
+	"лук зеленый":          {Bunch: 50},
+	"чеснок":               {Clove: 5, Head: 50, Piece: 5},
+	"картофель":            {Piece: 150},
+	"морковь":              {Piece: 100},
+	"свекла":               {Piece: 250},
+	"помидор":              {Piece: 120},
+	"огурец":               {Piece: 120},
+	"перец болгарский":     {Piece: 150},
+	"баклажан":             {Piece: 250},
+	"кабачок":              {Piece: 300},
+	"капуста белокочанная": {Piece: 1500, Head: 1500},
+	"лимон":                {Piece: 120},
+	"яблоко":               {Piece: 180},
+	"банан":                {Piece: 150},
+	"укроп":                {Bunch: 50, Sprig: 2},
+	"петрушка":             {Bunch: 50, Sprig: 2},
+	"кинза":                {Bunch: 50, Sprig: 2},
+	"базилик":              {Bunch: 30, Sprig: 2},
+	"лавровый лист":        {Piece: 0.2},
+	"куриное филе":         {Piece: 250},
+	"дрожжи":               {Pack: 11},
+	"разрыхлитель":         {Pack: 10},
+	"ванильный сахар":      {Pack: 10},
+	"масло сливочное":      {Pack: 180},
+	"творог":               {Pack: 200},
+	"сливочный сыр":        {Pack: 180},
+	"сыр моцарелла":        {Pack: 125},
+	"томатная паста":       {Can: 70},
+}
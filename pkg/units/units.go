@@ -0,0 +1,146 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Канонические единицы измерения
+const (
+	Tablespoon = "ст. л."
+	Teaspoon   = "ч. л."
+	Kilogram   = "кг"
+	Gram       = "г"
+	Liter      = "л"
+	Milliliter = "мл"
+	Glass      = "стакан"
+	Pinch      = "щепотка"
+	Piece      = "шт"
+	Clove      = "зубчик"
+	Bunch      = "пучок"
+	Sprig      = "веточка"
+	Head       = "головка"
+	Pack       = "упаковка"
+	Can        = "банка"
+	ToTaste    = "по вкусу"
+)
+
+// Kind — вид единицы измерения
+type Kind int
+
+// Виды единиц
+const (
+	KindOther  Kind = iota // Не переводится в массу без справочника штучных весов
+	KindMass               // Масса, базовая единица — грамм
+	KindVolume             // Объем, базовая единица — миллилитр
+)
+
+// base — вид единицы и ее размер в базовых единицах
+var base = map[string]struct {
+	kind Kind
+	size float64
+}{
+	Gram:       {KindMass, 1},
+	Kilogram:   {KindMass, 1000},
+	Pinch:      {KindMass, 0.5},
+	Milliliter: {KindVolume, 1},
+	Liter:      {KindVolume, 1000},
+	Teaspoon:   {KindVolume, 5},
+	Tablespoon: {KindVolume, 15},
+	Glass:      {KindVolume, 200}, // Граненый стакан
+}
+
+// ErrNotConvertible возвращается, если единицы нельзя перевести друг в друга
+var ErrNotConvertible = errors.New("units are not convertible")
+
+// KindOf возвращает вид единицы
+func KindOf(unit string) Kind {
+	return base[unit].kind
+}
+
+// Convert переводит количество между единицами. Объем и масса переводятся друг
+// в друга по плотности ингредиента, штучные единицы — по справочнику весов.
+func Convert(quantity float64, from, to, ingredient string) (float64, error) {
+	if from == to {
+		return quantity, nil
+	}
+
+	toBase, ok := base[to]
+	if !ok || toBase.kind == KindOther {
+		return 0, fmt.Errorf("%w: %s -> %s", ErrNotConvertible, from, to)
+	}
+
+	grams, gramsOK := Grams(quantity, from, ingredient)
+	if fromBase, ok := base[from]; ok && fromBase.kind == toBase.kind {
+		return quantity * fromBase.size / toBase.size, nil
+	}
+	if !gramsOK {
+		return 0, fmt.Errorf("%w: %s -> %s for %q", ErrNotConvertible, from, to, ingredient)
+	}
+
+	if toBase.kind == KindMass {
+		return grams / toBase.size, nil
+	}
+	density, ok := Densities[ingredient]
+	if !ok {
+		return 0, fmt.Errorf("%w: no density for %q", ErrNotConvertible, ingredient)
+	}
+	return grams / density / toBase.size, nil
+}
+
+// Grams переводит количество ингредиента в граммы
+func Grams(quantity float64, unit, ingredient string) (float64, bool) {
+	if b, ok := base[unit]; ok {
+		switch b.kind {
+		case KindMass:
+			return quantity * b.size, true
+		case KindVolume:
+			density, ok := Densities[ingredient]
+			if !ok {
+				return 0, false
+			}
+			return quantity * b.size * density, true
+		}
+	}
+
+	// Количество без единицы — штуки: «3 яйца»
+	if unit == "" {
+		unit = Piece
+	}
+	if weight, ok := PieceWeights[ingredient][unit]; ok {
+		return quantity * weight, true
+	}
+	return 0, false
+}
+
+// Round округляет количество до удобного для кухни значения: граммы и миллилитры
+// до целых или пятерок, ложки и стаканы до четвертей, штуки до половин
+func Round(quantity float64, unit string) float64 {
+	var step float64
+	switch KindOf(unit) {
+	case KindMass, KindVolume:
+		switch unit {
+		case Tablespoon, Teaspoon, Glass:
+			step = 0.25
+		case Kilogram, Liter:
+			step = 0.05
+		case Pinch:
+			step = 1
+		default:
+			step = 1
+			if quantity >= 50 {
+				step = 5
+			}
+		}
+	default:
+		step = 0.5
+	}
+
+	rounded := math.Round(quantity/step) * step
+	// Ненулевое количество не округляется до нуля
+	if rounded == 0 && quantity > 0 {
+		rounded = step
+	}
+	return math.Round(rounded*1000) / 1000
+}
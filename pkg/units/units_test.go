@@ -0,0 +1,37 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConvert проверяет перевод между объемом и массой
+func TestConvert(t *testing.T) {
+	grams, err := Convert(1, Glass, Gram, "мука пшеничная")
+	require.NoError(t, err)
+	assert.Equal(t, 130.0, grams)
+
+	spoons, err := Convert(30, Gram, Tablespoon, "сахар")
+	require.NoError(t, err)
+	assert.InDelta(t, 2.22, spoons, 0.01)
+
+	ml, err := Convert(1, Liter, Milliliter, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1000.0, ml)
+
+	_, err = Convert(1, Glass, Gram, "неизвестный порошок")
+	assert.ErrorIs(t, err, ErrNotConvertible)
+	_, err = Convert(1, Gram, Piece, "яйцо куриное")
+	assert.ErrorIs(t, err, ErrNotConvertible)
+}
+
+// TestRound проверяет округление до удобных значений
+func TestRound(t *testing.T) {
+	assert.Equal(t, 0.75, Round(0.7, Tablespoon))
+	assert.Equal(t, 135.0, Round(133.3, Gram))
+	assert.Equal(t, 13.0, Round(13.3, Gram))
+	assert.Equal(t, 1.5, Round(1.4, Piece))
+	assert.Equal(t, 0.5, Round(0.1, Piece))
+}
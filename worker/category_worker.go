@@ -5,24 +5,36 @@ import (
 
 	"github.com/gocolly/colly"
 	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/anomaly"
 	"github.com/seniorcat/scraper/pkg/cache"
+	"github.com/seniorcat/scraper/pkg/dedup"
 	"github.com/seniorcat/scraper/pkg/metrics"
 	"go.uber.org/zap"
 )
 
+// baseURL — адрес сайта-источника
+const baseURL = "https://eda.ru"
+
 // CategoryParser отвечает за логику парсинга категорий
 type CategoryParser struct {
 	Collector *colly.Collector
 	Logger    *zap.Logger
 	Limiter   *RateLimiter
 	timeout   time.Duration
-	Cache     *cache.MemoryCache
+	Cache     cache.Cache
+	Yield     *anomaly.Collector // Показатели извлечения для поиска аномалий (может быть nil)
 }
 
 // NewCategoryParser создает новый экземпляр CategoryParser
-func NewCategoryParser(logger *zap.Logger, rps int, timeout time.Duration, cache *cache.MemoryCache) *CategoryParser {
+func NewCategoryParser(logger *zap.Logger, rps int, timeout time.Duration, cache cache.Cache) *CategoryParser {
+	collector := colly.NewCollector()
+	if timeout > 0 {
+		collector.SetRequestTimeout(timeout)
+	}
+	instrumentCollector(collector, stageCategory)
+
 	return &CategoryParser{
-		Collector: colly.NewCollector(),
+		Collector: collector,
 		Logger:    logger,
 		Limiter:   NewRateLimiter(rps),
 		timeout:   timeout,
@@ -32,52 +44,58 @@ func NewCategoryParser(logger *zap.Logger, rps int, timeout time.Duration, cache
 
 // ParseCategories выполняет сбор всех категорий и отправляет их в канал
 func (p *CategoryParser) ParseCategories(categoryQueue chan<- entity.Category) error {
-	p.Collector.OnHTML(".emotion-18mh8uc .emotion-c3fqwx", func(e *colly.HTMLElement) {
-		// Увеличиваем счетчик запросов
-		metrics.RequestCounter.Inc()
-
-		// Извлечение имени категории
-		categoryName := e.DOM.Find("a .emotion-1ooehk6").Clone().Children().Remove().End().Text()
-
-		// Проверка через кеш, была ли категория уже обработана
-		if p.Cache.Exists(categoryName) {
-			p.Logger.Info("Category already cached, skipping", zap.String("Name", categoryName))
-			return
+	// Закрываем канал после завершения парсинга, в том числе при ошибке
+	defer close(categoryQueue)
+
+	p.Collector.OnHTML("html", func(e *colly.HTMLElement) {
+		// Категории извлекаются первой сработавшей стратегией
+		categories, _ := categoryChain.Run(e.DOM, e.Request.URL.String(), p.Logger)
+		for _, category := range categories {
+			p.processCategory(e, category, categoryQueue)
 		}
+	})
 
-		category := entity.Category{
-			Name: categoryName,
-			Href: e.ChildAttr("a", "href"),
-		}
-
-		// Нормализация данных категории
-		category.Normalize()
-
-		// Валидация категории
-		if err := category.Validate(); err != nil {
-			p.Logger.Error("Invalid category data", zap.Error(err))
-			return
-		}
+	// URL для парсинга
+	return p.Collector.Visit(baseURL)
+}
 
-		// Добавление в кеш
-		p.Cache.Set(categoryName)
+// processCategory проверяет извлеченную категорию и отправляет ее в канал
+func (p *CategoryParser) processCategory(e *colly.HTMLElement, category entity.Category, categoryQueue chan<- entity.Category) {
+	// Нормализация данных категории
+	category.Normalize()
+
+	// Валидация категории
+	if err := category.Validate(); err != nil {
+		metrics.ValidationErrors.WithLabelValues(e.Request.URL.Host, stageCategory).Inc()
+		p.Yield.ValidationError(stageCategory)
+		p.Logger.Error("Invalid category data", zap.Error(err))
+		return
+	}
 
-		p.Logger.Info("Category found", zap.String("Name", category.Name))
+	canonicalURL, err := dedup.CanonicalURL(baseURL, category.Href)
+	if err != nil {
+		p.Logger.Error("Invalid category href", zap.String("href", category.Href), zap.Error(err))
+		return
+	}
+	category.CanonicalURL = canonicalURL
 
-		// Отправляем категорию в канал
-		categoryQueue <- category
-	})
+	// Учитываем до проверки кеша: пропуск уже обработанной категории не означает поломку разметки
+	p.Yield.Extracted(stageCategory)
 
-	// URL для парсинга
-	err := p.Collector.Visit("https://eda.ru")
-	if err != nil {
-		return err
+	// Проверка через кеш, была ли категория уже обработана
+	if p.Cache.Exists(category.CanonicalURL) {
+		p.Logger.Info("Category already cached, skipping", zap.String("Name", category.Name))
+		return
 	}
 
-	// Закрываем канал после завершения парсинга
-	close(categoryQueue)
+	// Добавление в кеш
+	p.Cache.Set(category.CanonicalURL)
 
-	return nil
+	metrics.ItemsExtracted.WithLabelValues(e.Request.URL.Host, stageCategory).Inc()
+	p.Logger.Info("Category found", zap.String("Name", category.Name))
+
+	// Отправляем категорию в канал
+	categoryQueue <- category
 }
 
 // CategoryWorker управляет парсингом категорий
@@ -86,7 +104,7 @@ type CategoryWorker struct {
 }
 
 // NewCategoryWorker создает новый экземпляр CategoryWorker
-func NewCategoryWorker(logger *zap.Logger, rps int, timeout time.Duration, cache *cache.MemoryCache) *CategoryWorker {
+func NewCategoryWorker(logger *zap.Logger, rps int, timeout time.Duration, cache cache.Cache) *CategoryWorker {
 	parser := NewCategoryParser(logger, rps, timeout, cache)
 	return &CategoryWorker{Parser: parser}
 }
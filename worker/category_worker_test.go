@@ -3,6 +3,7 @@ package worker
 import (
 	"testing"
 
+	"github.com/seniorcat/scraper/entity"
 	"github.com/seniorcat/scraper/pkg/cache"
 	"go.uber.org/zap"
 )
@@ -13,9 +14,19 @@ func TestCategoryWorkerStart(t *testing.T) {
 	memCache := cache.NewMemoryCache() // Создаем новый кеш в памяти
 	categoryWorker := NewCategoryWorker(logger, 5, 10, memCache)
 
-	// Запуск парсинга категорий
-	categories, err := categoryWorker.Start()
-	if err != nil {
+	// Запуск парсинга категорий, канал закрывается по завершении
+	categoryQueue := make(chan entity.Category, 100)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- categoryWorker.Start(categoryQueue)
+	}()
+
+	var categories []entity.Category
+	for category := range categoryQueue {
+		categories = append(categories, category)
+	}
+
+	if err := <-errChan; err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// DesiredWorkers возвращает целевой размер пула воркеров рецептов
+func (tc *TaskController) DesiredWorkers() int {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.WorkersCount
+}
+
+// SetWorkersCount меняет размер пула во время работы. Лишние воркеры, начиная со
+// свободных, завершаются после текущей задачи, поэтому прогресс не теряется.
+func (tc *TaskController) SetWorkersCount(count int) error {
+	if count < 1 {
+		return fmt.Errorf("workers count must be positive, got %d", count)
+	}
+
+	tc.scaleMu.Lock()
+	defer tc.scaleMu.Unlock()
+
+	tc.mu.Lock()
+	previous := tc.WorkersCount
+	tc.WorkersCount = count
+	tc.mu.Unlock()
+
+	workers := tc.workers()
+	for i := len(workers); i < count; i++ {
+		tc.startWorker()
+	}
+
+	if excess := len(workers) - count; excess > 0 {
+		for _, w := range pickWorkersToStop(workers, excess) {
+			w.Quit()
+			tc.removeWorker(w)
+			tc.Tracker.RemoveWorker(w.ID)
+		}
+	}
+
+	tc.Logger.Info("Worker pool resized", zap.Int("from", previous), zap.Int("to", count))
+	return nil
+}
+
+// Pause приостанавливает выдачу задач воркерам; начатые задачи дорабатываются
+func (tc *TaskController) Pause() {
+	tc.Gate.Pause()
+	tc.Logger.Info("Task dispatching paused")
+}
+
+// Resume возобновляет выдачу задач воркерам
+func (tc *TaskController) Resume() {
+	tc.Gate.Resume()
+	tc.Logger.Info("Task dispatching resumed")
+}
+
+// Paused сообщает, приостановлена ли выдача задач
+func (tc *TaskController) Paused() bool {
+	return tc.Gate.Paused()
+}
+
+// RPS возвращает текущее ограничение запросов в секунду для каждого воркера
+func (tc *TaskController) RPS() int {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.rps
+}
+
+// SetRPS меняет ограничение запросов в секунду для всех воркеров, в том числе будущих.
+// Лимит действует на каждый воркер отдельно: пул в целом делает до WorkersCount × rps запросов.
+func (tc *TaskController) SetRPS(rps int) error {
+	if rps < 1 {
+		return fmt.Errorf("rps must be positive, got %d", rps)
+	}
+
+	tc.mu.Lock()
+	previous := tc.rps
+	tc.rps = rps
+	tc.mu.Unlock()
+
+	for _, w := range tc.workers() {
+		w.Parser.Limiter.SetRPS(rps)
+	}
+	if tc.CategoryWorker != nil {
+		tc.CategoryWorker.Parser.Limiter.SetRPS(rps)
+	}
+
+	tc.Logger.Info("Rate limit changed", zap.Int("from", previous), zap.Int("to", rps))
+	return nil
+}
+
+// pickWorkersToStop выбирает count воркеров для остановки, предпочитая свободные
+func pickWorkersToStop(workers []*RecipeWorker, count int) []*RecipeWorker {
+	picked := make([]*RecipeWorker, 0, count)
+
+	// Сначала свободные воркеры, затем занятые, с конца пула
+	for _, wantBusy := range []bool{false, true} {
+		for i := len(workers) - 1; i >= 0 && len(picked) < count; i-- {
+			if _, busy := workers[i].CurrentTask(); busy == wantBusy {
+				picked = append(picked, workers[i])
+			}
+		}
+	}
+
+	return picked
+}
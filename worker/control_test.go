@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// TestTaskControllerScale проверяет изменение размера пула во время работы
+func TestTaskControllerScale(t *testing.T) {
+	tc := NewTaskController(nil, 2, zap.NewNop(), time.Second, 1, nil)
+	tc.InitWorkerPool(1, 1, time.Second)
+	assert.Len(t, tc.workers(), 2)
+
+	assert.NoError(t, tc.SetWorkersCount(4))
+	assert.Len(t, tc.workers(), 4)
+	assert.Equal(t, 4, tc.DesiredWorkers())
+
+	assert.NoError(t, tc.SetWorkersCount(1))
+	assert.Len(t, tc.workers(), 1)
+	assert.Len(t, tc.WorkerStates(), 1)
+
+	assert.Error(t, tc.SetWorkersCount(0))
+
+	assert.NoError(t, tc.SetRPS(20))
+	assert.Equal(t, 20, tc.RPS())
+	assert.Error(t, tc.SetRPS(0))
+
+	tc.Stop()
+}
+
+// TestTaskControllerRPSPerWorker проверяет, что лимит запросов действует на каждый воркер
+// отдельно, а лимитер остановленного воркера перестает выдавать токены
+func TestTaskControllerRPSPerWorker(t *testing.T) {
+	tc := NewTaskController(nil, 3, zap.NewNop(), time.Second, 1, nil)
+	tc.InitWorkerPool(1, 1, time.Second)
+
+	assert.NoError(t, tc.SetRPS(20))
+	workers := tc.workers()
+	limiters := make(map[*RateLimiter]bool)
+	for _, w := range workers {
+		assert.Equal(t, 50*time.Millisecond, w.Parser.Limiter.Interval())
+		limiters[w.Parser.Limiter] = true
+	}
+	// У каждого воркера свой лимитер: пул делает до 3 × 20 запросов в секунду
+	assert.Len(t, limiters, 3)
+
+	// Новый воркер получает текущий лимит
+	assert.NoError(t, tc.SetWorkersCount(4))
+	assert.Equal(t, 50*time.Millisecond, tc.workers()[3].Parser.Limiter.Interval())
+
+	// Лимитеры воркеров, исключенных из пула, останавливаются
+	assert.NoError(t, tc.SetWorkersCount(1))
+	remaining := tc.workers()[0]
+	for _, w := range workers {
+		if w == remaining {
+			continue
+		}
+		assert.Eventually(t, func() bool {
+			select {
+			case <-w.Parser.Limiter.stop:
+				return true
+			default:
+				return false
+			}
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	tc.Stop()
+}
+
+// TestTaskControllerPause проверяет, что на паузе воркеры не берут задачи
+func TestTaskControllerPause(t *testing.T) {
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Second, 1, nil)
+	tc.Pause()
+	tc.InitWorkerPool(1, 1, time.Second)
+
+	tc.AddTask(Task{ID: "1", Type: "recipe", Category: &entity.Category{Name: "завтраки", Href: "/recepty/zavtraki"}})
+
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, tc.Paused())
+	assert.Len(t, tc.TaskQueue, 1)
+
+	tc.Resume()
+	assert.Eventually(t, func() bool { return len(tc.TaskQueue) == 0 }, time.Second, 10*time.Millisecond)
+
+	tc.Stop()
+}
+
+// TestTaskControllerStopPaused проверяет, что остановка на паузе не зависает
+func TestTaskControllerStopPaused(t *testing.T) {
+	tc := NewTaskController(nil, 2, zap.NewNop(), time.Second, 1, nil)
+	tc.InitWorkerPool(1, 1, time.Second)
+	tc.Pause()
+	time.Sleep(50 * time.Millisecond) // Воркеры доходят до ожидания шлюза
+
+	stopped := make(chan struct{})
+	go func() {
+		tc.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop на паузе не завершился")
+	}
+}
@@ -0,0 +1,293 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/diet"
+	"github.com/seniorcat/scraper/pkg/ingredient"
+	"github.com/seniorcat/scraper/pkg/nutrition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeDetails считает страницы части рецептов уже загруженными
+type fakeDetails struct {
+	fresh map[string]bool
+}
+
+func (f fakeDetails) StaleRecipes(ctx context.Context, recipes []entity.Recipe) ([]entity.Recipe, error) {
+	var stale []entity.Recipe
+	for _, recipe := range recipes {
+		if !f.fresh[recipe.CanonicalURL] {
+			stale = append(stale, recipe)
+		}
+	}
+	return stale, nil
+}
+
+// nopDB принимает сохранения без базы данных
+type nopDB struct{}
+
+func (nopDB) SaveCategories(ctx context.Context, categories []entity.Category) error { return nil }
+func (nopDB) SaveRecipes(ctx context.Context, recipes []entity.Recipe) error         { return nil }
+
+// receiveTasks ждет count задач из очереди контроллера
+func receiveTasks(t *testing.T, tc *TaskController, count int) []Task {
+	t.Helper()
+	var tasks []Task
+	for len(tasks) < count {
+		select {
+		case task := <-tc.TaskQueue:
+			tasks = append(tasks, task)
+		case <-time.After(time.Second):
+			t.Fatalf("enqueued %d of %d tasks", len(tasks), count)
+		}
+	}
+	return tasks
+}
+
+// TestDetailStage проверяет, что рецепты из списка категории ставят задачи загрузки их страниц
+func TestDetailStage(t *testing.T) {
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Second, 1, nopDB{})
+	tc.Details = fakeDetails{fresh: map[string]bool{"https://eda.ru/recepty/supy/borsch-1": true}}
+
+	category := &entity.Category{Name: "супы", Href: "/recepty/supy", CanonicalURL: "https://eda.ru/recepty/supy"}
+	listing := Result{TaskID: "супы", Type: TaskListing, Category: category, Recipes: []entity.Recipe{
+		{Name: "борщ", Href: "/recepty/supy/borsch-1", CanonicalURL: "https://eda.ru/recepty/supy/borsch-1"},
+		{Name: "щи", Href: "/recepty/supy/schi-2", CanonicalURL: "https://eda.ru/recepty/supy/schi-2"},
+		{Name: "уха", Href: "/recepty/supy/uha-3", CanonicalURL: "https://eda.ru/recepty/supy/uha-3"},
+	}}
+	tc.processResult(context.Background(), listing)
+
+	// Страница борща уже загружена, а карточка не изменилась
+	tasks := receiveTasks(t, tc, 2)
+	var urls []string
+	for _, task := range tasks {
+		assert.Equal(t, TaskRecipeDetail, task.Type)
+		payload := task.Payload.(RecipeDetailPayload)
+		assert.Equal(t, payload.URL, payload.CanonicalURL)
+		assert.Equal(t, "detail:"+payload.URL, task.ID)
+		urls = append(urls, payload.URL)
+	}
+	assert.ElementsMatch(t, []string{"https://eda.ru/recepty/supy/schi-2", "https://eda.ru/recepty/supy/uha-3"}, urls)
+
+	// Рецепт из другой категории в том же запуске повторно не загружается
+	tc.processResult(context.Background(), Result{TaskID: "обеды", Type: TaskListing, Recipes: listing.Recipes[1:2]})
+
+	// Результат страницы рецепта новых задач страниц не ставит
+	detail := listing.Recipes[2]
+	detail.Detailed = true
+	detail.Ingredients = []string{"рыба 500 г"}
+	tc.processResult(context.Background(), Result{TaskID: tasks[0].ID, Type: TaskRecipeDetail, Recipes: []entity.Recipe{detail}})
+
+	select {
+	case task := <-tc.TaskQueue:
+		t.Fatalf("unexpected task %s", task.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// recordingDB запоминает сохраненные рецепты
+type recordingDB struct {
+	mu      sync.Mutex
+	recipes map[string]entity.Recipe // По каноническому URL; рецепт со страницы заменяет карточку
+}
+
+func (db *recordingDB) SaveCategories(ctx context.Context, categories []entity.Category) error {
+	return nil
+}
+
+func (db *recordingDB) SaveRecipes(ctx context.Context, recipes []entity.Recipe) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, recipe := range recipes {
+		db.recipes[recipe.CanonicalURL] = recipe
+	}
+	return nil
+}
+
+// detailed возвращает сохраненные рецепты со страниц рецептов
+func (db *recordingDB) detailed() map[string]entity.Recipe {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	detailed := make(map[string]entity.Recipe)
+	for url, recipe := range db.recipes {
+		if recipe.Detailed {
+			detailed[url] = recipe
+		}
+	}
+	return detailed
+}
+
+// runDetailPipeline отдает результат списка категории с карточками страниц pages
+// контроллеру и возвращает рецепты, сохраненные после загрузки этих страниц
+func runDetailPipeline(t *testing.T, tc *TaskController, db *recordingDB, pages map[string]string) map[string]entity.Recipe {
+	t.Helper()
+	mux := http.NewServeMux()
+	for path, html := range pages {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(html))
+		})
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	listing := Result{TaskID: "категория", Type: TaskListing}
+	for path := range pages {
+		listing.Recipes = append(listing.Recipes, entity.Recipe{Name: "карточка", Href: path, CanonicalURL: server.URL + path})
+	}
+
+	tc.Start(1, 100, time.Second)
+	defer tc.Stop()
+	tc.ResultQueue <- listing
+
+	require.Eventually(t, func() bool { return len(db.detailed()) == len(pages) }, 5*time.Second, 20*time.Millisecond)
+	saved := make(map[string]entity.Recipe)
+	for url, recipe := range db.detailed() {
+		saved[strings.TrimPrefix(url, server.URL)] = recipe
+	}
+	return saved
+}
+
+// TestDetailPipeline проверяет, что рецепты со страниц, поставленных из списка
+// категории, извлекаются из каждого вида разметки и обогащаются до сохранения
+func TestDetailPipeline(t *testing.T) {
+	catalog, err := ingredient.DefaultCatalog()
+	require.NoError(t, err)
+	table, err := nutrition.DefaultTable()
+	require.NoError(t, err)
+	classifier, err := diet.NewClassifier([]diet.Rule{
+		{Tag: "lactose-free", Excludes: []string{"молоко", "сливки"}},
+		{Tag: "vegetarian", Excludes: []string{"курица", "говядина"}},
+	}, catalog)
+	require.NoError(t, err)
+	db := &recordingDB{recipes: make(map[string]entity.Recipe)}
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Second, 1, db)
+	tc.Catalog = catalog
+	tc.Nutrition = nutrition.NewCalculator(table)
+	tc.Diet = classifier
+
+	tests := []struct {
+		name        string
+		path        string
+		html        string
+		recipe      string
+		ingredients []string
+		quantities  []float64 // Разобранные количества ингредиентов
+		units       []string  // Разобранные единицы ингредиентов
+		canonical   []string  // Названия ингредиентов из каталога
+		servings    int
+		coverage    float64           // Доля ингредиентов, учтенных в расчете пищевой ценности
+		site        *entity.Nutrition // Пищевая ценность, указанная на сайте
+		tags        []string
+		seconds     [3]int // Время подготовки, приготовления и общее в секундах
+		yield       string
+	}{
+		{
+			name: "microdata",
+			path: "/recepty/zavtraki/syrniki-1",
+			html: `<html><body><div itemscope itemtype="https://schema.org/Recipe">
+<h1 itemprop="name">Сырники</h1>
+<meta itemprop="recipeYield" content="4 порции">
+<li itemprop="recipeIngredient">Творог 500 г</li><li itemprop="recipeIngredient">Яйца 2 шт.</li>
+</div></body></html>`,
+			recipe:      "сырники",
+			ingredients: []string{"Творог 500 г", "Яйца 2 шт."},
+			quantities:  []float64{500, 2},
+			units:       []string{ingredient.UnitGram, ingredient.UnitPiece},
+			canonical:   []string{"творог", "яйцо куриное"},
+			servings:    4,
+			coverage:    1,
+			tags:        []string{"lactose-free", "vegetarian"},
+			yield:       "4 порции",
+		},
+		{
+			name: "rdfa",
+			path: "/recepty/supy/borsch-2",
+			html: `<html><body><div vocab="https://schema.org/" typeof="Recipe">
+<h1 property="name">Борщ</h1>
+<meta property="recipeYield" content="6 порций">
+<li property="recipeIngredient">Свекла 2 шт.</li><li property="recipeIngredient">Капуста 300 г</li>
+</div></body></html>`,
+			recipe:      "борщ",
+			ingredients: []string{"Свекла 2 шт.", "Капуста 300 г"},
+			quantities:  []float64{2, 300},
+			units:       []string{ingredient.UnitPiece, ingredient.UnitGram},
+			canonical:   []string{"свекла", "капуста белокочанная"},
+			servings:    6,
+			coverage:    1,
+			tags:        []string{"lactose-free", "vegetarian"},
+			yield:       "6 порций",
+		},
+		{
+			name: "json-ld",
+			path: "/recepty/vypechka/bliny-3",
+			html: `<html><head><script type="application/ld+json">{
+"@context": "https://schema.org", "@type": "Recipe", "name": "Блины",
+"recipeYield": "4 порции", "prepTime": "PT10M", "cookTime": "PT20M", "totalTime": "PT30M",
+"recipeIngredient": ["Молоко 0,5 л", "Мука пшеничная 1 ½ стакана", "2 ст. л. сахара", "Соль щепотка"],
+"nutrition": {"@type": "NutritionInformation", "calories": "250 ккал"}
+}</script></head><body></body></html>`,
+			recipe:      "блины",
+			ingredients: []string{"Молоко 0,5 л", "Мука пшеничная 1 ½ стакана", "2 ст. л. сахара", "Соль щепотка"},
+			quantities:  []float64{0.5, 1.5, 2, 0},
+			units:       []string{ingredient.UnitLiter, ingredient.UnitGlass, ingredient.UnitTablespoon, ingredient.UnitPinch},
+			canonical:   []string{"молоко", "мука пшеничная", "сахар", "соль"},
+			servings:    4,
+			coverage:    1,
+			site:        &entity.Nutrition{Calories: 250},
+			tags:        []string{"vegetarian"},
+			seconds:     [3]int{600, 1200, 1800},
+			yield:       "4 порции",
+		},
+	}
+
+	pages := make(map[string]string)
+	for _, tt := range tests {
+		pages[tt.path] = tt.html
+	}
+	saved := runDetailPipeline(t, tc, db, pages)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipe := saved[tt.path]
+			assert.Equal(t, tt.recipe, recipe.Name)
+			assert.Equal(t, tt.ingredients, recipe.Ingredients)
+
+			var quantities []float64
+			var units, canonical []string
+			for _, parsed := range recipe.Parsed {
+				quantities = append(quantities, parsed.Quantity)
+				units = append(units, parsed.Unit)
+				canonical = append(canonical, parsed.Canonical)
+			}
+			assert.Equal(t, tt.quantities, quantities)
+			assert.Equal(t, tt.units, units)
+			assert.Equal(t, tt.canonical, canonical)
+
+			require.NotNil(t, recipe.Nutrition)
+			assert.NotNil(t, recipe.Nutrition.Computed)
+			assert.Equal(t, tt.servings, recipe.Nutrition.Servings)
+			assert.Equal(t, tt.coverage, recipe.Nutrition.Coverage)
+			assert.Equal(t, tt.site, recipe.Nutrition.Site)
+
+			var tags []string
+			for _, tag := range recipe.Tags {
+				tags = append(tags, tag.Tag)
+			}
+			assert.Equal(t, tt.tags, tags)
+			require.NotNil(t, recipe.Details)
+			assert.Equal(t, tt.yield, recipe.Details.Yield)
+			assert.Equal(t, tt.seconds, [3]int{recipe.Details.PrepSeconds, recipe.Details.CookSeconds, recipe.Details.TotalSeconds})
+		})
+	}
+}
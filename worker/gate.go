@@ -0,0 +1,67 @@
+package worker
+
+import "sync"
+
+// openGate — всегда закрытый канал, которым nil-шлюз сообщает, что он открыт
+var openGate = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// Gate приостанавливает выдачу задач воркерам без потери прогресса:
+// задачи остаются в очереди, а уже начатые дорабатываются
+type Gate struct {
+	mu     sync.Mutex
+	open   chan struct{} // Закрыт, пока шлюз открыт
+	paused bool
+}
+
+// NewGate создает открытый шлюз
+func NewGate() *Gate {
+	return &Gate{open: openGate}
+}
+
+// Pause закрывает шлюз
+func (g *Gate) Pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.paused {
+		g.paused = true
+		g.open = make(chan struct{})
+	}
+}
+
+// Resume открывает шлюз и пропускает ожидающих воркеров
+func (g *Gate) Resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused {
+		g.paused = false
+		close(g.open)
+	}
+}
+
+// Paused сообщает, закрыт ли шлюз
+func (g *Gate) Paused() bool {
+	if g == nil {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// Wait возвращает канал, который закрыт, пока шлюз открыт
+func (g *Gate) Wait() <-chan struct{} {
+	if g == nil {
+		return openGate
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.open
+}
@@ -0,0 +1,312 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gocolly/colly"
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/cooktime"
+	"github.com/seniorcat/scraper/pkg/dedup"
+	"github.com/seniorcat/scraper/pkg/ingredient"
+	"github.com/seniorcat/scraper/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// RecipeDetailPayload — нагрузка задачи TaskRecipeDetail
+type RecipeDetailPayload struct {
+	URL          string // Абсолютный адрес страницы рецепта
+	CanonicalURL string // Канонический URL карточки рецепта; рецепт сохраняется под ним, чтобы дополнить ту же запись
+}
+
+// ImagePayload — нагрузка задачи TaskImageDownload
+type ImagePayload struct {
+	URL       string // Адрес изображения
+	RecipeURL string // Канонический URL рецепта, к которому относится изображение
+	Kind      string // entity.ImageHero или entity.ImageStep
+	Position  int    // Порядковый номер среди изображений того же вида
+}
+
+// LinkCheckPayload — нагрузка задачи TaskLinkCheck
+type LinkCheckPayload struct {
+	URL string
+}
+
+// Image — результат задачи TaskImageDownload
+type Image struct {
+	URL         string
+	RecipeURL   string
+	ContentType string
+	Body        []byte
+}
+
+// LinkStatus — результат задачи TaskLinkCheck
+type LinkStatus struct {
+	URL        string
+	StatusCode int
+	Alive      bool // Ответ без ошибки 4xx/5xx
+}
+
+// Gone — результат задачи, страница которой удалена с сайта (ответ 404 или 410)
+type Gone struct {
+	URL        string // Канонический URL страницы
+	StatusCode int
+}
+
+// GoneError возвращается парсером, если страница ответила 404 или 410
+type GoneError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *GoneError) Error() string {
+	return fmt.Sprintf("page %s is gone: http %d", e.URL, e.StatusCode)
+}
+
+// goneResult превращает GoneError в результат Gone; остальные ошибки возвращаются как есть
+func goneResult(err error) (Result, error) {
+	var gone *GoneError
+	if !errors.As(err, &gone) {
+		return Result{}, err
+	}
+
+	canonicalURL, cerr := dedup.CanonicalURL(gone.URL, gone.URL)
+	if cerr != nil {
+		return Result{}, err
+	}
+	return Result{Data: Gone{URL: canonicalURL, StatusCode: gone.StatusCode}}, nil
+}
+
+// CategoryCrawlHandler создает обработчик обхода списка категорий; найденные
+// категории возвращаются в Result.Data как []entity.Category
+func CategoryCrawlHandler(categoryWorker *CategoryWorker) Handler {
+	return HandlerFunc(func(ctx context.Context, w *RecipeWorker, task Task) (Result, error) {
+		categoryQueue := make(chan entity.Category)
+		done := make(chan error, 1)
+		go func() { done <- categoryWorker.Start(categoryQueue) }()
+
+		var categories []entity.Category
+		for category := range categoryQueue {
+			categories = append(categories, category)
+		}
+		if err := <-done; err != nil {
+			return Result{}, err
+		}
+		return Result{Data: categories}, nil
+	})
+}
+
+// handleListing собирает ссылки на рецепты со страницы категории
+func handleListing(ctx context.Context, w *RecipeWorker, task Task) (Result, error) {
+	if task.Category == nil {
+		return Result{}, fmt.Errorf("listing task %s has no category", task.ID)
+	}
+
+	recipes, listed, err := w.Parser.ParseListing(*task.Category)
+	if err != nil {
+		return goneResult(err)
+	}
+	return Result{Category: task.Category, Recipes: recipes, Listed: listed}, nil
+}
+
+// handleRecipeDetail загружает страницу отдельного рецепта
+func handleRecipeDetail(ctx context.Context, w *RecipeWorker, task Task, payload RecipeDetailPayload) (Result, error) {
+	recipe, err := w.Parser.ParseRecipeDetail(payload.URL)
+	if err != nil {
+		return goneResult(err)
+	}
+	if payload.CanonicalURL != "" {
+		recipe.CanonicalURL = payload.CanonicalURL
+	}
+	return Result{Recipes: []entity.Recipe{recipe}}, nil
+}
+
+// handleImageDownload загружает изображение рецепта. Если задан обработчик
+// изображений, оно сохраняется в хранилище вместе с уменьшенными копиями и
+// результатом становится entity.RecipeImage.
+func handleImageDownload(ctx context.Context, w *RecipeWorker, task Task, payload ImagePayload) (Result, error) {
+	image, err := w.Parser.DownloadImage(payload.URL)
+	if err != nil {
+		return Result{}, err
+	}
+	image.RecipeURL = payload.RecipeURL
+	if w.Parser.Imaging == nil {
+		return Result{Data: image}, nil
+	}
+
+	stored, err := w.Parser.Imaging.Process(ctx, image.Body)
+	if err != nil {
+		return Result{}, fmt.Errorf("image %s: %w", payload.URL, err)
+	}
+	stored.RecipeURL = payload.RecipeURL
+	stored.SourceURL = payload.URL
+	stored.Kind = payload.Kind
+	stored.Position = payload.Position
+	return Result{Data: stored}, nil
+}
+
+// handleLinkCheck проверяет доступность ссылки
+func handleLinkCheck(ctx context.Context, w *RecipeWorker, task Task, payload LinkCheckPayload) (Result, error) {
+	status, err := w.Parser.CheckLink(payload.URL)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Data: status}, nil
+}
+
+// reportUnknownTask учитывает задачу, для типа которой нет обработчика
+func reportUnknownTask(logger *zap.Logger, tracker *StatusTracker, task Task) {
+	metrics.UnknownTaskTypes.WithLabelValues(task.Type).Inc()
+	logger.Error("Task rejected: no handler for task type",
+		zap.String("task_id", task.ID),
+		zap.String("type", task.Type))
+	tracker.TaskRejected(task, fmt.Errorf("%w %q", ErrUnknownTaskType, task.Type))
+}
+
+// ParseRecipeDetail извлекает рецепт со страницы рецепта
+func (p *RecipeParser) ParseRecipeDetail(pageURL string) (entity.Recipe, error) {
+	var recipe entity.Recipe
+	var canonicalHref string
+
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return recipe, err
+	}
+
+	p.Limiter.TakeToken() // Ограничение скорости запросов
+
+	collector := p.Collector.Clone()
+	instrumentCollector(collector, stageRecipeDetail)
+
+	collector.OnHTML("html", func(e *colly.HTMLElement) {
+		canonicalHref = e.DOM.Find(`link[rel="canonical"]`).AttrOr("href", "")
+
+		// Каждое поле извлекается первой сработавшей стратегией
+		recipe.Name, _ = recipeNameChain.Run(e.DOM, pageURL, p.Logger)
+		if image, ok := recipeImageChain.Run(e.DOM, pageURL, p.Logger); ok {
+			recipe.ImageURL = e.Request.AbsoluteURL(image)
+		}
+		recipe.Ingredients, _ = recipeIngredientsChain.Run(e.DOM, pageURL, p.Logger)
+		if details, ok := recipeDetailsChain.Run(e.DOM, pageURL, p.Logger); ok {
+			for i, image := range details.Images {
+				details.Images[i] = e.Request.AbsoluteURL(image)
+			}
+			for i, image := range details.StepImages {
+				details.StepImages[i] = e.Request.AbsoluteURL(image)
+			}
+			if !cooktime.Normalize(details) {
+				p.Logger.Warn("Время подготовки и приготовления не сходится с общим временем",
+					zap.String("url", pageURL), zap.String("prep", details.PrepTime),
+					zap.String("cook", details.CookTime), zap.String("total", details.TotalTime))
+			}
+			recipe.Details = details
+		}
+	})
+
+	statusCode := 0
+	collector.OnError(func(r *colly.Response, err error) {
+		statusCode = r.StatusCode
+	})
+
+	if err := collector.Visit(pageURL); err != nil {
+		if entity.IsGoneStatus(statusCode) {
+			return entity.Recipe{}, &GoneError{URL: pageURL, StatusCode: statusCode}
+		}
+		return entity.Recipe{}, err
+	}
+
+	recipe.Href = page.RequestURI()
+	recipe.Detailed = true
+	recipe.Normalize()
+	recipe.Parsed = ingredient.ParseAll(recipe.Ingredients)
+	p.Catalog.Link(recipe.Parsed)
+	recipe.Tags = p.Diet.Classify(recipe.Parsed)
+	if err := recipe.Validate(); err != nil {
+		metrics.ValidationErrors.WithLabelValues(page.Host, stageRecipeDetail).Inc()
+		p.Yield.ValidationError(stageRecipeDetail)
+		return entity.Recipe{}, err
+	}
+
+	recipe.CanonicalURL, err = dedup.ResolveCanonical(pageURL, canonicalHref)
+	if err != nil {
+		return entity.Recipe{}, err
+	}
+
+	recipe.Nutrition = p.Nutrition.Analyze(recipe)
+	if recipe.Nutrition != nil && len(recipe.Nutrition.Discrepancies) > 0 {
+		for _, field := range recipe.Nutrition.Discrepancies {
+			metrics.NutritionDiscrepancies.WithLabelValues(field).Inc()
+		}
+		p.Logger.Warn("Пищевая ценность на сайте расходится с расчетом",
+			zap.String("url", recipe.CanonicalURL), zap.Strings("fields", recipe.Nutrition.Discrepancies))
+	}
+	recipe.Fingerprint = dedup.Fingerprint(recipe)
+
+	metrics.ItemsExtracted.WithLabelValues(page.Host, stageRecipeDetail).Inc()
+	p.Yield.Extracted(stageRecipeDetail)
+	if len(recipe.Ingredients) == 0 {
+		p.Yield.EmptyField(stageRecipeDetail, "ingredients")
+	}
+	if recipe.ImageURL == "" {
+		p.Yield.EmptyField(stageRecipeDetail, "image")
+	}
+	return recipe, nil
+}
+
+// DownloadImage загружает изображение; ответ с другим типом содержимого считается ошибкой
+func (p *RecipeParser) DownloadImage(imageURL string) (Image, error) {
+	image := Image{URL: imageURL}
+
+	// Общий лимитер не дает скорости загрузки расти с числом воркеров
+	if p.ImageLimit != nil {
+		p.ImageLimit.TakeToken()
+	} else {
+		p.Limiter.TakeToken()
+	}
+
+	collector := p.Collector.Clone()
+	instrumentCollector(collector, stageImage)
+	collector.OnResponse(func(r *colly.Response) {
+		image.ContentType = r.Headers.Get("Content-Type")
+		image.Body = r.Body
+	})
+
+	if err := collector.Visit(imageURL); err != nil {
+		return Image{}, err
+	}
+	if !strings.HasPrefix(image.ContentType, "image/") {
+		return Image{}, fmt.Errorf("unexpected content type %q for image %s", image.ContentType, imageURL)
+	}
+	return image, nil
+}
+
+// CheckLink проверяет ссылку запросом HEAD. Ответ 4xx/5xx — результат проверки,
+// а не ошибка; ошибкой считается только отсутствие ответа.
+func (p *RecipeParser) CheckLink(link string) (LinkStatus, error) {
+	status := LinkStatus{URL: link}
+
+	p.Limiter.TakeToken() // Ограничение скорости запросов
+
+	collector := p.Collector.Clone()
+	instrumentCollector(collector, stageLinkCheck)
+	collector.OnResponse(func(r *colly.Response) {
+		status.StatusCode = r.StatusCode
+	})
+	collector.OnError(func(r *colly.Response, err error) {
+		status.StatusCode = r.StatusCode
+	})
+
+	err := collector.Head(link)
+	if status.StatusCode == 0 {
+		if err == nil {
+			err = errors.New("no response")
+		}
+		return LinkStatus{}, err
+	}
+
+	status.Alive = status.StatusCode < 400
+	return status, nil
+}
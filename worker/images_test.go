@@ -0,0 +1,165 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/blob"
+	"github.com/seniorcat/scraper/pkg/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeImages хранит сведения об изображениях в памяти
+type fakeImages struct {
+	mu    sync.Mutex
+	saved map[string]entity.RecipeImage // По адресу изображения
+}
+
+func (f *fakeImages) SaveImage(ctx context.Context, image entity.RecipeImage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.saved[image.SourceURL] = image
+	return nil
+}
+
+func (f *fakeImages) MissingImages(ctx context.Context, images []entity.RecipeImage) ([]entity.RecipeImage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var missing []entity.RecipeImage
+	for _, image := range images {
+		if _, ok := f.saved[image.SourceURL]; !ok {
+			missing = append(missing, image)
+		}
+	}
+	return missing, nil
+}
+
+// TestImageStage проверяет постановку задач изображений и сохранение загруженного изображения
+func TestImageStage(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 400, 200))))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/img/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	images := &fakeImages{saved: map[string]entity.RecipeImage{
+		server.URL + "/img/old.png": {SourceURL: server.URL + "/img/old.png"},
+	}}
+
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Second, 1, nopDB{})
+	tc.Imaging = imaging.NewProcessor(store, []int{100})
+	tc.Images = images
+
+	recipe := entity.Recipe{
+		Name:         "борщ",
+		Href:         "/recepty/supy/borsch-1",
+		CanonicalURL: "https://eda.ru/recepty/supy/borsch-1",
+		ImageURL:     server.URL + "/img/hero.png",
+		Details: &entity.RecipeDetails{
+			Images:     []string{server.URL + "/img/hero.png", server.URL + "/img/old.png"},
+			StepImages: []string{server.URL + "/img/step.png"},
+		},
+	}
+	tc.processResult(context.Background(), Result{TaskID: "detail", Type: TaskRecipeDetail, Recipes: []entity.Recipe{recipe}})
+
+	// Уже загруженное изображение и повтор основного в очередь не попадают
+	var tasks []Task
+	for len(tasks) < 2 {
+		select {
+		case task := <-tc.TaskQueue:
+			tasks = append(tasks, task)
+		case <-time.After(time.Second):
+			t.Fatal("image tasks were not enqueued")
+		}
+	}
+	assert.Empty(t, tc.TaskQueue)
+	payloads := map[string]ImagePayload{}
+	for _, task := range tasks {
+		assert.Equal(t, TaskImageDownload, task.Type)
+		payload := task.Payload.(ImagePayload)
+		payloads[payload.Kind] = payload
+	}
+	assert.Equal(t, ImagePayload{URL: server.URL + "/img/hero.png", RecipeURL: recipe.CanonicalURL, Kind: entity.ImageHero}, payloads[entity.ImageHero])
+	assert.Equal(t, ImagePayload{URL: server.URL + "/img/step.png", RecipeURL: recipe.CanonicalURL, Kind: entity.ImageStep}, payloads[entity.ImageStep])
+
+	// Повторный результат в том же запуске задач не ставит
+	tc.processResult(context.Background(), Result{TaskID: "detail", Type: TaskRecipeDetail, Recipes: []entity.Recipe{recipe}})
+
+	// Воркер загружает изображение и сохраняет его в хранилище
+	w := NewRecipeWorker(zap.NewNop(), 1, 100, time.Second)
+	w.Parser.Imaging = tc.Imaging
+	results := make(chan Result, 1)
+	w.processTask(tasks[0], results)
+	result := <-results
+	stored, ok := result.Data.(entity.RecipeImage)
+	require.True(t, ok)
+	assert.Equal(t, 400, stored.Width)
+	assert.Equal(t, "image/png", stored.MimeType)
+	require.Len(t, stored.Thumbnails, 1)
+	assert.Equal(t, 50, stored.Thumbnails[0].Height)
+
+	tc.processResult(context.Background(), result)
+	saved, ok := images.saved[stored.SourceURL]
+	require.True(t, ok)
+	assert.Equal(t, stored.Key, saved.Key)
+	assert.Equal(t, recipe.CanonicalURL, saved.RecipeURL)
+
+	select {
+	case task := <-tc.TaskQueue:
+		t.Fatalf("unexpected task %s", task.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestImageLimit проверяет, что воркеры загружают изображения через общий лимитер контроллера
+func TestImageLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer server.Close()
+
+	// Лимитер без генератора: токены выдает только тест
+	limit := &RateLimiter{TokenCh: make(chan struct{}, 2)}
+	tc := NewTaskController(nil, 2, zap.NewNop(), time.Second, 1, nopDB{})
+	tc.ImageLimit = limit
+	tc.InitWorkerPool(1, 100, time.Second)
+	defer tc.Stop()
+
+	downloaded := make(chan error, 2)
+	for _, w := range tc.RecipeWorkers {
+		require.Same(t, limit, w.Parser.ImageLimit)
+		go func(p *RecipeParser) {
+			_, err := p.DownloadImage(server.URL + "/img.png")
+			downloaded <- err
+		}(w.Parser)
+	}
+
+	// Собственные лимитеры воркеров не пропускают загрузку без общего токена
+	select {
+	case <-downloaded:
+		t.Fatal("image downloaded without a shared token")
+	case <-time.After(100 * time.Millisecond):
+	}
+	limit.TokenCh <- struct{}{}
+	limit.TokenCh <- struct{}{}
+	for range tc.RecipeWorkers {
+		require.NoError(t, <-downloaded)
+	}
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gocolly/colly"
+	"github.com/seniorcat/scraper/pkg/metrics"
+)
+
+// Этапы обхода, используемые в метках метрик
+const (
+	stageCategory     = "category"
+	stageRecipe       = "recipe"
+	stageRecipeDetail = "recipe_detail"
+	stageImage        = "image"
+	stageLinkCheck    = "link_check"
+)
+
+// requestStartKey — ключ контекста запроса colly с временем его отправки
+const requestStartKey = "metrics_request_start"
+
+// instrumentCollector добавляет в коллектор сбор метрик по каждому запросу
+func instrumentCollector(c *colly.Collector, stage string) {
+	c.OnRequest(func(r *colly.Request) {
+		metrics.RequestCounter.Inc()
+		r.Ctx.Put(requestStartKey, time.Now())
+	})
+
+	c.OnResponse(func(r *colly.Response) {
+		site := r.Request.URL.Host
+		observeFetchDuration(r, site, stage)
+		metrics.ResponseSize.WithLabelValues(site, stage).Observe(float64(len(r.Body)))
+		metrics.ResponsesTotal.WithLabelValues(site, stage, strconv.Itoa(r.StatusCode)).Inc()
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		site := r.Request.URL.Host
+		observeFetchDuration(r, site, stage)
+
+		status := "error"
+		if r.StatusCode != 0 {
+			status = strconv.Itoa(r.StatusCode)
+		}
+		metrics.ResponsesTotal.WithLabelValues(site, stage, status).Inc()
+	})
+}
+
+// observeFetchDuration учитывает время от отправки запроса до ответа
+func observeFetchDuration(r *colly.Response, site, stage string) {
+	start, ok := r.Ctx.GetAny(requestStartKey).(time.Time)
+	if !ok {
+		return
+	}
+	metrics.FetchDuration.WithLabelValues(site, stage).Observe(time.Since(start).Seconds())
+}
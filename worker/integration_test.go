@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/seniorcat/scraper/entity"
 	"github.com/seniorcat/scraper/pkg/cache"
 	"go.uber.org/zap"
 )
@@ -31,15 +32,27 @@ func TestWorkerIntegration(t *testing.T) {
 		defer wg.Done()
 
 		// Шаг 1: Парсинг категорий
-		categories, err := categoryWorker.Start()
-		if err != nil {
+		categoryQueue := make(chan entity.Category, 100)
+		startErr := make(chan error, 1)
+		go func() {
+			startErr <- categoryWorker.Start(categoryQueue)
+		}()
+
+		var categories []entity.Category
+		for category := range categoryQueue {
+			categories = append(categories, category)
+		}
+
+		if err := <-startErr; err != nil {
 			errChan <- err // Передаем ошибку через канал
+			close(taskQueue)
 			return
 		}
 
 		// Проверка, что есть хотя бы одна категория
 		if len(categories) == 0 {
 			errChan <- fmt.Errorf("expected at least one category, got %d", len(categories))
+			close(taskQueue)
 			return
 		}
 
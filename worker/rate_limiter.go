@@ -1,41 +1,113 @@
 package worker
 
 import (
+	"sync"
 	"time"
+
+	"github.com/seniorcat/scraper/pkg/metrics"
 )
 
-// RateLimiter отвечает за ограничение скорости запросов
+// maxBurst — наибольшая пачка токенов, которую лимитер может накопить после SetRPS
+const maxBurst = 100
+
+// RateLimiter отвечает за ограничение скорости запросов. Каждый воркер получает
+// собственный лимитер, поэтому общий поток запросов пула — число воркеров × rps.
 type RateLimiter struct {
-	Interval time.Duration // Интервал между запросами
-	TokenCh  chan struct{}
+	TokenCh chan struct{}
+
+	mu       sync.Mutex
+	interval time.Duration      // Интервал между токенами
+	burst    int                // Сколько токенов можно накопить
+	resetCh  chan time.Duration // Новый интервал, заданный во время работы
+	stop     chan struct{}      // Закрывается, чтобы остановить выдачу токенов
+	stopOnce sync.Once
 }
 
 // NewRateLimiter создает новый лимитер с заданным интервалом
 func NewRateLimiter(rps int) *RateLimiter {
 	rl := &RateLimiter{
-		Interval: time.Second / time.Duration(rps), // Интервал между токенами
-		TokenCh:  make(chan struct{}, rps),         // Канал для токенов
+		TokenCh:  make(chan struct{}, max(rps, maxBurst)), // Канал для токенов
+		interval: time.Second / time.Duration(rps),        // Интервал между токенами
+		burst:    rps,
+		resetCh:  make(chan time.Duration, 1),
+		stop:     make(chan struct{}),
 	}
 
 	// Запускаем горутину для периодической выдачи токенов
-	go rl.generateTokens()
+	go rl.generateTokens(rl.interval)
 	return rl
 }
 
-// generateTokens добавляет токены в канал с заданным интервалом
-func (rl *RateLimiter) generateTokens() {
-	ticker := time.NewTicker(rl.Interval)
+// generateTokens добавляет токены в канал с заданным интервалом до остановки лимитера
+func (rl *RateLimiter) generateTokens(interval time.Duration) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
-	for range ticker.C {
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case <-ticker.C:
+			rl.mu.Lock()
+			if len(rl.TokenCh) < rl.burst {
+				select {
+				case rl.TokenCh <- struct{}{}:
+				default:
+					// Если канал переполнен, пропускаем токен
+				}
+			}
+			rl.mu.Unlock()
+		case interval := <-rl.resetCh:
+			ticker.Reset(interval)
+		}
+	}
+}
+
+// Interval возвращает текущий интервал между токенами
+func (rl *RateLimiter) Interval() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.interval
+}
+
+// SetRPS меняет ограничение скорости во время работы вместе с размером пачки токенов.
+// Не блокируется, даже если лимитер уже остановлен.
+func (rl *RateLimiter) SetRPS(rps int) {
+	if rps <= 0 {
+		return
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.interval = time.Second / time.Duration(rps)
+	rl.burst = min(rps, cap(rl.TokenCh))
+
+	// Накопленные сверх новой пачки токены отбрасываются
+	for len(rl.TokenCh) > rl.burst {
 		select {
-		case rl.TokenCh <- struct{}{}:
+		case <-rl.TokenCh:
 		default:
-			// Если канал переполнен, пропускаем токен
 		}
 	}
+
+	// Непрочитанный прежний интервал заменяется новым
+	select {
+	case <-rl.resetCh:
+	default:
+	}
+	rl.resetCh <- rl.interval
+}
+
+// Stop останавливает выдачу токенов; повторный вызов и вызов на nil-лимитере безопасны
+func (rl *RateLimiter) Stop() {
+	if rl == nil || rl.stop == nil {
+		return
+	}
+	rl.stopOnce.Do(func() { close(rl.stop) })
 }
 
 // TakeToken запрашивает токен из лимитера, блокируя выполнение до его получения
 func (rl *RateLimiter) TakeToken() {
+	start := time.Now()
 	<-rl.TokenCh
+	metrics.RateLimiterWait.Observe(time.Since(start).Seconds())
 }
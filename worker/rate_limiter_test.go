@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRateLimiterSetRPS проверяет, что смена лимита не блокируется и меняет интервал и пачку
+func TestRateLimiterSetRPS(t *testing.T) {
+	rl := NewRateLimiter(100)
+	defer rl.Stop()
+	assert.Eventually(t, func() bool { return len(rl.TokenCh) >= 5 }, time.Second, 5*time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		// Повторные вызовы подряд не ждут горутину выдачи токенов
+		rl.SetRPS(1)
+		rl.SetRPS(2)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SetRPS blocked")
+	}
+
+	assert.Equal(t, 500*time.Millisecond, rl.Interval())
+	assert.LessOrEqual(t, len(rl.TokenCh), 2)
+}
+
+// TestRateLimiterStop проверяет, что остановленный лимитер больше не выдает токены
+func TestRateLimiterStop(t *testing.T) {
+	rl := NewRateLimiter(100)
+	rl.Stop()
+	rl.Stop() // Повторная остановка безопасна
+	time.Sleep(10 * time.Millisecond)
+	for len(rl.TokenCh) > 0 {
+		<-rl.TokenCh
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, rl.TokenCh)
+
+	// Смена лимита остановленного лимитера не блокируется
+	rl.SetRPS(10)
+	var nilLimiter *RateLimiter
+	assert.NotPanics(t, nilLimiter.Stop)
+}
@@ -1,11 +1,22 @@
 package worker
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gocolly/colly"
 	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/anomaly"
+	"github.com/seniorcat/scraper/pkg/dedup"
+	"github.com/seniorcat/scraper/pkg/diet"
+	"github.com/seniorcat/scraper/pkg/imaging"
+	"github.com/seniorcat/scraper/pkg/ingredient"
+	"github.com/seniorcat/scraper/pkg/metrics"
+	"github.com/seniorcat/scraper/pkg/nutrition"
 	"go.uber.org/zap"
 )
 
@@ -16,12 +27,24 @@ type RecipeParser struct {
 	Limiter    *RateLimiter
 	maxRecipes int
 	timeout    time.Duration
+	Yield      *anomaly.Collector    // Показатели извлечения для поиска аномалий (может быть nil)
+	Catalog    *ingredient.Catalog   // Каталог для привязки ингредиентов к каноническим (может быть nil)
+	Nutrition  *nutrition.Calculator // Расчет пищевой ценности рецептов (может быть nil)
+	Diet       *diet.Classifier      // Метки диет и аллергенов рецептов (может быть nil)
+	Imaging    *imaging.Processor    // Сохранение загруженных изображений в хранилище (может быть nil)
+	ImageLimit *RateLimiter          // Общий для воркеров лимитер загрузки изображений; nil — используется Limiter
 }
 
 // NewRecipeParser создает новый экземпляр RecipeParser
 func NewRecipeParser(logger *zap.Logger, maxRecipes int, rps int, timeout time.Duration) *RecipeParser {
+	// Повторное посещение нужно, чтобы задача, перезапущенная сторожем, снова загрузила страницу
+	collector := colly.NewCollector(colly.AllowURLRevisit())
+	if timeout > 0 {
+		collector.SetRequestTimeout(timeout)
+	}
+
 	return &RecipeParser{
-		Collector:  colly.NewCollector(),
+		Collector:  collector,
 		Logger:     logger,
 		Limiter:    NewRateLimiter(rps),
 		maxRecipes: maxRecipes,
@@ -31,45 +54,142 @@ func NewRecipeParser(logger *zap.Logger, maxRecipes int, rps int, timeout time.D
 
 // ParseRecipes парсит рецепты для заданной категории
 func (p *RecipeParser) ParseRecipes(category entity.Category) ([]entity.Recipe, error) {
+	recipes, _, err := p.ParseListing(category)
+	return recipes, err
+}
+
+// ParseListing парсит рецепты для заданной категории и возвращает канонические URL
+// всех карточек списка, в том числе не вошедших в лимит рецептов. Обходятся все
+// страницы списка: рецепт, сдвинутый на следующую страницу, не пропал с сайта.
+func (p *RecipeParser) ParseListing(category entity.Category) ([]entity.Recipe, []string, error) {
 	var recipes []entity.Recipe
+	var listed []string
+	var pageErr error
+	visited := make(map[string]bool)
 
 	p.Limiter.TakeToken() // Ограничение скорости запросов
 
-	p.Collector.OnHTML(".emotion-13pp0tv", func(e *colly.HTMLElement) {
-		if len(recipes) >= p.maxRecipes {
-			return // Прерывание парсинга, если достигнут лимит рецептов
-		}
-		recipe := entity.Recipe{
-			Name: e.ChildAttr("img", "alt"),
-			Href: e.Attr("href"),
-		}
+	// Отдельный коллектор на каждый вызов: обработчики не накапливаются между категориями,
+	// а брошенный по таймауту вызов не мешает следующему
+	collector := p.Collector.Clone()
+	instrumentCollector(collector, stageRecipe)
+
+	collector.OnHTML("html", func(e *colly.HTMLElement) {
+		visited[e.Request.URL.String()] = true
 
-		// Нормализация данных рецепта
-		recipe.Normalize()
+		// Карточки рецептов извлекаются первой сработавшей стратегией
+		cards, _ := recipeCardChain.Run(e.DOM, e.Request.URL.String(), p.Logger)
+		for _, recipe := range cards {
+			// Карточка встречена на сайте, даже если рецепт не попадет в лимит
+			if canonicalURL, err := dedup.CanonicalURL(baseURL, recipe.Href); err == nil && recipe.Href != "" {
+				listed = append(listed, canonicalURL)
+			}
+			if len(recipes) >= p.maxRecipes {
+				continue // Рецепты сверх лимита не разбираются
+			}
+			if recipe, ok := p.processRecipe(e, recipe); ok {
+				recipes = append(recipes, recipe)
+			}
+		}
 
-		// Валидация рецепта
-		if err := recipe.Validate(); err != nil {
-			p.Logger.Error("Invalid recipe data", zap.Error(err))
+		// Следующая страница списка; ошибка на ней делает список неполным
+		next := nextPage(e)
+		if next == "" || visited[next] || pageErr != nil {
 			return
 		}
-		p.Logger.Info("Recipe found", zap.String("Name", recipe.Name))
-		recipes = append(recipes, recipe)
+		p.Limiter.TakeToken()
+		if err := collector.Visit(next); err != nil {
+			pageErr = fmt.Errorf("listing page %s: %w", next, err)
+		}
+	})
+
+	statusCode := 0
+	collector.OnError(func(r *colly.Response, err error) {
+		statusCode = r.StatusCode
 	})
 
 	// URL для парсинга
-	err := p.Collector.Visit("https://eda.ru" + category.Href)
+	err := collector.Visit(baseURL + category.Href)
+	if err != nil {
+		if entity.IsGoneStatus(statusCode) {
+			return nil, nil, &GoneError{URL: baseURL + category.Href, StatusCode: statusCode}
+		}
+		return nil, nil, err
+	}
+	if pageErr != nil {
+		return nil, nil, pageErr
+	}
+
+	return recipes, listed, nil
+}
+
+// nextPage возвращает абсолютный адрес следующей страницы списка или пустую строку
+func nextPage(e *colly.HTMLElement) string {
+	href, ok := e.DOM.Find(`link[rel="next"], a[rel="next"]`).First().Attr("href")
+	if !ok || strings.TrimSpace(href) == "" {
+		return ""
+	}
+	return e.Request.AbsoluteURL(strings.TrimSpace(href))
+}
+
+// processRecipe нормализует и проверяет карточку рецепта
+func (p *RecipeParser) processRecipe(e *colly.HTMLElement, recipe entity.Recipe) (entity.Recipe, bool) {
+	if recipe.ImageURL != "" {
+		recipe.ImageURL = e.Request.AbsoluteURL(recipe.ImageURL)
+	}
+
+	// Нормализация данных рецепта
+	recipe.Normalize()
+
+	// Валидация рецепта
+	if err := recipe.Validate(); err != nil {
+		metrics.ValidationErrors.WithLabelValues(e.Request.URL.Host, stageRecipe).Inc()
+		p.Yield.ValidationError(stageRecipe)
+		p.Logger.Error("Invalid recipe data", zap.Error(err))
+		return recipe, false
+	}
+
+	canonicalURL, err := dedup.CanonicalURL(baseURL, recipe.Href)
 	if err != nil {
-		return nil, err
+		p.Logger.Error("Invalid recipe href", zap.String("href", recipe.Href), zap.Error(err))
+		return recipe, false
 	}
+	recipe.CanonicalURL = canonicalURL
+	recipe.Fingerprint = dedup.Fingerprint(recipe)
 
-	return recipes, nil
+	metrics.ItemsExtracted.WithLabelValues(e.Request.URL.Host, stageRecipe).Inc()
+	p.Yield.Extracted(stageRecipe)
+	if recipe.ImageURL == "" {
+		p.Yield.EmptyField(stageRecipe, "image")
+	}
+	p.Logger.Info("Recipe found", zap.String("Name", recipe.Name))
+	return recipe, true
 }
 
 // RecipeWorker управляет парсингом рецептов с синхронизацией
 type RecipeWorker struct {
+	ID             int // Номер воркера в пуле контроллера
 	Parser         *RecipeParser
 	ProcessedCount int
-	Mutex          *sync.Mutex // Добавляем мьютекс для синхронизации
+	Mutex          *sync.Mutex    // Добавляем мьютекс для синхронизации
+	Tracker        *StatusTracker // Учет состояний задач и воркера (может быть nil)
+	Gate           *Gate          // Приостановка выдачи задач (может быть nil)
+	Registry       *Registry      // Обработчики типов задач; nil — встроенные обработчики
+	Retry          func(Task)     // Повторная постановка задачи, завершившейся ошибкой (может быть nil)
+
+	current *Task              // Задача в работе; защищается Mutex
+	cancel  context.CancelFunc // Прерывает текущую задачу; защищается Mutex
+
+	quit     chan struct{} // Закрывается, чтобы воркер завершился после текущей задачи
+	quitOnce sync.Once
+	running  atomic.Bool // Горутина ProcessTasks работает
+	detached atomic.Bool // Воркер уже исключен из группы ожидания контроллера
+}
+
+// handleResult — результат вызова обработчика в отдельной горутине
+type handleResult struct {
+	result Result
+	err    error
 }
 
 // NewRecipeWorker создает новый экземпляр RecipeWorker
@@ -78,28 +198,142 @@ func NewRecipeWorker(logger *zap.Logger, maxRecipes int, rps int, timeout time.D
 	return &RecipeWorker{
 		Parser: parser,
 		Mutex:  &sync.Mutex{},
+		quit:   make(chan struct{}),
 	}
 }
 
 // ProcessTasks запускает воркер для обработки задач и защищает доступ к счетчику
 func (w *RecipeWorker) ProcessTasks(taskQueue chan Task, resultQueue chan Result) {
-	for task := range taskQueue {
-		if task.Type == "recipe" && task.Category != nil {
-			recipes, err := w.Parser.ParseRecipes(*task.Category)
-			if err != nil {
-				w.Parser.Logger.Error("Failed to parse recipes", zap.String("category", task.Category.Name), zap.Error(err))
-				continue
-			}
+	w.running.Store(true)
+	defer w.running.Store(false)
 
-			// Безопасное обновление счетчика обработанных рецептов
-			w.Mutex.Lock()
-			w.ProcessedCount += len(recipes)
-			w.Mutex.Unlock()
+	for {
+		// Ожидание, пока выдача задач не возобновится
+		select {
+		case <-w.quit:
+			return
+		case <-w.Gate.Wait():
+		}
 
-			resultQueue <- Result{
-				TaskID:  task.ID,
-				Recipes: recipes,
+		select {
+		case <-w.quit:
+			return
+		case task, ok := <-taskQueue:
+			if !ok {
+				return
 			}
+			w.processTask(task, resultQueue)
 		}
 	}
 }
+
+// Quit просит воркер завершиться после текущей задачи
+func (w *RecipeWorker) Quit() {
+	w.quitOnce.Do(func() { close(w.quit) })
+}
+
+// Running сообщает, работает ли горутина воркера
+func (w *RecipeWorker) Running() bool {
+	return w.running.Load()
+}
+
+// CurrentTask возвращает задачу, которую воркер обрабатывает в данный момент
+func (w *RecipeWorker) CurrentTask() (Task, bool) {
+	w.Mutex.Lock()
+	defer w.Mutex.Unlock()
+
+	if w.current == nil {
+		return Task{}, false
+	}
+	return *w.current, true
+}
+
+// CancelTask прерывает текущую задачу; воркер отбрасывает ее результат и берет следующую
+func (w *RecipeWorker) CancelTask() {
+	w.Mutex.Lock()
+	defer w.Mutex.Unlock()
+
+	if w.cancel != nil {
+		w.cancel()
+	}
+}
+
+// processTask обрабатывает одну задачу обработчиком ее типа
+func (w *RecipeWorker) processTask(task Task, resultQueue chan Result) {
+	handler, ok := w.registry().Lookup(task.Type)
+	if !ok {
+		reportUnknownTask(w.Parser.Logger, w.Tracker, task)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w.setCurrent(&task, cancel)
+	defer w.setCurrent(nil, nil)
+
+	w.Tracker.TaskStarted(task, w.ID)
+	metrics.WorkersBusy.Inc()
+	result, err := w.handle(ctx, handler, task)
+	metrics.WorkersBusy.Dec()
+	if ctx.Err() != nil {
+		// Задача снята сторожем: состояние уже обновлено, задача поставлена заново
+		w.Parser.Logger.Warn("Task cancelled", zap.String("task_id", task.ID))
+		return
+	}
+	if err != nil && w.Retry != nil {
+		// Повтор планируется до смены состояния, чтобы контроллер не счел обход завершенным
+		w.Retry(task)
+	}
+	w.Tracker.TaskFinished(task.ID, w.ID, len(result.Recipes), err)
+	if err != nil {
+		w.Parser.Logger.Error("Task failed", zap.String("task_id", task.ID), zap.String("type", task.Type), zap.Error(err))
+		return
+	}
+
+	// Безопасное обновление счетчика обработанных рецептов
+	w.Mutex.Lock()
+	w.ProcessedCount += len(result.Recipes)
+	w.Mutex.Unlock()
+
+	result.TaskID = task.ID
+	result.Type = task.Type
+	resultQueue <- result
+}
+
+// handle запускает обработчик в отдельной горутине, чтобы отмена задачи не ждала ответа сайта
+func (w *RecipeWorker) handle(ctx context.Context, handler Handler, task Task) (Result, error) {
+	done := make(chan handleResult, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- handleResult{err: fmt.Errorf("handler panic: %v", r)}
+			}
+		}()
+		result, err := handler.Handle(ctx, w, task)
+		done <- handleResult{result: result, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.result, res.err
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	}
+}
+
+// registry возвращает реестр обработчиков воркера
+func (w *RecipeWorker) registry() *Registry {
+	if w.Registry == nil {
+		return defaultRegistry
+	}
+	return w.Registry
+}
+
+// setCurrent запоминает текущую задачу и функцию ее отмены
+func (w *RecipeWorker) setCurrent(task *Task, cancel context.CancelFunc) {
+	w.Mutex.Lock()
+	defer w.Mutex.Unlock()
+	w.current = task
+	w.cancel = cancel
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Типы задач обхода
+const (
+	TaskCategoryCrawl = "category_crawl" // Обход главной страницы со списком категорий
+	TaskListing       = "recipe"         // Страница категории со списком рецептов; имя сохранено для совместимости
+	TaskRecipeDetail  = "recipe_detail"  // Страница отдельного рецепта
+	TaskImageDownload = "image_download" // Загрузка изображения
+	TaskLinkCheck     = "link_check"     // Проверка доступности ссылки
+)
+
+// ErrUnknownTaskType возвращается для задачи, тип которой не зарегистрирован
+var ErrUnknownTaskType = errors.New("unknown task type")
+
+// Handler выполняет задачу одного типа. ctx отменяется, если задачу снял сторож.
+type Handler interface {
+	Handle(ctx context.Context, w *RecipeWorker, task Task) (Result, error)
+}
+
+// HandlerFunc позволяет использовать обычную функцию как Handler
+type HandlerFunc func(ctx context.Context, w *RecipeWorker, task Task) (Result, error)
+
+// Handle вызывает f(ctx, w, task)
+func (f HandlerFunc) Handle(ctx context.Context, w *RecipeWorker, task Task) (Result, error) {
+	return f(ctx, w, task)
+}
+
+// Typed создает обработчик задач с нагрузкой типа P; задача с нагрузкой другого типа завершается ошибкой
+func Typed[P any](fn func(ctx context.Context, w *RecipeWorker, task Task, payload P) (Result, error)) Handler {
+	return HandlerFunc(func(ctx context.Context, w *RecipeWorker, task Task) (Result, error) {
+		payload, ok := task.Payload.(P)
+		if !ok {
+			var want P
+			return Result{}, fmt.Errorf("task %s of type %q: payload is %T, want %T", task.ID, task.Type, task.Payload, want)
+		}
+		return fn(ctx, w, task, payload)
+	})
+}
+
+// Registry сопоставляет типы задач с их обработчиками
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewRegistry создает пустой реестр обработчиков
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler)}
+}
+
+// NewDefaultRegistry создает реестр со встроенными обработчиками, не требующими настройки
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(TaskListing, HandlerFunc(handleListing))
+	r.Register(TaskRecipeDetail, Typed(handleRecipeDetail))
+	r.Register(TaskImageDownload, Typed(handleImageDownload))
+	r.Register(TaskLinkCheck, Typed(handleLinkCheck))
+	return r
+}
+
+// Register регистрирует обработчик для типа задач. Повторная регистрация
+// типа — ошибка программиста, поэтому, как и http.ServeMux, вызывает панику.
+func (r *Registry) Register(taskType string, handler Handler) {
+	if taskType == "" || handler == nil {
+		panic("worker: empty task type or nil handler")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.handlers[taskType]; exists {
+		panic(fmt.Sprintf("worker: handler for task type %q already registered", taskType))
+	}
+	r.handlers[taskType] = handler
+}
+
+// Lookup возвращает обработчик для типа задач
+func (r *Registry) Lookup(taskType string) (Handler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	handler, ok := r.handlers[taskType]
+	return handler, ok
+}
+
+// Types возвращает зарегистрированные типы задач в алфавитном порядке
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	types := make([]string, 0, len(r.handlers))
+	for taskType := range r.handlers {
+		types = append(types, taskType)
+	}
+	r.mu.RUnlock()
+
+	sort.Strings(types)
+	return types
+}
+
+// defaultRegistry используется воркерами, созданными без контроллера
+var defaultRegistry = NewDefaultRegistry()
@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestRegistry проверяет регистрацию и поиск обработчиков
+func TestRegistry(t *testing.T) {
+	r := NewDefaultRegistry()
+	assert.Equal(t, []string{TaskImageDownload, TaskLinkCheck, TaskListing, TaskRecipeDetail}, r.Types())
+
+	_, ok := r.Lookup("video")
+	assert.False(t, ok)
+
+	// Повторная регистрация типа — ошибка программиста
+	assert.Panics(t, func() { r.Register(TaskListing, HandlerFunc(handleListing)) })
+}
+
+// TestTypedPayload проверяет отказ обработчика при нагрузке неверного типа
+func TestTypedPayload(t *testing.T) {
+	handler := Typed(func(ctx context.Context, w *RecipeWorker, task Task, payload LinkCheckPayload) (Result, error) {
+		return Result{Data: payload.URL}, nil
+	})
+
+	result, err := handler.Handle(context.Background(), nil, Task{Payload: LinkCheckPayload{URL: "https://eda.ru"}})
+	require.NoError(t, err)
+	assert.Equal(t, "https://eda.ru", result.Data)
+
+	_, err = handler.Handle(context.Background(), nil, Task{ID: "1", Type: TaskLinkCheck, Payload: "https://eda.ru"})
+	assert.Error(t, err)
+}
+
+// TestUnknownTaskType проверяет, что задачи неизвестного типа отмечаются ошибкой, а не теряются
+func TestUnknownTaskType(t *testing.T) {
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Second, 1, nil)
+
+	assert.False(t, tc.AddTask(Task{ID: "1", Type: "video"}))
+	state, ok := tc.TaskState("1")
+	require.True(t, ok)
+	assert.Equal(t, StatusError, state.Status)
+	assert.Contains(t, state.LastError, ErrUnknownTaskType.Error())
+
+	// Задача, попавшая в очередь в обход контроллера, тоже учитывается
+	w := NewRecipeWorker(zap.NewNop(), 1, 1, time.Second)
+	w.Tracker = tc.Tracker
+	w.processTask(Task{ID: "2", Type: "video"}, tc.ResultQueue)
+	state, ok = tc.TaskState("2")
+	require.True(t, ok)
+	assert.Equal(t, StatusError, state.Status)
+	assert.Empty(t, tc.ResultQueue)
+}
+
+// TestCustomHandler проверяет выполнение задачи зарегистрированным обработчиком
+func TestCustomHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("echo", HandlerFunc(func(ctx context.Context, w *RecipeWorker, task Task) (Result, error) {
+		return Result{Data: task.Payload}, nil
+	}))
+	registry.Register("fail", HandlerFunc(func(ctx context.Context, w *RecipeWorker, task Task) (Result, error) {
+		return Result{}, errors.New("boom")
+	}))
+
+	w := NewRecipeWorker(zap.NewNop(), 1, 1, time.Second)
+	w.Registry = registry
+	w.Tracker = NewStatusTracker()
+	results := make(chan Result, 1)
+
+	w.processTask(Task{ID: "1", Type: "echo", Payload: 42}, results)
+	result := <-results
+	assert.Equal(t, "1", result.TaskID)
+	assert.Equal(t, "echo", result.Type)
+	assert.Equal(t, 42, result.Data)
+
+	w.processTask(Task{ID: "2", Type: "fail"}, results)
+	assert.Empty(t, results)
+	state, _ := w.Tracker.Task("2")
+	assert.Equal(t, StatusError, state.Status)
+}
+
+// TestBuiltinHandlers проверяет обработчики страницы рецепта и проверки ссылок на локальном сервере
+func TestBuiltinHandlers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/recepty/supy/borsch-1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><link rel="canonical" href="/recepty/supy/borsch-1?utm_source=x"></head>
+<body><h1> Борщ </h1><span itemprop="recipeIngredient">Свекла 2 шт</span><span itemprop="recipeIngredient">Капуста</span></body></html>`))
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	w := NewRecipeWorker(zap.NewNop(), 1, 100, time.Second)
+	w.Tracker = NewStatusTracker()
+	results := make(chan Result, 1)
+
+	w.processTask(Task{ID: "detail", Type: TaskRecipeDetail, Payload: RecipeDetailPayload{URL: server.URL + "/recepty/supy/borsch-1"}}, results)
+	result := <-results
+	require.Len(t, result.Recipes, 1)
+	recipe := result.Recipes[0]
+	assert.Equal(t, "борщ", recipe.Name)
+	assert.Equal(t, "/recepty/supy/borsch-1", recipe.Href)
+	assert.Equal(t, server.URL+"/recepty/supy/borsch-1", recipe.CanonicalURL)
+	assert.Equal(t, []string{"Свекла 2 шт", "Капуста"}, recipe.Ingredients)
+	require.Len(t, recipe.Parsed, 2)
+	assert.Equal(t, "шт", recipe.Parsed[0].Unit)
+
+	w.processTask(Task{ID: "link", Type: TaskLinkCheck, Payload: LinkCheckPayload{URL: server.URL + "/missing"}}, results)
+	result = <-results
+	status, ok := result.Data.(LinkStatus)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, status.StatusCode)
+	assert.False(t, status.Alive)
+}
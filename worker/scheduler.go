@@ -0,0 +1,154 @@
+package worker
+
+import (
+	"container/heap"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+)
+
+// maxStaleness ограничивает давность обхода при расчете приоритета; категории,
+// которые еще ни разу не обходились, получают ее целиком
+const maxStaleness = 30 * 24 * time.Hour
+
+// PriorityFunc вычисляет приоритет задачи; задачи с большим приоритетом выдаются раньше
+type PriorityFunc func(task Task) float64
+
+// Scheduler — очередь задач с приоритетами; задачи с равным приоритетом выдаются в порядке поступления
+type Scheduler struct {
+	mu       sync.Mutex
+	items    taskHeap
+	seq      uint64
+	notify   chan struct{} // Сигнал о появлении задачи
+	priority PriorityFunc
+}
+
+// NewScheduler создает планировщик с заданной функцией приоритета
+func NewScheduler(priority PriorityFunc) *Scheduler {
+	return &Scheduler{
+		notify:   make(chan struct{}, 1),
+		priority: priority,
+	}
+}
+
+// Push добавляет задачу в очередь
+func (s *Scheduler) Push(task Task) {
+	priority := s.priority(task)
+
+	s.mu.Lock()
+	s.seq++
+	heap.Push(&s.items, &scheduledTask{task: task, priority: priority, seq: s.seq})
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Pop возвращает задачу с наибольшим приоритетом, ожидая ее появления.
+// Возвращает false, если done закрыт раньше.
+func (s *Scheduler) Pop(done <-chan struct{}) (Task, bool) {
+	for {
+		s.mu.Lock()
+		if s.items.Len() > 0 {
+			item := heap.Pop(&s.items).(*scheduledTask)
+			s.mu.Unlock()
+			return item.task, true
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.notify:
+		case <-done:
+			return Task{}, false
+		}
+	}
+}
+
+// Len возвращает количество задач в очереди; для nil-планировщика возвращает 0
+func (s *Scheduler) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.items.Len()
+}
+
+// FreshnessPolicy рассчитывает приоритет категории по давности последнего успешного
+// обхода, исторической частоте изменений и ручному повышению из конфигурации
+type FreshnessPolicy struct {
+	Stats        map[string]entity.CategoryStats // Статистика обходов по каноническому URL категории
+	Boosts       map[string]float64              // Повышение приоритета по названию или URL категории, в часах давности
+	ChangeWeight float64                         // Вес частоты изменений
+	Now          func() time.Time
+}
+
+// Priority возвращает приоритет задачи: давность обхода в часах, увеличенная
+// пропорционально частоте изменений, плюс ручное повышение
+func (p *FreshnessPolicy) Priority(task Task) float64 {
+	if task.Category == nil {
+		return 0
+	}
+
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+
+	staleness := maxStaleness
+	changeRate := 0.0
+	if stats, ok := p.Stats[task.Category.CanonicalURL]; ok && !stats.LastCrawledAt.IsZero() {
+		staleness = now().Sub(stats.LastCrawledAt)
+		if staleness > maxStaleness {
+			staleness = maxStaleness
+		}
+		changeRate = stats.ChangeRate()
+	}
+
+	priority := staleness.Hours() * (1 + p.ChangeWeight*changeRate)
+	priority += p.boost(*task.Category)
+	return priority
+}
+
+// boost возвращает ручное повышение приоритета категории
+func (p *FreshnessPolicy) boost(category entity.Category) float64 {
+	if boost, ok := p.Boosts[strings.ToLower(category.CanonicalURL)]; ok {
+		return boost
+	}
+	return p.Boosts[strings.ToLower(strings.TrimSpace(category.Name))]
+}
+
+// scheduledTask — элемент очереди с приоритетами
+type scheduledTask struct {
+	task     Task
+	priority float64
+	seq      uint64
+}
+
+// taskHeap реализует heap.Interface с максимумом приоритета в вершине
+type taskHeap []*scheduledTask
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *taskHeap) Push(x any) { *h = append(*h, x.(*scheduledTask)) }
+
+func (h *taskHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
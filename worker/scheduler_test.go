@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// categoryTask создает задачу на обход категории
+func categoryTask(name, url string) Task {
+	return Task{ID: name, Type: "recipe", Category: &entity.Category{Name: name, Href: url, CanonicalURL: url}}
+}
+
+// TestSchedulerOrder проверяет выдачу по приоритету и FIFO при равных приоритетах
+func TestSchedulerOrder(t *testing.T) {
+	priorities := map[string]float64{"a": 1, "b": 5, "c": 1, "d": 3}
+	s := NewScheduler(func(task Task) float64 { return priorities[task.ID] })
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		s.Push(Task{ID: id})
+	}
+	assert.Equal(t, 4, s.Len())
+
+	done := make(chan struct{})
+	var order []string
+	for s.Len() > 0 {
+		task, ok := s.Pop(done)
+		require.True(t, ok)
+		order = append(order, task.ID)
+	}
+	assert.Equal(t, []string{"b", "d", "a", "c"}, order)
+
+	// Пустой планировщик ждет задачу до закрытия done
+	close(done)
+	_, ok := s.Pop(done)
+	assert.False(t, ok)
+}
+
+// TestFreshnessPolicy проверяет влияние давности, частоты изменений и ручного повышения
+func TestFreshnessPolicy(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	policy := &FreshnessPolicy{
+		Stats: map[string]entity.CategoryStats{
+			"https://eda.ru/recepty/supy":     {LastCrawledAt: now.Add(-10 * time.Hour), Crawls: 5, Changes: 0},
+			"https://eda.ru/recepty/salaty":   {LastCrawledAt: now.Add(-10 * time.Hour), Crawls: 5, Changes: 4},
+			"https://eda.ru/recepty/novinki":  {LastCrawledAt: now.Add(-time.Hour), Crawls: 5, Changes: 4},
+			"https://eda.ru/recepty/zavtraki": {LastCrawledAt: now.Add(-90 * 24 * time.Hour), Crawls: 1},
+		},
+		Boosts:       map[string]float64{"новинки": 1000},
+		ChangeWeight: 1,
+		Now:          func() time.Time { return now },
+	}
+
+	stable := policy.Priority(categoryTask("супы", "https://eda.ru/recepty/supy"))
+	changing := policy.Priority(categoryTask("салаты", "https://eda.ru/recepty/salaty"))
+	boosted := policy.Priority(categoryTask("Новинки", "https://eda.ru/recepty/novinki"))
+	stale := policy.Priority(categoryTask("завтраки", "https://eda.ru/recepty/zavtraki"))
+	unknown := policy.Priority(categoryTask("десерты", "https://eda.ru/recepty/deserty"))
+
+	assert.InDelta(t, 10, stable, 0.001)
+	assert.InDelta(t, 20, changing, 0.001)
+	assert.Greater(t, boosted, stale)
+
+	// Давность ограничена, а еще не обходившиеся категории считаются самыми устаревшими
+	assert.InDelta(t, maxStaleness.Hours(), stale, 0.001)
+	assert.InDelta(t, maxStaleness.Hours(), unknown, 0.001)
+}
+
+// TestTaskControllerPriorityScheduler проверяет, что воркеры получают задачи по приоритету
+func TestTaskControllerPriorityScheduler(t *testing.T) {
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Second, 1, nil)
+	tc.UsePriorityScheduler(func(task Task) float64 {
+		if task.ID == "новинки" {
+			return 100
+		}
+		return 1
+	})
+
+	tc.AddTask(categoryTask("супы", "https://eda.ru/recepty/supy"))
+	tc.AddTask(categoryTask("новинки", "https://eda.ru/recepty/novinki"))
+	assert.Equal(t, 2, tc.Status().ScheduledTasks)
+
+	go tc.dispatch()
+
+	first := <-tc.TaskQueue
+	second := <-tc.TaskQueue
+	assert.Equal(t, "новинки", first.ID)
+	assert.Equal(t, "супы", second.ID)
+
+	tc.Stop()
+}
+
+// TestContentHash проверяет, что хеш состава категории не зависит от порядка рецептов
+func TestContentHash(t *testing.T) {
+	a := []entity.Recipe{{CanonicalURL: "https://eda.ru/r/1"}, {CanonicalURL: "https://eda.ru/r/2"}}
+	b := []entity.Recipe{{CanonicalURL: "https://eda.ru/r/2"}, {CanonicalURL: "https://eda.ru/r/1"}}
+	c := []entity.Recipe{{CanonicalURL: "https://eda.ru/r/1"}, {CanonicalURL: "https://eda.ru/r/3"}}
+
+	assert.Equal(t, contentHash(a), contentHash(b))
+	assert.NotEqual(t, contentHash(a), contentHash(c))
+}
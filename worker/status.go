@@ -0,0 +1,457 @@
+package worker
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TaskState описывает жизненный цикл задачи
+type TaskState struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Category   string    `json:"category,omitempty"`
+	Status     Status    `json:"status"`
+	WorkerID   int       `json:"worker_id,omitempty"`
+	RetryCount int       `json:"retry_count"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// WorkerState описывает состояние воркера рецептов
+type WorkerState struct {
+	ID          int       `json:"id"`
+	Status      Status    `json:"status"`
+	CurrentTask string    `json:"current_task,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	StartedAt   time.Time `json:"started_at,omitempty"` // Время начала текущей задачи
+	LastError   string    `json:"last_error,omitempty"`
+	Processed   int       `json:"processed"` // Количество обработанных рецептов
+}
+
+// StatusEvent — событие смены состояния задачи или воркера
+type StatusEvent struct {
+	TaskID   string    `json:"task_id,omitempty"`
+	WorkerID int       `json:"worker_id,omitempty"`
+	From     Status    `json:"from,omitempty"`
+	To       Status    `json:"to"`
+	Time     time.Time `json:"time"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// ControllerStatus — снимок состояния контроллера задач
+type ControllerStatus struct {
+	TaskQueueDepth   int            `json:"task_queue_depth"`
+	ScheduledTasks   int            `json:"scheduled_tasks"`
+	ResultQueueDepth int            `json:"result_queue_depth"`
+	WorkersCount     int            `json:"workers_count"`
+	AliveWorkers     int            `json:"alive_workers"`
+	Paused           bool           `json:"paused"`
+	RPS              int            `json:"rps"`
+	Tasks            map[Status]int `json:"tasks"`
+	Workers          []WorkerState  `json:"workers"`
+}
+
+// taskHistory — сколько последних завершенных задач трекер хранит целиком
+const taskHistory = 1000
+
+// StatusTracker хранит состояния задач и воркеров и рассылает события об их смене.
+// Из завершенных задач хранятся только последние, остальные учитываются в счетчиках.
+// Методы безопасны для вызова на nil-трекере, чтобы воркеры работали и без контроллера.
+type StatusTracker struct {
+	mu          sync.RWMutex
+	tasks       map[string]*TaskState
+	counts      map[Status]int // Количество задач в каждом состоянии, включая вытесненные
+	finished    []*TaskState   // Завершенные задачи в порядке завершения
+	history     int            // Сколько завершенных задач хранить
+	workers     map[int]*WorkerState
+	subscribers []chan StatusEvent
+}
+
+// NewStatusTracker создает пустой трекер состояний
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{
+		tasks:   make(map[string]*TaskState),
+		counts:  make(map[Status]int),
+		history: taskHistory,
+		workers: make(map[int]*WorkerState),
+	}
+}
+
+// Subscribe возвращает канал событий смены состояний. События не блокируют
+// воркеры: если подписчик не успевает их читать, лишние события отбрасываются.
+// У nil-трекера событий нет, и канал остается пустым.
+func (t *StatusTracker) Subscribe(buffer int) <-chan StatusEvent {
+	ch := make(chan StatusEvent, buffer)
+	if t == nil {
+		return ch
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.subscribers = append(t.subscribers, ch)
+	return ch
+}
+
+// TaskPending регистрирует задачу, поставленную в очередь
+func (t *StatusTracker) TaskPending(task Task) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.tasks[task.ID]
+	if !ok {
+		state = &TaskState{ID: task.ID}
+		t.tasks[task.ID] = state
+	}
+	state.Type = task.Type
+	if task.Category != nil {
+		state.Category = task.Category.Name
+	}
+	state.RetryCount = task.RetryCount
+	state.EnqueuedAt = time.Now()
+	state.WorkerID = 0
+	state.StartedAt = time.Time{}
+	state.FinishedAt = time.Time{}
+
+	t.setTaskStatus(state, StatusPending, "")
+}
+
+// TaskRejected отмечает задачу, которую нельзя выполнить, например из-за неизвестного типа
+func (t *StatusTracker) TaskRejected(task Task, err error) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	state, ok := t.tasks[task.ID]
+	if !ok {
+		state = &TaskState{ID: task.ID, EnqueuedAt: now}
+		t.tasks[task.ID] = state
+	}
+	state.Type = task.Type
+	if task.Category != nil {
+		state.Category = task.Category.Name
+	}
+	state.RetryCount = task.RetryCount
+	state.FinishedAt = now
+	t.setTaskStatus(state, StatusError, err.Error())
+}
+
+// TaskStarted отмечает, что воркер взял задачу в работу
+func (t *StatusTracker) TaskStarted(task Task, workerID int) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	state, ok := t.tasks[task.ID]
+	if !ok {
+		// Задача могла попасть в очередь в обход контроллера
+		state = &TaskState{ID: task.ID, Type: task.Type, EnqueuedAt: now}
+		if task.Category != nil {
+			state.Category = task.Category.Name
+		}
+		t.tasks[task.ID] = state
+	}
+	state.WorkerID = workerID
+	state.StartedAt = now
+	t.setTaskStatus(state, StatusInProgress, "")
+
+	w := t.worker(workerID)
+	w.CurrentTask = task.ID
+	w.Category = state.Category
+	w.StartedAt = now
+	t.setWorkerStatus(w, StatusBusy, "")
+}
+
+// TaskFinished отмечает завершение задачи воркером; err != nil означает ошибку
+func (t *StatusTracker) TaskFinished(taskID string, workerID int, processed int, err error) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Задачу, снятую сторожем и поставленную заново, не перезаписываем
+	if state, ok := t.tasks[taskID]; ok && state.Status == StatusInProgress && state.WorkerID == workerID {
+		state.FinishedAt = time.Now()
+		if err != nil {
+			t.setTaskStatus(state, StatusError, err.Error())
+		} else {
+			t.setTaskStatus(state, StatusCompleted, "")
+		}
+	}
+
+	// Воркер мог быть удален из пула, пока дорабатывал задачу
+	w, ok := t.workers[workerID]
+	if !ok {
+		return
+	}
+	w.CurrentTask = ""
+	w.Category = ""
+	w.StartedAt = time.Time{}
+	w.Processed += processed
+	if err != nil {
+		// Воркер остается в состоянии ошибки до следующей задачи
+		t.setWorkerStatus(w, StatusError, err.Error())
+		return
+	}
+	t.setWorkerStatus(w, StatusIdle, "")
+}
+
+// TaskTimedOut отмечает задачу, снятую с воркера по превышению времени выполнения
+func (t *StatusTracker) TaskTimedOut(taskID string, workerID int, deadline time.Duration) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	errText := fmt.Sprintf("deadline of %s exceeded", deadline)
+	if state, ok := t.tasks[taskID]; ok && state.Status == StatusInProgress {
+		state.FinishedAt = time.Now()
+		t.setTaskStatus(state, StatusError, errText)
+	}
+	t.setWorkerStatus(t.worker(workerID), StatusError, errText)
+}
+
+// WorkerFailed отмечает воркер, завершившийся аварийно или признанный зависшим
+func (t *StatusTracker) WorkerFailed(workerID int, err error) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setWorkerStatus(t.worker(workerID), StatusError, err.Error())
+}
+
+// RemoveWorker удаляет состояние воркера, остановленного при уменьшении пула
+func (t *StatusTracker) RemoveWorker(workerID int) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.workers, workerID)
+}
+
+// WorkerIdle регистрирует воркер, готовый к приему задач
+func (t *StatusTracker) WorkerIdle(workerID int) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setWorkerStatus(t.worker(workerID), StatusIdle, "")
+}
+
+// Task возвращает состояние задачи по идентификатору
+func (t *StatusTracker) Task(id string) (TaskState, bool) {
+	if t == nil {
+		return TaskState{}, false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	state, ok := t.tasks[id]
+	if !ok {
+		return TaskState{}, false
+	}
+	return *state, true
+}
+
+// Tasks возвращает состояния всех задач, отсортированные по времени постановки в очередь
+func (t *StatusTracker) Tasks() []TaskState {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.RLock()
+	states := make([]TaskState, 0, len(t.tasks))
+	for _, state := range t.tasks {
+		states = append(states, *state)
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].EnqueuedAt.Before(states[j].EnqueuedAt)
+	})
+	return states
+}
+
+// Workers возвращает состояния воркеров, отсортированные по номеру
+func (t *StatusTracker) Workers() []WorkerState {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.RLock()
+	states := make([]WorkerState, 0, len(t.workers))
+	for _, state := range t.workers {
+		states = append(states, *state)
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].ID < states[j].ID
+	})
+	return states
+}
+
+// TaskCounts возвращает количество задач в каждом состоянии, включая вытесненные из истории
+func (t *StatusTracker) TaskCounts() map[Status]int {
+	counts := make(map[Status]int)
+	if t == nil {
+		return counts
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for status, count := range t.counts {
+		if count > 0 {
+			counts[status] = count
+		}
+	}
+	return counts
+}
+
+// worker возвращает состояние воркера, создавая его при первом обращении; вызывается под блокировкой
+func (t *StatusTracker) worker(id int) *WorkerState {
+	w, ok := t.workers[id]
+	if !ok {
+		w = &WorkerState{ID: id, Status: StatusIdle}
+		t.workers[id] = w
+	}
+	return w
+}
+
+// setTaskStatus меняет состояние задачи и рассылает событие; вызывается под блокировкой
+func (t *StatusTracker) setTaskStatus(state *TaskState, status Status, errText string) {
+	from := state.Status
+	state.Status = status
+	if errText != "" {
+		state.LastError = errText
+	}
+	if from != "" {
+		t.counts[from]--
+	}
+	t.counts[status]++
+	if status == StatusCompleted || status == StatusError {
+		t.finished = append(t.finished, state)
+		t.trimHistory()
+	}
+	t.publish(StatusEvent{TaskID: state.ID, WorkerID: state.WorkerID, From: from, To: status, Time: time.Now(), Error: errText})
+}
+
+// trimHistory удаляет самые давние завершенные задачи сверх history; они остаются
+// в счетчиках. Задача, вытесненная и затем поставленная заново, считается дважды:
+// лишняя ошибка лишь отменит пометку пропавших записей. Вызывается под блокировкой.
+func (t *StatusTracker) trimHistory() {
+	for len(t.finished) > t.history {
+		state := t.finished[0]
+		t.finished[0] = nil
+		t.finished = t.finished[1:]
+		// Задача могла быть поставлена заново или завершиться еще раз позже
+		if t.tasks[state.ID] == state && (state.Status == StatusCompleted || state.Status == StatusError) && !t.finishedLater(state) {
+			delete(t.tasks, state.ID)
+		}
+	}
+}
+
+// finishedLater сообщает, что задача завершалась еще раз и осталась в истории; вызывается под блокировкой
+func (t *StatusTracker) finishedLater(state *TaskState) bool {
+	for _, later := range t.finished {
+		if later == state {
+			return true
+		}
+	}
+	return false
+}
+
+// setWorkerStatus меняет состояние воркера и рассылает событие; вызывается под блокировкой
+func (t *StatusTracker) setWorkerStatus(w *WorkerState, status Status, errText string) {
+	from := w.Status
+	w.Status = status
+	if errText != "" {
+		w.LastError = errText
+	}
+	if from == status && errText == "" {
+		return
+	}
+	t.publish(StatusEvent{TaskID: w.CurrentTask, WorkerID: w.ID, From: from, To: status, Time: time.Now(), Error: errText})
+}
+
+// publish рассылает событие подписчикам без блокировки; вызывается под блокировкой
+func (t *StatusTracker) publish(event StatusEvent) {
+	for _, ch := range t.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// AliveWorkers возвращает количество работающих воркеров рецептов в пуле
+func (tc *TaskController) AliveWorkers() int {
+	alive := 0
+	for _, w := range tc.workers() {
+		if w.Running() {
+			alive++
+		}
+	}
+	return alive
+}
+
+// TaskStates возвращает состояния всех задач контроллера
+func (tc *TaskController) TaskStates() []TaskState {
+	return tc.Tracker.Tasks()
+}
+
+// TaskState возвращает состояние задачи по идентификатору
+func (tc *TaskController) TaskState(id string) (TaskState, bool) {
+	return tc.Tracker.Task(id)
+}
+
+// WorkerStates возвращает состояния воркеров рецептов, включая текущую задачу и последнюю ошибку
+func (tc *TaskController) WorkerStates() []WorkerState {
+	return tc.Tracker.Workers()
+}
+
+// Subscribe подписывает на события смены состояний задач и воркеров
+func (tc *TaskController) Subscribe(buffer int) <-chan StatusEvent {
+	return tc.Tracker.Subscribe(buffer)
+}
+
+// Status возвращает снимок состояния очередей, задач и воркеров
+func (tc *TaskController) Status() ControllerStatus {
+	return ControllerStatus{
+		TaskQueueDepth:   len(tc.TaskQueue),
+		ScheduledTasks:   tc.Scheduler.Len(),
+		ResultQueueDepth: len(tc.ResultQueue),
+		WorkersCount:     tc.DesiredWorkers(),
+		AliveWorkers:     tc.AliveWorkers(),
+		Paused:           tc.Paused(),
+		RPS:              tc.RPS(),
+		Tasks:            tc.Tracker.TaskCounts(),
+		Workers:          tc.WorkerStates(),
+	}
+}
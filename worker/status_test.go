@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStatusTrackerLifecycle проверяет смену состояний задачи и воркера
+func TestStatusTrackerLifecycle(t *testing.T) {
+	tracker := NewStatusTracker()
+	events := tracker.Subscribe(10)
+
+	task := Task{ID: "zavtraki", Type: "recipe", Category: &entity.Category{Name: "завтраки", Href: "/recepty/zavtraki"}}
+
+	tracker.WorkerIdle(1)
+	tracker.TaskPending(task)
+	state, ok := tracker.Task(task.ID)
+	require.True(t, ok)
+	assert.Equal(t, StatusPending, state.Status)
+
+	tracker.TaskStarted(task, 1)
+	state, _ = tracker.Task(task.ID)
+	assert.Equal(t, StatusInProgress, state.Status)
+	assert.Equal(t, 1, state.WorkerID)
+
+	workers := tracker.Workers()
+	require.Len(t, workers, 1)
+	assert.Equal(t, StatusBusy, workers[0].Status)
+	assert.Equal(t, "завтраки", workers[0].Category)
+	assert.False(t, workers[0].StartedAt.IsZero())
+
+	tracker.TaskFinished(task.ID, 1, 5, nil)
+	state, _ = tracker.Task(task.ID)
+	assert.Equal(t, StatusCompleted, state.Status)
+	workers = tracker.Workers()
+	assert.Equal(t, StatusIdle, workers[0].Status)
+	assert.Equal(t, 5, workers[0].Processed)
+	assert.Empty(t, workers[0].CurrentTask)
+
+	// Ошибка переводит задачу и воркер в состояние Error
+	tracker.TaskPending(task)
+	tracker.TaskStarted(task, 1)
+	tracker.TaskFinished(task.ID, 1, 0, errors.New("timeout"))
+	state, _ = tracker.Task(task.ID)
+	assert.Equal(t, StatusError, state.Status)
+	assert.Equal(t, "timeout", state.LastError)
+	assert.Equal(t, StatusError, tracker.Workers()[0].Status)
+	assert.Equal(t, 1, tracker.TaskCounts()[StatusError])
+
+	// Первое событие — постановка задачи в очередь
+	event := <-events
+	assert.Equal(t, task.ID, event.TaskID)
+	assert.Equal(t, StatusPending, event.To)
+}
+
+// TestStatusTrackerNil проверяет, что воркер без контроллера работает с nil-трекером
+func TestStatusTrackerNil(t *testing.T) {
+	var tracker *StatusTracker
+
+	assert.NotPanics(t, func() {
+		tracker.TaskPending(Task{ID: "1"})
+		tracker.TaskStarted(Task{ID: "1"}, 1)
+		tracker.TaskFinished("1", 1, 0, nil)
+		tracker.WorkerIdle(1)
+	})
+	assert.Empty(t, tracker.Workers())
+	assert.Empty(t, tracker.Subscribe(1))
+}
+
+// TestStatusTrackerBoundedHistory проверяет, что трекер хранит только последние
+// завершенные задачи, а счетчики учитывают и вытесненные
+func TestStatusTrackerBoundedHistory(t *testing.T) {
+	tracker := NewStatusTracker()
+	tracker.history = 3
+	tracker.WorkerIdle(1)
+
+	for i := 0; i < 50; i++ {
+		task := Task{ID: fmt.Sprintf("task-%d", i), Type: "recipe"}
+		tracker.TaskPending(task)
+		tracker.TaskStarted(task, 1)
+		var err error
+		if i%10 == 0 {
+			err = errors.New("boom")
+		}
+		tracker.TaskFinished(task.ID, 1, 1, err)
+		assert.LessOrEqual(t, len(tracker.Tasks()), 3)
+	}
+
+	// В истории остаются последние задачи
+	_, ok := tracker.Task("task-0")
+	assert.False(t, ok)
+	state, ok := tracker.Task("task-49")
+	require.True(t, ok)
+	assert.Equal(t, StatusCompleted, state.Status)
+
+	counts := tracker.TaskCounts()
+	assert.Equal(t, 45, counts[StatusCompleted])
+	assert.Equal(t, 5, counts[StatusError])
+	assert.Zero(t, counts[StatusPending])
+
+	// Незавершенные задачи не вытесняются
+	pending := Task{ID: "pending", Type: "recipe"}
+	tracker.TaskPending(pending)
+	for i := 50; i < 60; i++ {
+		task := Task{ID: fmt.Sprintf("task-%d", i), Type: "recipe"}
+		tracker.TaskPending(task)
+		tracker.TaskStarted(task, 1)
+		tracker.TaskFinished(task.ID, 1, 1, nil)
+	}
+	_, ok = tracker.Task("pending")
+	assert.True(t, ok)
+	assert.Len(t, tracker.Tasks(), 4)
+	assert.Equal(t, 1, tracker.TaskCounts()[StatusPending])
+}
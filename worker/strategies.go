@@ -0,0 +1,319 @@
+package worker
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/extract"
+)
+
+// Пути страниц категорий и рецептов на сайте-источнике
+var (
+	categoryPath = regexp.MustCompile(`^/recepty/[^/]+/?$`)
+	recipePath   = regexp.MustCompile(`^/recepty/[^/]+/[^/]+`)
+)
+
+// categoryChain извлекает список категорий с главной страницы
+var categoryChain = extract.Chain[[]entity.Category]{
+	Stage: stageCategory,
+	Field: "categories",
+	Strategies: []extract.Strategy[[]entity.Category]{
+		{Name: extract.StrategyJSONLD, Extract: categoriesFromJSONLD},
+		{Name: extract.StrategyMicrodata, Extract: categoriesFromMicrodata},
+		{Name: extract.StrategySemantic, Extract: categoriesFromNav},
+		{Name: extract.StrategyCSS, Extract: categoriesFromCSS},
+	},
+	Candidates: func(doc *goquery.Selection) *goquery.Selection {
+		return linksTo(doc, categoryPath)
+	},
+}
+
+// recipeCardChain извлекает карточки рецептов со страницы категории
+var recipeCardChain = extract.Chain[[]entity.Recipe]{
+	Stage: stageRecipe,
+	Field: "recipes",
+	Strategies: []extract.Strategy[[]entity.Recipe]{
+		{Name: extract.StrategyJSONLD, Extract: recipeCardsFromJSONLD},
+		{Name: extract.StrategyMicrodata, Extract: recipeCardsFromMicrodata},
+		{Name: extract.StrategySemantic, Extract: recipeCardsFromArticles},
+		{Name: extract.StrategyCSS, Extract: recipeCardsFromCSS},
+	},
+	Candidates: func(doc *goquery.Selection) *goquery.Selection {
+		return linksTo(doc, recipePath)
+	},
+}
+
+// recipeNameChain извлекает название рецепта со страницы рецепта
+var recipeNameChain = extract.Chain[string]{
+	Stage: stageRecipeDetail,
+	Field: "name",
+	Strategies: []extract.Strategy[string]{
+		{Name: extract.StrategyJSONLD, Extract: structuredField(extract.RecipeFromJSONLD, recipeName)},
+		{Name: extract.StrategyMicrodata, Extract: structuredField(extract.RecipeFromMicrodata, recipeName)},
+		{Name: extract.StrategyRDFa, Extract: structuredField(extract.RecipeFromRDFa, recipeName)},
+		{Name: extract.StrategySemantic, Extract: func(doc *goquery.Selection) (string, bool) {
+			name := strings.TrimSpace(doc.Find("h1").First().Text())
+			return name, name != ""
+		}},
+	},
+	Candidates: func(doc *goquery.Selection) *goquery.Selection {
+		return doc.Find(`h1, h2, [class*="title"], [class*="Title"]`)
+	},
+}
+
+// recipeImageChain извлекает изображение рецепта; у части рецептов его нет
+var recipeImageChain = extract.Chain[string]{
+	Stage:    stageRecipeDetail,
+	Field:    "image",
+	Optional: true,
+	Strategies: []extract.Strategy[string]{
+		{Name: extract.StrategyJSONLD, Extract: structuredField(extract.RecipeFromJSONLD, recipeImage)},
+		{Name: extract.StrategyMicrodata, Extract: structuredField(extract.RecipeFromMicrodata, recipeImage)},
+		{Name: extract.StrategyRDFa, Extract: structuredField(extract.RecipeFromRDFa, recipeImage)},
+		{Name: extract.StrategySemantic, Extract: func(doc *goquery.Selection) (string, bool) {
+			image := strings.TrimSpace(doc.Find(`meta[property="og:image"]`).AttrOr("content", ""))
+			return image, image != ""
+		}},
+	},
+	Candidates: func(doc *goquery.Selection) *goquery.Selection {
+		return doc.Find("img[src]")
+	},
+}
+
+// recipeIngredientsChain извлекает ингредиенты со страницы рецепта
+var recipeIngredientsChain = extract.Chain[[]string]{
+	Stage: stageRecipeDetail,
+	Field: "ingredients",
+	Strategies: []extract.Strategy[[]string]{
+		{Name: extract.StrategyJSONLD, Extract: structuredField(extract.RecipeFromJSONLD, recipeIngredients)},
+		{Name: extract.StrategyMicrodata, Extract: func(doc *goquery.Selection) ([]string, bool) {
+			// Свойство ищется по всей странице: сайты размечают ингредиенты и без itemscope
+			ingredients := extract.Props(doc, "recipeIngredient")
+			return ingredients, len(ingredients) > 0
+		}},
+		{Name: extract.StrategyRDFa, Extract: structuredField(extract.RecipeFromRDFa, recipeIngredients)},
+	},
+	Candidates: func(doc *goquery.Selection) *goquery.Selection {
+		return doc.Find(`[class*="ngredient"] li, [class*="ngredient"] span`)
+	},
+}
+
+// recipeDetailsChain извлекает структурированные данные рецепта из разметки schema.org
+var recipeDetailsChain = extract.Chain[*entity.RecipeDetails]{
+	Stage:    stageRecipeDetail,
+	Field:    "details",
+	Optional: true,
+	Strategies: []extract.Strategy[*entity.RecipeDetails]{
+		{Name: extract.StrategyJSONLD, Extract: structuredField(extract.RecipeFromJSONLD, recipeDetails)},
+		{Name: extract.StrategyMicrodata, Extract: structuredField(extract.RecipeFromMicrodata, recipeDetails)},
+		{Name: extract.StrategyRDFa, Extract: structuredField(extract.RecipeFromRDFa, recipeDetails)},
+	},
+	Candidates: func(doc *goquery.Selection) *goquery.Selection {
+		return doc.Find(`script[type="application/ld+json"], [itemscope], [typeof]`)
+	},
+}
+
+// categoriesFromJSONLD извлекает категории из списка ItemList
+func categoriesFromJSONLD(doc *goquery.Selection) ([]entity.Category, bool) {
+	var categories []entity.Category
+	for _, list := range extract.JSONLD(doc, "ItemList") {
+		for _, element := range listElements(list) {
+			if href := relativeHref(element.url); categoryPath.MatchString(href) {
+				categories = append(categories, entity.Category{Name: element.name, Href: href})
+			}
+		}
+	}
+	return categories, len(categories) > 0
+}
+
+// categoriesFromMicrodata извлекает категории из элементов ItemList в разметке microdata
+func categoriesFromMicrodata(doc *goquery.Selection) ([]entity.Category, bool) {
+	var categories []entity.Category
+	extract.ItemScopes(doc, "ItemList").Find(`[itemprop~="itemListElement"]`).Each(func(i int, s *goquery.Selection) {
+		href := relativeHref(firstNonEmpty(extract.Prop(s, "url"), s.Find("a[href]").AttrOr("href", "")))
+		if categoryPath.MatchString(href) {
+			categories = append(categories, entity.Category{Name: extract.Prop(s, "name"), Href: href})
+		}
+	})
+	return categories, len(categories) > 0
+}
+
+// categoriesFromNav извлекает категории из ссылок навигации
+func categoriesFromNav(doc *goquery.Selection) ([]entity.Category, bool) {
+	var categories []entity.Category
+	linksTo(doc.Find(`nav, [role="navigation"]`), categoryPath).Each(func(i int, s *goquery.Selection) {
+		categories = append(categories, entity.Category{Name: s.Text(), Href: relativeHref(s.AttrOr("href", ""))})
+	})
+	return categories, len(categories) > 0
+}
+
+// categoriesFromCSS извлекает категории по классам текущей верстки eda.ru
+func categoriesFromCSS(doc *goquery.Selection) ([]entity.Category, bool) {
+	var categories []entity.Category
+	doc.Find(".emotion-18mh8uc .emotion-c3fqwx").Each(func(i int, s *goquery.Selection) {
+		categories = append(categories, entity.Category{
+			// Название без текста вложенных элементов (счетчика рецептов)
+			Name: s.Find("a .emotion-1ooehk6").Clone().Children().Remove().End().Text(),
+			Href: s.Find("a").AttrOr("href", ""),
+		})
+	})
+	return categories, len(categories) > 0
+}
+
+// recipeCardsFromJSONLD извлекает карточки рецептов из ItemList или отдельных объектов Recipe
+func recipeCardsFromJSONLD(doc *goquery.Selection) ([]entity.Recipe, bool) {
+	var recipes []entity.Recipe
+	for _, list := range extract.JSONLD(doc, "ItemList") {
+		for _, element := range listElements(list) {
+			if href := relativeHref(element.url); recipePath.MatchString(href) {
+				recipes = append(recipes, entity.Recipe{Name: element.name, Href: href, ImageURL: element.image})
+			}
+		}
+	}
+	for _, object := range extract.JSONLD(doc, "Recipe") {
+		if href := relativeHref(extract.String(object["url"])); recipePath.MatchString(href) {
+			recipes = append(recipes, entity.Recipe{
+				Name:     extract.String(object["name"]),
+				Href:     href,
+				ImageURL: extract.String(object["image"]),
+			})
+		}
+	}
+	return recipes, len(recipes) > 0
+}
+
+// recipeCardsFromMicrodata извлекает карточки рецептов из элементов Recipe в разметке microdata
+func recipeCardsFromMicrodata(doc *goquery.Selection) ([]entity.Recipe, bool) {
+	var recipes []entity.Recipe
+	extract.ItemScopes(doc, "Recipe").Each(func(i int, s *goquery.Selection) {
+		href := relativeHref(firstNonEmpty(extract.Prop(s, "url"), s.Find("a[href]").AttrOr("href", "")))
+		if recipePath.MatchString(href) {
+			recipes = append(recipes, entity.Recipe{Name: extract.Prop(s, "name"), Href: href, ImageURL: extract.Prop(s, "image")})
+		}
+	})
+	return recipes, len(recipes) > 0
+}
+
+// recipeCardsFromArticles извлекает карточки рецептов из элементов article
+func recipeCardsFromArticles(doc *goquery.Selection) ([]entity.Recipe, bool) {
+	var recipes []entity.Recipe
+	doc.Find("article").Each(func(i int, s *goquery.Selection) {
+		link := linksTo(s, recipePath).First()
+		if link.Length() == 0 {
+			return
+		}
+		img := s.Find("img").First()
+		recipes = append(recipes, entity.Recipe{
+			Name:     firstNonEmpty(strings.TrimSpace(s.Find("h2, h3").First().Text()), img.AttrOr("alt", "")),
+			Href:     relativeHref(link.AttrOr("href", "")),
+			ImageURL: img.AttrOr("src", ""),
+		})
+	})
+	return recipes, len(recipes) > 0
+}
+
+// recipeCardsFromCSS извлекает карточки рецептов по классам текущей верстки eda.ru
+func recipeCardsFromCSS(doc *goquery.Selection) ([]entity.Recipe, bool) {
+	var recipes []entity.Recipe
+	doc.Find(".emotion-13pp0tv").Each(func(i int, s *goquery.Selection) {
+		img := s.Find("img").First()
+		recipes = append(recipes, entity.Recipe{
+			Name:     img.AttrOr("alt", ""),
+			Href:     s.AttrOr("href", ""),
+			ImageURL: img.AttrOr("src", ""),
+		})
+	})
+	return recipes, len(recipes) > 0
+}
+
+// structuredField создает стратегию, берущую поле из рецепта, извлеченного из разметки schema.org
+func structuredField[T any](from func(doc *goquery.Selection) (entity.Recipe, bool), field func(entity.Recipe) (T, bool)) func(doc *goquery.Selection) (T, bool) {
+	return func(doc *goquery.Selection) (T, bool) {
+		recipe, ok := from(doc)
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		return field(recipe)
+	}
+}
+
+// recipeName возвращает название рецепта для structuredField
+func recipeName(r entity.Recipe) (string, bool) {
+	return r.Name, r.Name != ""
+}
+
+// recipeIngredients возвращает ингредиенты рецепта для structuredField
+func recipeIngredients(r entity.Recipe) ([]string, bool) {
+	return r.Ingredients, len(r.Ingredients) > 0
+}
+
+// recipeImage возвращает основное изображение рецепта для structuredField
+func recipeImage(r entity.Recipe) (string, bool) {
+	if r.Details == nil || len(r.Details.Images) == 0 {
+		return "", false
+	}
+	return r.Details.Images[0], true
+}
+
+// recipeDetails возвращает структурированные данные рецепта для structuredField
+func recipeDetails(r entity.Recipe) (*entity.RecipeDetails, bool) {
+	return r.Details, r.Details != nil
+}
+
+// listElement — элемент списка ItemList из JSON-LD
+type listElement struct {
+	name, url, image string
+}
+
+// listElements возвращает элементы ItemList; данные элемента могут быть вложены в item
+func listElements(list map[string]any) []listElement {
+	items, _ := list["itemListElement"].([]any)
+
+	var elements []listElement
+	for _, raw := range items {
+		element, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		if item, ok := element["item"].(map[string]any); ok {
+			element = item
+		}
+		elements = append(elements, listElement{
+			name:  extract.String(element["name"]),
+			url:   firstNonEmpty(extract.String(element["url"]), extract.String(element["@id"]), extract.String(element["item"])),
+			image: extract.String(element["image"]),
+		})
+	}
+	return elements
+}
+
+// linksTo возвращает ссылки внутри элементов, путь которых соответствует шаблону
+func linksTo(s *goquery.Selection, path *regexp.Regexp) *goquery.Selection {
+	return s.Find("a[href]").FilterFunction(func(i int, link *goquery.Selection) bool {
+		return path.MatchString(relativeHref(link.AttrOr("href", "")))
+	})
+}
+
+// relativeHref приводит абсолютную ссылку к пути с запросом, как в ссылках верстки
+func relativeHref(href string) string {
+	href = strings.TrimSpace(href)
+	u, err := url.Parse(href)
+	if err != nil || u.Host == "" {
+		return href
+	}
+	return u.RequestURI()
+}
+
+// firstNonEmpty возвращает первую непустую строку
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestCategoryChain проверяет извлечение категорий разными стратегиями
+func TestCategoryChain(t *testing.T) {
+	pages := map[string]string{
+		"json-ld": `<script type="application/ld+json">{"@type":"ItemList","itemListElement":[
+{"@type":"ListItem","item":{"@id":"https://eda.ru/recepty/supy","name":"Супы"}},
+{"@type":"ListItem","url":"https://eda.ru/recepty/supy/borsch-1","name":"Борщ"}]}</script>`,
+		"microdata": `<ul itemscope itemtype="http://schema.org/ItemList"><li itemprop="itemListElement"><a href="/recepty/supy"><span itemprop="name">Супы</span></a></li></ul>`,
+		"semantic":  `<nav><a href="/recepty/supy">Супы</a><a href="/about">О нас</a></nav>`,
+		"css":       `<div class="emotion-18mh8uc"><div class="emotion-c3fqwx"><a href="/recepty/supy"><span class="emotion-1ooehk6">Супы<span>120</span></span></a></div></div>`,
+	}
+
+	for name, html := range pages {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		require.NoError(t, err)
+
+		categories, ok := categoryChain.Run(doc.Selection, "https://eda.ru", zap.NewNop())
+		require.True(t, ok, name)
+		require.Len(t, categories, 1, name)
+		categories[0].Normalize()
+		assert.Equal(t, entity.Category{Name: "супы", Href: "/recepty/supy"}, categories[0], name)
+	}
+}
+
+// TestRecipeCardChain проверяет переход к классам верстки, если структурированной разметки нет
+func TestRecipeCardChain(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(
+		`<a class="emotion-13pp0tv" href="/recepty/supy/borsch-1"><img alt="Борщ" src="/b.jpg"></a>`))
+	require.NoError(t, err)
+
+	recipes, ok := recipeCardChain.Run(doc.Selection, "https://eda.ru/recepty/supy", zap.NewNop())
+	require.True(t, ok)
+	assert.Equal(t, []entity.Recipe{{Name: "Борщ", Href: "/recepty/supy/borsch-1", ImageURL: "/b.jpg"}}, recipes)
+
+	_, ok = recipeCardChain.Run(doc.Find("img"), "https://eda.ru/recepty/supy", zap.NewNop())
+	assert.False(t, ok)
+}
@@ -2,11 +2,22 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/seniorcat/scraper/database"
 	"github.com/seniorcat/scraper/entity"
+	"github.com/seniorcat/scraper/pkg/anomaly"
+	"github.com/seniorcat/scraper/pkg/dedup"
+	"github.com/seniorcat/scraper/pkg/diet"
+	"github.com/seniorcat/scraper/pkg/imaging"
+	"github.com/seniorcat/scraper/pkg/ingredient"
+	"github.com/seniorcat/scraper/pkg/metrics"
+	"github.com/seniorcat/scraper/pkg/nutrition"
 	"go.uber.org/zap"
 )
 
@@ -25,15 +36,20 @@ const (
 // Task представляет собой задачу, которая должна быть обработана воркером
 type Task struct {
 	ID         string
-	Type       string
-	Category   *entity.Category
+	Type       string           // Тип задачи; для него должен быть зарегистрирован обработчик
+	Category   *entity.Category // Категория задачи TaskListing
+	Payload    any              // Нагрузка остальных типов задач, например RecipeDetailPayload
 	RetryCount int
 }
 
 // Result представляет результат выполнения задачи
 type Result struct {
-	TaskID  string
-	Recipes []entity.Recipe
+	TaskID   string
+	Type     string           // Тип выполненной задачи
+	Category *entity.Category // Категория, рецепты которой получены (может быть nil)
+	Recipes  []entity.Recipe
+	Listed   []string // Канонические URL всех карточек страницы категории, включая не вошедшие в лимит
+	Data     any      // Результат, отличный от рецептов, например []entity.Category, Image или LinkStatus
 }
 
 // TaskController управляет распределением задач между воркерами
@@ -50,12 +66,57 @@ type TaskController struct {
 	retryInterval time.Duration
 	maxRetries    int
 
+	// Параметры создания воркеров, запоминаются при запуске для замены упавших
+	maxRecipes int
+	rps        int
+	timeout    time.Duration
+
+	TaskDeadline     time.Duration // Максимальное время выполнения задачи; 0 отключает сторожа
+	WatchdogInterval time.Duration // Период проверок сторожа
+
+	mu     sync.RWMutex // Защищает RecipeWorkers, WorkersCount, rps и nextID
+	nextID int
+
+	scaleMu sync.Mutex // Упорядочивает изменение размера пула
+	Gate    *Gate      // Приостановка выдачи задач воркерам
+
+	queueMu     sync.RWMutex // Защищает отправку в TaskQueue от ее закрытия
+	queueClosed bool
+
 	wg        sync.WaitGroup
+	resultsWg sync.WaitGroup              // Ожидание ProcessResults, запущенного из Start
+	done      chan struct{}               // Закрывается при остановке контроллера
 	DBService database.DBServiceInterface // Используем интерфейс вместо структуры
+
+	Deduplicator *dedup.Deduplicator // Отсеивает дубликаты рецептов перед сохранением (может быть nil)
+	Tracker      *StatusTracker      // Состояния задач и воркеров
+	Registry     *Registry           // Обработчики типов задач
+
+	Scheduler *Scheduler                  // Очередь с приоритетами перед TaskQueue (может быть nil)
+	Stats     database.CategoryStatsStore // История обходов категорий для планировщика (может быть nil)
+
+	Tombstones database.TombstoneStore // Пометка удаленными пропавших страниц (может быть nil)
+	Yield      *anomaly.Collector      // Показатели извлечения для поиска аномалий (может быть nil)
+	Catalog    *ingredient.Catalog     // Каталог для привязки ингредиентов к каноническим (может быть nil)
+	Nutrition  *nutrition.Calculator   // Расчет пищевой ценности рецептов (может быть nil)
+	Diet       *diet.Classifier        // Метки диет и аллергенов рецептов (может быть nil)
+	Imaging    *imaging.Processor      // Сохранение изображений в хранилище; вместе с Images включает этап изображений
+	Images     database.ImageStore     // Сведения о сохраненных изображениях (может быть nil)
+	ImageLimit *RateLimiter            // Общее ограничение загрузки изображений всеми воркерами (может быть nil)
+	Details    database.DetailStore    // Отбор рецептов, страницы которых нужно загрузить; nil — загружаются все
+
+	inFlight      atomic.Int64 // Результаты в обработке и задачи, ожидающие повторной постановки
+	queuedImages  sync.Map     // Изображения, уже поставленные в очередь в этом запуске
+	queuedDetails sync.Map     // Страницы рецептов, уже поставленные в очередь в этом запуске
 }
 
 // NewTaskController создает новый экземпляр TaskController
 func NewTaskController(categoryWorker *CategoryWorker, workersCount int, logger *zap.Logger, retryInterval time.Duration, maxRetries int, dbService database.DBServiceInterface) *TaskController {
+	registry := NewDefaultRegistry()
+	if categoryWorker != nil {
+		registry.Register(TaskCategoryCrawl, CategoryCrawlHandler(categoryWorker))
+	}
+
 	return &TaskController{
 		CategoryWorker: categoryWorker,
 		RecipeWorkers:  make([]*RecipeWorker, 0, workersCount), // Создаем слайс для пула воркеров
@@ -68,64 +129,572 @@ func NewTaskController(categoryWorker *CategoryWorker, workersCount int, logger
 		Logger:        logger,
 		retryInterval: retryInterval,
 		maxRetries:    maxRetries,
+		done:          make(chan struct{}),
 		DBService:     dbService,
+		Tracker:       NewStatusTracker(),
+		Registry:      registry,
+		Gate:          NewGate(),
 	}
 }
 
 // InitWorkerPool инициализирует пул воркеров
 func (tc *TaskController) InitWorkerPool(maxRecipes int, rps int, timeout time.Duration) {
+	tc.mu.Lock()
+	tc.maxRecipes = maxRecipes
+	tc.rps = rps
+	tc.timeout = timeout
+	tc.mu.Unlock()
+
+	tc.scaleMu.Lock()
+	defer tc.scaleMu.Unlock()
+
 	// Создаем воркеры и добавляем их в пул
-	for i := 0; i < tc.WorkersCount; i++ {
-		worker := NewRecipeWorker(tc.Logger, maxRecipes, rps, timeout)
-		tc.RecipeWorkers = append(tc.RecipeWorkers, worker)
+	count := tc.DesiredWorkers()
+	for i := 0; i < count; i++ {
+		tc.startWorker()
+	}
+
+	tc.Logger.Info("Worker pool initialized", zap.Int("workers_count", count))
+}
+
+// startWorker создает воркер, добавляет его в пул и запускает в отдельной горутине
+func (tc *TaskController) startWorker() *RecipeWorker {
+	tc.mu.Lock()
+	worker := NewRecipeWorker(tc.Logger, tc.maxRecipes, tc.rps, tc.timeout)
+	worker.Tracker = tc.Tracker
+	worker.Gate = tc.Gate
+	worker.Registry = tc.Registry
+	worker.Retry = tc.retryTask
+	worker.Parser.Yield = tc.Yield
+	worker.Parser.Catalog = tc.Catalog
+	worker.Parser.Nutrition = tc.Nutrition
+	worker.Parser.Diet = tc.Diet
+	worker.Parser.Imaging = tc.Imaging
+	worker.Parser.ImageLimit = tc.ImageLimit
+
+	tc.nextID++
+	worker.ID = tc.nextID
+	tc.RecipeWorkers = append(tc.RecipeWorkers, worker)
+	tc.mu.Unlock()
+
+	tc.Tracker.WorkerIdle(worker.ID)
+
+	// Добавляем каждого воркера в группу ожидания
+	tc.wg.Add(1)
 
-		// Добавляем каждого воркера в группу ожидания
-		tc.wg.Add(1)
+	// Запускаем каждого воркера в отдельной горутине
+	worker.running.Store(true)
+	go tc.runWorker(worker)
 
-		// Запускаем каждого воркера в отдельной горутине
-		go func(w *RecipeWorker) {
-			defer tc.wg.Done() // После завершения работы воркера уменьшаем счетчик WaitGroup
-			w.ProcessTasks(tc.TaskQueue, tc.ResultQueue)
-		}(worker)
+	return worker
+}
+
+// runWorker выполняет цикл воркера и исключает его из пула при панике
+func (tc *TaskController) runWorker(w *RecipeWorker) {
+	defer tc.detachWorker(w)      // После завершения работы воркера уменьшаем счетчик WaitGroup
+	defer w.Parser.Limiter.Stop() // Лимитер воркера больше не нужен
+	defer func() {
+		if r := recover(); r != nil {
+			tc.Logger.Error("Recipe worker died", zap.Int("worker_id", w.ID), zap.Any("panic", r))
+			tc.Tracker.WorkerFailed(w.ID, fmt.Errorf("panic: %v", r))
+			tc.removeWorker(w)
+		}
+	}()
+
+	w.ProcessTasks(tc.TaskQueue, tc.ResultQueue)
+}
+
+// detachWorker исключает воркер из группы ожидания ровно один раз: либо при его
+// завершении, либо раньше, если сторож признал его зависшим
+func (tc *TaskController) detachWorker(w *RecipeWorker) {
+	if w.detached.CompareAndSwap(false, true) {
+		tc.wg.Done()
+	}
+}
+
+// removeWorker удаляет воркер из пула
+func (tc *TaskController) removeWorker(w *RecipeWorker) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	for i, candidate := range tc.RecipeWorkers {
+		if candidate == w {
+			tc.RecipeWorkers = append(tc.RecipeWorkers[:i], tc.RecipeWorkers[i+1:]...)
+			return
+		}
 	}
+}
+
+// workers возвращает копию пула воркеров
+func (tc *TaskController) workers() []*RecipeWorker {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 
-	tc.Logger.Info("Worker pool initialized", zap.Int("workers_count", tc.WorkersCount))
+	workers := make([]*RecipeWorker, len(tc.RecipeWorkers))
+	copy(workers, tc.RecipeWorkers)
+	return workers
 }
 
 // Start запускает контроллер задач для обработки всех задач из очереди
 func (tc *TaskController) Start(maxRecipes int, rps int, timeout time.Duration) {
-	tc.wg.Add(tc.WorkersCount)
 	// Инициализация пула воркеров
 	tc.InitWorkerPool(maxRecipes, rps, timeout)
 
 	// Запуск обработки результатов
-	go tc.ProcessResults()
+	tc.resultsWg.Add(1)
+	go func() {
+		defer tc.resultsWg.Done()
+		tc.ProcessResults()
+	}()
+
+	// Периодическая выгрузка глубины очередей в метрики
+	go tc.reportQueueMetrics(time.Second)
+
+	// Журналирование смены состояний задач и воркеров
+	go tc.logStatusEvents(tc.Subscribe(100))
+
+	// Сторож зависших задач и упавших воркеров
+	if tc.TaskDeadline > 0 {
+		go tc.runWatchdog()
+	}
+
+	// Выдача задач из очереди с приоритетами
+	if tc.Scheduler != nil {
+		go tc.dispatch()
+	}
+}
+
+// UsePriorityScheduler включает выдачу задач по приоритету. Вызывается до Start:
+// TaskQueue становится небуферизованной, чтобы задачи ждали воркеров в планировщике.
+func (tc *TaskController) UsePriorityScheduler(priority PriorityFunc) {
+	tc.Scheduler = NewScheduler(priority)
+	tc.TaskQueue = make(chan Task)
+}
+
+// dispatch передает воркерам задачи из планировщика до остановки контроллера
+func (tc *TaskController) dispatch() {
+	for {
+		task, ok := tc.Scheduler.Pop(tc.done)
+		if !ok {
+			return
+		}
+		if !tc.send(task) {
+			return
+		}
+	}
+}
+
+// send отправляет задачу в TaskQueue, если контроллер еще не остановлен
+func (tc *TaskController) send(task Task) bool {
+	tc.queueMu.RLock()
+	defer tc.queueMu.RUnlock()
+
+	if tc.queueClosed {
+		return false
+	}
+
+	select {
+	case tc.TaskQueue <- task:
+		return true
+	case <-tc.done:
+		return false
+	}
+}
+
+// AddTask ставит задачу в очередь и отмечает ее как ожидающую.
+// Задача неизвестного типа и задача после остановки контроллера отбрасываются.
+func (tc *TaskController) AddTask(task Task) bool {
+	if _, ok := tc.Registry.Lookup(task.Type); !ok {
+		reportUnknownTask(tc.Logger, tc.Tracker, task)
+		return false
+	}
+
+	tc.queueMu.RLock()
+	defer tc.queueMu.RUnlock()
+
+	if tc.queueClosed {
+		tc.Logger.Warn("Task dropped: controller is stopped", zap.String("task_id", task.ID))
+		return false
+	}
+
+	tc.Tracker.TaskPending(task)
+	if tc.Scheduler != nil {
+		tc.Scheduler.Push(task)
+		return true
+	}
+
+	select {
+	case tc.TaskQueue <- task:
+		return true
+	case <-tc.done:
+		return false
+	}
 }
 
 // Stop завершает работу контроллера задач
 func (tc *TaskController) Stop() {
+	close(tc.done)
+
 	// Закрываем TaskQueue, чтобы прекратить отправку новых задач
+	tc.queueMu.Lock()
+	tc.queueClosed = true
 	close(tc.TaskQueue)
+	tc.queueMu.Unlock()
+
+	// Воркеры на паузе ждут шлюз и не увидят закрытую очередь, пока он закрыт
+	tc.Gate.Resume()
 
 	// Ждем завершения всех воркеров
 	tc.wg.Wait()
 
+	// Остановка лимитеров, которые не принадлежат воркерам рецептов
+	if tc.CategoryWorker != nil {
+		tc.CategoryWorker.Parser.Limiter.Stop()
+	}
+	tc.ImageLimit.Stop()
+
 	// Закрываем ResultQueue только после того, как все результаты были отправлены
 	close(tc.ResultQueue)
+
+	// Ждем, пока оставшиеся результаты будут переданы на сохранение
+	tc.resultsWg.Wait()
 }
 
 // ProcessResults обрабатывает результаты из канала ResultQueue и сохраняет их в базу данных
 func (tc *TaskController) ProcessResults() {
 	ctx := context.Background()
 	for result := range tc.ResultQueue {
-		// Логирование результата
-		tc.Logger.Info("Result received", zap.String("task_id", result.TaskID), zap.Int("recipes_count", len(result.Recipes)))
-
-		// Сохранение рецептов в базу данных
-		if err := tc.DBService.SaveRecipes(ctx, result.Recipes); err != nil {
-			tc.Logger.Error("Failed to save recipes", zap.Error(err))
-		} else {
-			tc.Logger.Info("Recipes saved successfully", zap.String("task_id", result.TaskID))
+		tc.inFlight.Add(1)
+		tc.processResult(ctx, result)
+		tc.inFlight.Add(-1)
+	}
+}
+
+// processResult сохраняет один результат выполнения задачи
+func (tc *TaskController) processResult(ctx context.Context, result Result) {
+	// Логирование результата
+	tc.Logger.Info("Result received",
+		zap.String("task_id", result.TaskID),
+		zap.String("type", result.Type),
+		zap.Int("recipes_count", len(result.Recipes)))
+
+	// Учет обхода категории для планировщика
+	tc.recordCrawl(ctx, result)
+	if result.Type == TaskListing && result.Category != nil {
+		tc.Yield.CategoryYield(result.Category.CanonicalURL, len(result.Recipes))
+	}
+
+	switch data := result.Data.(type) {
+	case []entity.Category:
+		tc.processCategories(ctx, data)
+	case Image:
+		tc.Logger.Info("Image downloaded", zap.String("url", data.URL), zap.Int("size", len(data.Body)))
+	case entity.RecipeImage:
+		tc.saveImage(ctx, data)
+	case LinkStatus:
+		tc.Logger.Info("Link checked", zap.String("url", data.URL), zap.Int("status", data.StatusCode), zap.Bool("alive", data.Alive))
+		if entity.IsGoneStatus(data.StatusCode) {
+			if canonicalURL, err := dedup.CanonicalURL(data.URL, data.URL); err == nil {
+				tc.markGone(ctx, Gone{URL: canonicalURL, StatusCode: data.StatusCode})
+			}
+		}
+	case Gone:
+		tc.markGone(ctx, data)
+	}
+	tc.markSeen(ctx, result.Listed)
+
+	if len(result.Recipes) == 0 {
+		return
+	}
+
+	// Отсеивание рецептов, уже встреченных в текущем обходе
+	recipes := tc.filterDuplicates(result)
+
+	// Страницы рецептов отбираются до сохранения: оно обновляет хеш карточки
+	var stale []entity.Recipe
+	if result.Type == TaskListing {
+		stale = tc.staleRecipes(ctx, recipes)
+	}
+
+	// Сохранение рецептов в базу данных
+	if err := tc.DBService.SaveRecipes(ctx, recipes); err != nil {
+		tc.Logger.Error("Failed to save recipes", zap.Error(err))
+		return
+	}
+	tc.Logger.Info("Recipes passed to storage", zap.String("task_id", result.TaskID))
+	tc.enqueueDetails(stale)
+	tc.enqueueImages(ctx, recipes)
+}
+
+// staleRecipes возвращает рецепты из списка категории, страницы которых нужно загрузить
+func (tc *TaskController) staleRecipes(ctx context.Context, recipes []entity.Recipe) []entity.Recipe {
+	if tc.Details == nil || len(recipes) == 0 {
+		return recipes
+	}
+
+	stale, err := tc.Details.StaleRecipes(ctx, recipes)
+	if err != nil {
+		tc.Logger.Warn("Не удалось проверить загруженные страницы рецептов", zap.Error(err))
+		return recipes
+	}
+	return stale
+}
+
+// enqueueDetails ставит задачи загрузки страниц рецептов: карточка в списке
+// категории не содержит ингредиентов
+func (tc *TaskController) enqueueDetails(recipes []entity.Recipe) {
+	var tasks []Task
+	for _, recipe := range recipes {
+		if recipe.CanonicalURL == "" {
+			continue
+		}
+		// Рецепт из нескольких категорий загружается один раз за запуск
+		if _, queued := tc.queuedDetails.LoadOrStore(recipe.CanonicalURL, true); queued {
+			continue
+		}
+		tasks = append(tasks, Task{
+			ID:      "detail:" + recipe.CanonicalURL,
+			Type:    TaskRecipeDetail,
+			Payload: RecipeDetailPayload{URL: recipe.CanonicalURL, CanonicalURL: recipe.CanonicalURL},
+		})
+	}
+	if len(tasks) == 0 {
+		return
+	}
+
+	// AddTask ждет места в TaskQueue, которое освобождают воркеры, ждущие ResultQueue
+	tc.inFlight.Add(1)
+	go func() {
+		defer tc.inFlight.Add(-1)
+		for _, task := range tasks {
+			tc.AddTask(task)
+		}
+	}()
+}
+
+// enqueueImages ставит задачи загрузки изображений рецептов, которых еще нет в хранилище
+func (tc *TaskController) enqueueImages(ctx context.Context, recipes []entity.Recipe) {
+	if tc.Imaging == nil || tc.Images == nil {
+		return
+	}
+
+	var images []entity.RecipeImage
+	for _, recipe := range recipes {
+		if recipe.CanonicalURL == "" {
+			continue
+		}
+		for _, ref := range recipe.ImageRefs() {
+			// Одно изображение рецепта ставится в очередь один раз за запуск
+			key := recipe.CanonicalURL + " " + ref.URL
+			if _, queued := tc.queuedImages.LoadOrStore(key, true); queued {
+				continue
+			}
+			images = append(images, entity.RecipeImage{RecipeURL: recipe.CanonicalURL, SourceURL: ref.URL, Kind: ref.Kind, Position: ref.Position})
+		}
+	}
+	if len(images) == 0 {
+		return
+	}
+
+	// Проверка в базе и AddTask, ждущий места в TaskQueue, не должны задерживать ProcessResults
+	tc.inFlight.Add(1)
+	go func() {
+		defer tc.inFlight.Add(-1)
+
+		missing, err := tc.Images.MissingImages(ctx, images)
+		if err != nil {
+			tc.Logger.Warn("Не удалось проверить загруженные изображения", zap.Error(err))
+			missing = images
+		}
+		for _, image := range missing {
+			tc.AddTask(Task{
+				ID:      fmt.Sprintf("image:%s:%s:%d", image.RecipeURL, image.Kind, image.Position),
+				Type:    TaskImageDownload,
+				Payload: ImagePayload{URL: image.SourceURL, RecipeURL: image.RecipeURL, Kind: image.Kind, Position: image.Position},
+			})
+		}
+	}()
+}
+
+// saveImage сохраняет сведения об изображении, загруженном в хранилище
+func (tc *TaskController) saveImage(ctx context.Context, image entity.RecipeImage) {
+	tc.Logger.Info("Image stored",
+		zap.String("url", image.SourceURL),
+		zap.String("key", image.Key),
+		zap.Int("width", image.Width),
+		zap.Int("height", image.Height))
+	if tc.Images == nil {
+		return
+	}
+	if err := tc.Images.SaveImage(ctx, image); err != nil {
+		tc.Logger.Error("Failed to save image", zap.String("url", image.SourceURL), zap.Error(err))
+	}
+}
+
+// processCategories сохраняет найденные категории и ставит задачи на обход их страниц
+func (tc *TaskController) processCategories(ctx context.Context, categories []entity.Category) {
+	if err := tc.DBService.SaveCategories(ctx, categories); err != nil {
+		tc.Logger.Error("Failed to save categories", zap.Error(err))
+	}
+
+	// Постановка в очередь в отдельной горутине: ProcessResults не должен ждать
+	// свободного места в TaskQueue, которое освобождают воркеры, ждущие ResultQueue
+	tc.inFlight.Add(1)
+	go func() {
+		defer tc.inFlight.Add(-1)
+		for i := range categories {
+			tc.AddTask(Task{ID: categories[i].Name, Type: TaskListing, Category: &categories[i]})
+		}
+	}()
+}
+
+// reportQueueMetrics обновляет метрики глубины очередей до остановки контроллера
+func (tc *TaskController) reportQueueMetrics(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			metrics.TaskQueueDepth.Set(float64(len(tc.TaskQueue) + tc.Scheduler.Len()))
+			metrics.ResultQueueDepth.Set(float64(len(tc.ResultQueue)))
+		case <-tc.done:
+			return
+		}
+	}
+}
+
+// logStatusEvents журналирует события смены состояний до остановки контроллера
+func (tc *TaskController) logStatusEvents(events <-chan StatusEvent) {
+	for {
+		select {
+		case event := <-events:
+			tc.Logger.Debug("Status changed",
+				zap.String("task_id", event.TaskID),
+				zap.Int("worker_id", event.WorkerID),
+				zap.String("from", string(event.From)),
+				zap.String("to", string(event.To)),
+				zap.String("error", event.Error))
+		case <-tc.done:
+			return
+		}
+	}
+}
+
+// filterDuplicates удаляет из результата рецепты, признанные дубликатами. URL рецепта
+// со страницы рецепта уже встретился в списке категории, поэтому сравнивается только содержимое.
+func (tc *TaskController) filterDuplicates(result Result) []entity.Recipe {
+	if tc.Deduplicator == nil {
+		return result.Recipes
+	}
+
+	check := tc.Deduplicator.Check
+	if result.Type == TaskRecipeDetail {
+		check = tc.Deduplicator.CheckContent
+	}
+
+	unique := make([]entity.Recipe, 0, len(result.Recipes))
+	for _, recipe := range result.Recipes {
+		if original, duplicate := check(recipe); duplicate {
+			tc.Logger.Info("Duplicate recipe skipped",
+				zap.String("url", recipe.CanonicalURL),
+				zap.String("original", original))
+			continue
+		}
+		unique = append(unique, recipe)
+	}
+	return unique
+}
+
+// recordCrawl сохраняет успешный обход категории вместе с хешем состава ее рецептов
+func (tc *TaskController) recordCrawl(ctx context.Context, result Result) {
+	if tc.Stats == nil || result.Category == nil {
+		return
+	}
+
+	url := result.Category.CanonicalURL
+	if url == "" {
+		url = result.Category.Href
+	}
+	if err := tc.Stats.RecordCategoryCrawl(ctx, url, contentHash(result.Recipes)); err != nil {
+		tc.Logger.Error("Failed to record category crawl", zap.String("category", url), zap.Error(err))
+	}
+}
+
+// contentHash возвращает хеш набора URL рецептов, не зависящий от их порядка
+func contentHash(recipes []entity.Recipe) uint64 {
+	urls := make([]string, 0, len(recipes))
+	for _, recipe := range recipes {
+		url := recipe.CanonicalURL
+		if url == "" {
+			url = recipe.Href
+		}
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	h := fnv.New64a()
+	for _, url := range urls {
+		h.Write([]byte(url))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// markGone помечает удаленной запись, страница которой ответила 404 или 410
+func (tc *TaskController) markGone(ctx context.Context, gone Gone) {
+	tc.Logger.Warn("Page is gone", zap.String("url", gone.URL), zap.Int("status", gone.StatusCode))
+	if tc.Tombstones == nil {
+		return
+	}
+	if err := tc.Tombstones.MarkGone(ctx, gone.URL, entity.GoneReason(gone.StatusCode)); err != nil {
+		tc.Logger.Error("Failed to mark page as gone", zap.String("url", gone.URL), zap.Error(err))
+	}
+}
+
+// markSeen отмечает встреченными рецепты с карточками в списке категории: рецепты
+// сверх лимита и отсеянные как дубликаты не сохраняются, но со страниц не пропали
+func (tc *TaskController) markSeen(ctx context.Context, urls []string) {
+	if tc.Tombstones == nil || len(urls) == 0 {
+		return
+	}
+	if err := tc.Tombstones.MarkSeen(ctx, urls); err != nil {
+		tc.Logger.Error("Failed to mark recipes as seen", zap.Int("count", len(urls)), zap.Error(err))
+	}
+}
+
+// Idle сообщает, что у контроллера нет задач в очереди, в работе, на повторе и необработанных результатов
+func (tc *TaskController) Idle() bool {
+	if tc.inFlight.Load() > 0 || len(tc.TaskQueue) > 0 || tc.Scheduler.Len() > 0 || len(tc.ResultQueue) > 0 {
+		return false
+	}
+	counts := tc.Tracker.TaskCounts()
+	return counts[StatusPending] == 0 && counts[StatusInProgress] == 0
+}
+
+// WaitIdle ждет, пока контроллер не окажется без работы две проверки подряд,
+// чтобы не принять за завершение момент передачи результата. Возвращает false,
+// если stop закрыт раньше.
+func (tc *TaskController) WaitIdle(stop <-chan struct{}, interval time.Duration) bool {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	idleChecks := 0
+	for {
+		select {
+		case <-ticker.C:
+			if !tc.Idle() {
+				idleChecks = 0
+				continue
+			}
+			idleChecks++
+			if idleChecks >= 2 {
+				return true
+			}
+		case <-stop:
+			return false
+		case <-tc.done:
+			return false
 		}
 	}
 }
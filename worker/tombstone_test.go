@@ -0,0 +1,156 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeTombstones запоминает страницы, помеченные удаленными
+type fakeTombstones struct {
+	mu   sync.Mutex
+	gone map[string]string
+	seen []string
+}
+
+func (f *fakeTombstones) MarkGone(ctx context.Context, canonicalURL string, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gone[canonicalURL] = reason
+	return nil
+}
+
+func (f *fakeTombstones) MarkSeen(ctx context.Context, canonicalURLs []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seen = append(f.seen, canonicalURLs...)
+	return nil
+}
+
+// TestRecipeDetailGone проверяет, что удаленная страница рецепта помечается, а не считается ошибкой
+func TestRecipeDetailGone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	w := NewRecipeWorker(zap.NewNop(), 1, 100, time.Second)
+	w.Tracker = NewStatusTracker()
+	results := make(chan Result, 1)
+
+	w.processTask(Task{ID: "detail", Type: TaskRecipeDetail, Payload: RecipeDetailPayload{URL: server.URL + "/recepty/supy/borsch-1/"}}, results)
+	result := <-results
+	gone, ok := result.Data.(Gone)
+	require.True(t, ok)
+	assert.Equal(t, server.URL+"/recepty/supy/borsch-1", gone.URL)
+	assert.Equal(t, http.StatusGone, gone.StatusCode)
+
+	state, _ := w.Tracker.Task("detail")
+	assert.Equal(t, StatusCompleted, state.Status)
+
+	// Контроллер передает пропавшую страницу в хранилище
+	tombstones := &fakeTombstones{gone: make(map[string]string)}
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Second, 1, nil)
+	tc.Tombstones = tombstones
+	tc.processResult(context.Background(), result)
+	assert.Equal(t, entity.GoneReason(http.StatusGone), tombstones.gone[gone.URL])
+}
+
+// TestTaskControllerIdle проверяет определение завершения обхода
+func TestTaskControllerIdle(t *testing.T) {
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Hour, 1, nil)
+	assert.True(t, tc.Idle())
+
+	task := Task{ID: "1", Type: TaskListing, Category: &entity.Category{Name: "супы", Href: "/recepty/supy"}}
+	tc.AddTask(task)
+	assert.False(t, tc.Idle())
+
+	// Задача взята и выполнена
+	<-tc.TaskQueue
+	tc.Tracker.TaskStarted(task, 1)
+	assert.False(t, tc.Idle())
+	tc.Tracker.TaskFinished(task.ID, 1, 0, nil)
+	assert.True(t, tc.Idle())
+
+	// Задача, ожидающая повторной постановки, тоже считается работой
+	tc.retryTask(task)
+	assert.False(t, tc.Idle())
+
+	stop := make(chan struct{})
+	close(stop)
+	assert.False(t, tc.WaitIdle(stop, time.Millisecond))
+}
+
+// TestListingMarksSeen проверяет, что встреченными отмечаются все карточки списка,
+// включая не вошедшие в лимит рецептов и отсеянные как дубликаты
+func TestListingMarksSeen(t *testing.T) {
+	tombstones := &fakeTombstones{gone: make(map[string]string)}
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Second, 1, nopDB{})
+	tc.Tombstones = tombstones
+
+	listed := []string{
+		"https://eda.ru/recepty/supy/borsch-1",
+		"https://eda.ru/recepty/supy/schi-2",
+		"https://eda.ru/recepty/supy/uha-3",
+	}
+	tc.processResult(context.Background(), Result{TaskID: "супы", Type: TaskListing, Listed: listed, Recipes: []entity.Recipe{
+		{Name: "борщ", Href: "/recepty/supy/borsch-1", CanonicalURL: listed[0]},
+	}})
+	assert.Equal(t, listed, tombstones.seen)
+}
+
+// siteTransport направляет запросы к сайту-источнику на тестовый сервер
+type siteTransport struct {
+	server *httptest.Server
+}
+
+func (t siteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := url.Parse(t.server.URL)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestListingPagesMarkSeen проверяет, что рецепт, сдвинутый на вторую страницу
+// списка, отмечается встреченным и не считается пропавшим
+func TestListingPagesMarkSeen(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/recepty/supy", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`<html><body><article><a href="/recepty/supy/borsch-1"><h3>Борщ</h3></a></article></body></html>`))
+			return
+		}
+		w.Write([]byte(`<html><head><link rel="next" href="/recepty/supy?page=2"></head>
+<body><article><a href="/recepty/supy/schi-2"><h3>Щи</h3></a></article></body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	w := NewRecipeWorker(zap.NewNop(), 1, 100, time.Second)
+	w.Parser.Collector.WithTransport(siteTransport{server: server})
+	w.Tracker = NewStatusTracker()
+	results := make(chan Result, 1)
+
+	w.processTask(Task{ID: "супы", Type: TaskListing, Category: &entity.Category{Name: "супы", Href: "/recepty/supy"}}, results)
+	result := <-results
+	assert.Equal(t, []string{"https://eda.ru/recepty/supy/schi-2", "https://eda.ru/recepty/supy/borsch-1"}, result.Listed)
+	require.Len(t, result.Recipes, 1) // Лимит рецептов не ограничивает обход страниц
+
+	tombstones := &fakeTombstones{gone: make(map[string]string)}
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Second, 1, nopDB{})
+	tc.Tombstones = tombstones
+	tc.processResult(context.Background(), result)
+	assert.Contains(t, tombstones.seen, "https://eda.ru/recepty/supy/borsch-1")
+}
@@ -0,0 +1,145 @@
+package worker
+
+import (
+	"errors"
+	"time"
+
+	"github.com/seniorcat/scraper/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// minWatchdogInterval — нижняя граница периода проверок сторожа
+const minWatchdogInterval = time.Second
+
+// runWatchdog периодически ищет зависшие задачи и упавшие воркеры до остановки контроллера
+func (tc *TaskController) runWatchdog() {
+	interval := tc.WatchdogInterval
+	if interval <= 0 {
+		interval = tc.TaskDeadline / 4
+	}
+	if interval < minWatchdogInterval {
+		interval = minWatchdogInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Воркеры, с которых уже снята задача, и идентификатор этой задачи
+	cancelled := make(map[*RecipeWorker]string)
+
+	for {
+		select {
+		case <-ticker.C:
+			tc.checkTasks(cancelled)
+			tc.restartDeadWorkers()
+		case <-tc.done:
+			return
+		}
+	}
+}
+
+// checkTasks снимает задачи, превысившие TaskDeadline, и заменяет воркеры,
+// не отреагировавшие на отмену к следующей проверке
+func (tc *TaskController) checkTasks(cancelled map[*RecipeWorker]string) {
+	now := time.Now()
+
+	for _, w := range tc.workers() {
+		task, busy := w.CurrentTask()
+		if !busy {
+			delete(cancelled, w)
+			continue
+		}
+
+		if taskID, ok := cancelled[w]; ok && taskID == task.ID {
+			delete(cancelled, w)
+			tc.replaceWorker(w, "worker did not respond to task cancellation")
+			continue
+		}
+
+		state, ok := tc.Tracker.Task(task.ID)
+		if !ok || state.Status != StatusInProgress || state.WorkerID != w.ID {
+			continue
+		}
+		if now.Sub(state.StartedAt) < tc.TaskDeadline {
+			continue
+		}
+
+		tc.Logger.Warn("Task deadline exceeded, cancelling",
+			zap.String("task_id", task.ID),
+			zap.Int("worker_id", w.ID),
+			zap.Duration("running", now.Sub(state.StartedAt)))
+		metrics.WatchdogTaskTimeouts.Inc()
+
+		tc.Tracker.TaskTimedOut(task.ID, w.ID, tc.TaskDeadline)
+		w.CancelTask()
+		cancelled[w] = task.ID
+
+		tc.retryTask(task)
+	}
+}
+
+// restartDeadWorkers дополняет пул до WorkersCount, если воркеры завершились аварийно
+func (tc *TaskController) restartDeadWorkers() {
+	tc.scaleMu.Lock()
+	defer tc.scaleMu.Unlock()
+
+	for missing := tc.DesiredWorkers() - len(tc.workers()); missing > 0; missing-- {
+		select {
+		case <-tc.done:
+			return
+		default:
+		}
+
+		w := tc.startWorker()
+		metrics.WatchdogWorkerRestarts.Inc()
+		tc.Logger.Error("Dead recipe worker replaced", zap.Int("new_worker_id", w.ID))
+	}
+}
+
+// replaceWorker исключает зависший воркер из пула и запускает вместо него новый.
+// Зависшая горутина завершится сама, когда разблокируется.
+func (tc *TaskController) replaceWorker(w *RecipeWorker, reason string) {
+	tc.scaleMu.Lock()
+	defer tc.scaleMu.Unlock()
+
+	tc.Tracker.WorkerFailed(w.ID, errors.New(reason))
+	w.Quit()
+	tc.removeWorker(w)
+	tc.detachWorker(w)
+
+	select {
+	case <-tc.done:
+		return
+	default:
+	}
+
+	replacement := tc.startWorker()
+	metrics.WatchdogWorkerRestarts.Inc()
+	tc.Logger.Error("Hung recipe worker replaced",
+		zap.Int("worker_id", w.ID),
+		zap.Int("new_worker_id", replacement.ID),
+		zap.String("reason", reason))
+}
+
+// retryTask ставит задачу в очередь повторно, пока не исчерпаны попытки. Задержка
+// начинается с retryInterval и удваивается с каждой попыткой.
+func (tc *TaskController) retryTask(task Task) {
+	if task.RetryCount >= tc.maxRetries {
+		tc.Logger.Error("Task failed, retries exhausted",
+			zap.String("task_id", task.ID),
+			zap.Int("retries", task.RetryCount))
+		return
+	}
+
+	delay := tc.retryInterval << task.RetryCount
+	task.RetryCount++
+	tc.inFlight.Add(1)
+	time.AfterFunc(delay, func() {
+		defer tc.inFlight.Add(-1)
+		tc.AddTask(task)
+	})
+	tc.Logger.Info("Task retry scheduled",
+		zap.String("task_id", task.ID),
+		zap.Int("retry", task.RetryCount),
+		zap.Duration("delay", delay))
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/seniorcat/scraper/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestWatchdogCancelsAndRequeues проверяет снятие зависшей задачи, ее повторную
+// постановку в очередь и замену воркера, не отреагировавшего на отмену
+func TestWatchdogCancelsAndRequeues(t *testing.T) {
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Millisecond, 3, nil)
+	tc.TaskDeadline = time.Nanosecond
+	tc.maxRecipes, tc.rps, tc.timeout = 1, 1, time.Second
+
+	// Воркер, зависший на задаче
+	hung := NewRecipeWorker(zap.NewNop(), 1, 1, time.Second)
+	hung.ID = 1
+	hung.Tracker = tc.Tracker
+	tc.RecipeWorkers = append(tc.RecipeWorkers, hung)
+	tc.wg.Add(1)
+
+	task := Task{ID: "zavtraki", Type: "recipe", Category: &entity.Category{Name: "завтраки", Href: "/recepty/zavtraki"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	hung.setCurrent(&task, cancel)
+	tc.Tracker.TaskStarted(task, hung.ID)
+
+	cancelled := make(map[*RecipeWorker]string)
+	tc.checkTasks(cancelled)
+
+	// Задача отменена и отмечена ошибкой
+	assert.Error(t, ctx.Err())
+	state, ok := tc.TaskState(task.ID)
+	require.True(t, ok)
+	assert.Equal(t, StatusError, state.Status)
+
+	// Задача поставлена заново с увеличенным счетчиком попыток
+	select {
+	case retried := <-tc.TaskQueue:
+		assert.Equal(t, task.ID, retried.ID)
+		assert.Equal(t, 1, retried.RetryCount)
+	case <-time.After(time.Second):
+		t.Fatal("task was not requeued")
+	}
+
+	// Воркер так и не освободился — сторож заменяет его новым
+	tc.checkTasks(cancelled)
+	workers := tc.workers()
+	require.Len(t, workers, 1)
+	assert.NotEqual(t, hung, workers[0])
+
+	tc.Stop()
+}
+
+// TestWatchdogRetriesExhausted проверяет, что задача не ставится заново после maxRetries
+func TestWatchdogRetriesExhausted(t *testing.T) {
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Millisecond, 1, nil)
+
+	tc.retryTask(Task{ID: "1", RetryCount: 1})
+
+	select {
+	case <-tc.TaskQueue:
+		t.Fatal("task requeued after retries were exhausted")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestHandlerErrorRetried проверяет, что задача, завершившаяся ошибкой обработчика,
+// ставится в очередь повторно и выполняется со второй попытки
+func TestHandlerErrorRetried(t *testing.T) {
+	tc := NewTaskController(nil, 1, zap.NewNop(), time.Millisecond, 3, nil)
+	var calls atomic.Int32
+	tc.Registry.Register("flaky", HandlerFunc(func(ctx context.Context, w *RecipeWorker, task Task) (Result, error) {
+		if calls.Add(1) == 1 {
+			return Result{}, errors.New("http 503")
+		}
+		return Result{}, nil
+	}))
+	tc.InitWorkerPool(1, 1, time.Second)
+
+	tc.AddTask(Task{ID: "flaky-1", Type: "flaky"})
+
+	select {
+	case result := <-tc.ResultQueue:
+		assert.Equal(t, "flaky-1", result.TaskID)
+	case <-time.After(time.Second):
+		t.Fatal("task was not retried")
+	}
+	assert.Equal(t, int32(2), calls.Load())
+	state, ok := tc.TaskState("flaky-1")
+	require.True(t, ok)
+	assert.Equal(t, StatusCompleted, state.Status)
+	assert.Equal(t, 1, state.RetryCount)
+
+	tc.Stop()
+}
+
+// TestRetryBackoff проверяет удвоение задержки с каждой попыткой
+func TestRetryBackoff(t *testing.T) {
+	tc := NewTaskController(nil, 1, zap.NewNop(), 20*time.Millisecond, 5, nil)
+
+	start := time.Now()
+	tc.retryTask(Task{ID: "1", Type: "recipe", RetryCount: 2})
+
+	select {
+	case retried := <-tc.TaskQueue:
+		assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+		assert.Equal(t, 3, retried.RetryCount)
+	case <-time.After(time.Second):
+		t.Fatal("task was not requeued")
+	}
+}